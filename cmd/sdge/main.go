@@ -1,17 +1,38 @@
 package main
 
 import (
+	"SDGEStreaming/internal/account"
 	"SDGEStreaming/internal/admin"
+	"SDGEStreaming/internal/anomaly"
 	"SDGEStreaming/internal/audio"
 	"SDGEStreaming/internal/audiovisual"
+	"SDGEStreaming/internal/audit"
+	"SDGEStreaming/internal/auth"
+	"SDGEStreaming/internal/catalog"
 	"SDGEStreaming/internal/categories"
+	"SDGEStreaming/internal/charts"
 	"SDGEStreaming/internal/contentclass"
 	"SDGEStreaming/internal/errors"
+	"SDGEStreaming/internal/genres"
+	"SDGEStreaming/internal/library"
+	"SDGEStreaming/internal/mailer"
+	"SDGEStreaming/internal/people"
 	"SDGEStreaming/internal/profiles"
+	"SDGEStreaming/internal/ratings"
+	"SDGEStreaming/internal/rbac"
+	"SDGEStreaming/internal/recommend"
+	"SDGEStreaming/internal/recovery"
+	"SDGEStreaming/internal/reviews"
+	"SDGEStreaming/internal/search"
+	"SDGEStreaming/internal/sessions"
+	"SDGEStreaming/internal/twofactor"
 	"SDGEStreaming/internal/utils"
+	"SDGEStreaming/internal/verification"
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -21,22 +42,33 @@ import (
 var (
 	currentUser      *categories.User
 	currentSessionID string
-	lastActivity     time.Time
-	sessionTimeout   = 5 * time.Minute
 )
 
 func main() {
 	fmt.Print("\033[H\033[2J") // Limpiar pantalla
 
-	lastActivity = time.Now()
+	// Los correos quedan en una bandeja local salvo que se indique otra carpeta
+	if dir := os.Getenv("SDGE_OUTBOX"); dir != "" {
+		mailer.SetMailer(mailer.FileOutbox{Dir: filepath.Clean(dir)})
+	}
 
 	for {
-		// Verificar expiración de sesión
-		if currentUser != nil && time.Since(lastActivity) > sessionTimeout {
-			fmt.Println("Sesión expirada por inactividad. Por favor inicie sesión nuevamente.")
-			currentUser = nil
-			waitForEnter()
-			continue
+		// Eliminar las cuentas cuyo plazo de eliminación venció
+		account.PurgeDue(time.Now())
+
+		// Verificar que la sesión siga vigente: vence por inactividad y se
+		// cierra al restablecer la contraseña
+		if currentUser != nil {
+			if _, err := sessions.Validate(currentSessionID); err != nil {
+				if err == errors.ErrSessionExpired {
+					fmt.Println("Sesión expirada por inactividad. Por favor inicie sesión nuevamente.")
+				} else {
+					fmt.Println("Su sesión fue cerrada. Por favor inicie sesión nuevamente.")
+				}
+				currentUser = nil
+				waitForEnter()
+				continue
+			}
 		}
 
 		if currentUser == nil {
@@ -70,8 +102,9 @@ func showAuthMenu() {
 	fmt.Println()
 	fmt.Println("1. Iniciar Sesión")
 	fmt.Println("2. Registrarse")
-	fmt.Println("3. Explorar como Invitado")
-	fmt.Println("4. Salir")
+	fmt.Println("3. ¿Olvidaste tu contraseña?")
+	fmt.Println("4. Explorar como Invitado")
+	fmt.Println("5. Salir")
 	fmt.Println("────────────────────────────────────────────────────────────")
 
 	option := readInput("Seleccione una opción: ")
@@ -82,9 +115,11 @@ func showAuthMenu() {
 	case "2":
 		register()
 	case "3":
+		resetPassword()
+	case "4":
 		currentUser = nil
 		showContentMenu(true)
-	case "4":
+	case "5":
 		fmt.Print("\033[H\033[2J")
 		fmt.Println("Gracias por usar SDGEStreaming. ¡Hasta luego!")
 		os.Exit(0)
@@ -113,25 +148,108 @@ func login() {
 		return
 	}
 
-	user, err := profiles.FindByEmail(email)
-	if err != nil {
-		fmt.Println("✗ Usuario no encontrado")
-		waitForEnter()
-		return
-	}
-
-	if user.Password != password {
-		fmt.Println("✗ Contraseña incorrecta")
+	user, err := auth.Login(email, password, loginSource())
+	// Segundo paso: obligatorio para administradores, opcional para el resto
+	if err == errors.ErrTwoFactorPending {
+		code := readInput("Código de verificación (app autenticadora o código de recuperación): ")
+		err = auth.VerifySecondFactor(user, code, loginSource())
+		if err != nil {
+			errors.HandleAppError(err)
+			waitForEnter()
+			return
+		}
+	} else if err != nil {
+		errors.HandleAppError(err)
+		if err != errors.ErrAccountDisabled {
+			fmt.Println("¿Olvidaste tu contraseña? Elija la opción 3 del menú de inicio")
+		}
 		waitForEnter()
 		return
+	} else if twofactor.IsRequired(user.ID) {
+		fmt.Println("Los administradores deben activar la verificación en dos pasos para continuar")
+		if !enrollTwoFactor(user.ID) {
+			waitForEnter()
+			return
+		}
 	}
 
 	profiles.UpdateLastLogin(user.ID)
 	currentUser = user
-	currentSessionID = fmt.Sprintf("sess_%d_%d", user.ID, time.Now().Unix())
-	lastActivity = time.Now()
+	currentSessionID = sessions.Create(user.ID, loginSource()).ID
 
 	fmt.Printf(" ¡Bienvenido, %s!\n", user.Name)
+	if at, pending := account.DeletionScheduled(user.ID); pending {
+		fmt.Printf("Su cuenta se eliminará el %s\n", at.Format("02/01/2006 15:04"))
+		if strings.ToLower(readInput("¿Desea cancelar la eliminación? (s/n): ")) == "s" {
+			if err := account.CancelDeletion(user.ID); err != nil {
+				errors.HandleAppError(err)
+			} else {
+				fmt.Println(" Eliminación cancelada")
+			}
+		}
+	}
+	if !user.EmailVerified {
+		fmt.Println("Su email no está verificado: verifíquelo desde Mi Perfil para poder calificar")
+	}
+	waitForEnter()
+}
+
+// Identificar desde dónde se inicia sesión: la dirección remota en una
+// conexión SSH o el dispositivo de la terminal local. Sin ninguno de los dos
+// (por ejemplo con la entrada redirigida) el origen queda vacío y los fallos
+// solo cuentan para la cuenta
+func loginSource() string {
+	if client := os.Getenv("SSH_CLIENT"); client != "" {
+		return "ssh " + strings.Fields(client)[0]
+	}
+	if tty, err := os.Readlink("/proc/self/fd/0"); err == nil && (strings.HasPrefix(tty, "/dev/pts/") || strings.HasPrefix(tty, "/dev/tty")) {
+		return "terminal " + tty
+	}
+	return ""
+}
+
+// Mostrar un origen de inicio de sesión, que puede ser desconocido
+func sourceLabel(source string) string {
+	if source == "" {
+		return "origen desconocido"
+	}
+	return source
+}
+
+// Restablecer la contraseña con un código enviado por email
+func resetPassword() {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Restablecer Contraseña")
+	fmt.Println("══════════════════════")
+
+	email := readInput("Email de su cuenta: ")
+	if email == "0" || email == "" {
+		return
+	}
+	if err := recovery.RequestReset(email); err != nil {
+		errors.HandleAppError(err)
+		waitForEnter()
+		return
+	}
+	fmt.Println("Si el email está registrado, le enviamos un código para restablecer su contraseña")
+
+	code := readInput("Código (Enter para volver): ")
+	if code == "" || code == "0" {
+		return
+	}
+	password := readInput("Nueva contraseña (6+ caracteres): ")
+	if readInput("Repita la contraseña: ") != password {
+		fmt.Println("Las contraseñas no coinciden")
+		waitForEnter()
+		return
+	}
+	if err := recovery.ResetPassword(code, password); err != nil {
+		errors.HandleAppError(err)
+		waitForEnter()
+		return
+	}
+	fmt.Println(" Contraseña restablecida. Se cerraron todas las sesiones abiertas; inicie sesión con la nueva contraseña")
 	waitForEnter()
 }
 
@@ -196,7 +314,7 @@ func register() {
 
 	ageRating := ratings[ratingNum-1].Name
 
-	_, err = profiles.AddUser(name, age, email, password, "Free", ageRating, false)
+	user, err := profiles.AddUser(name, age, email, password, "Free", ageRating, nil)
 	if err != nil {
 		errors.HandleAppError(err)
 		waitForEnter()
@@ -204,9 +322,74 @@ func register() {
 	}
 
 	fmt.Println(" Usuario registrado exitosamente")
+	if err := verification.SendRegistrationCode(user.ID); err != nil {
+		errors.HandleAppError(err)
+		waitForEnter()
+		return
+	}
+	fmt.Printf("Le enviamos un código de verificación a %s\n", user.Email)
+	confirmCode(user.ID, "Código de verificación (Enter para verificar más tarde): ")
 	waitForEnter()
 }
 
+// Opción del menú principal
+type menuEntry struct {
+	label  string
+	action func()
+}
+
+// Armar el menú principal con las opciones que permiten los roles del usuario
+func mainMenuEntries() []menuEntry {
+	entries := []menuEntry{
+		{"Mi Perfil", showUserProfile},
+		{"Explorar Contenido", func() { showContentMenu(false) }},
+	}
+	if !rbac.IsStaff(currentUser.ID) {
+		entries = append(entries,
+			menuEntry{"Mi Lista", showMyList},
+			menuEntry{"Historial de Reproducción", showPlayHistory},
+			menuEntry{"Configuraciones", func() {
+				fmt.Println("Funcionalidad para AA2")
+				waitForEnter()
+			}},
+		)
+	}
+
+	perms := rbac.PermissionsOf(currentUser.ID)
+	staff := []struct {
+		perm string
+		menuEntry
+	}{
+		{rbac.PermUsersView, menuEntry{"Gestionar Usuarios", showUserManagement}},
+		{rbac.PermContentManage, menuEntry{"Gestionar Contenido Audiovisual", showAudiovisualManagement}},
+		{rbac.PermContentManage, menuEntry{"Gestionar Contenido de Audio", showAudioManagement}},
+		{rbac.PermReviewsModerate, menuEntry{"Moderar Reseñas", showModerationQueue}},
+		{rbac.PermRatingsAudit, menuEntry{"Calificaciones Sospechosas", showRatingAnomalies}},
+		{rbac.PermGenresManage, menuEntry{"Gestionar Géneros", showGenreTaxonomy}},
+		{rbac.PermAccessManage, menuEntry{"Accesos y Bloqueos", showLoginActivity}},
+		{rbac.PermAuditView, menuEntry{"Auditoría", showAuditLog}},
+	}
+	for _, e := range staff {
+		if perms[e.perm] {
+			entries = append(entries, e.menuEntry)
+		}
+	}
+
+	return append(entries,
+		menuEntry{"Cerrar Sesión", func() {
+			sessions.Revoke(currentSessionID)
+			currentUser = nil
+			fmt.Println("Sesión cerrada")
+			waitForEnter()
+		}},
+		menuEntry{"Salir", func() {
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("Hasta luego, %s\n", currentUser.Name)
+			os.Exit(0)
+		}},
+	)
+}
+
 // Mostrar menú principal
 func showMainMenu() {
 	fmt.Print("\033[H\033[2J") // Limpiar pantalla
@@ -216,68 +399,23 @@ func showMainMenu() {
 	fmt.Println("════════════════════════════════")
 	fmt.Println()
 
-	if currentUser.IsAdmin {
-		fmt.Println("1. Mi Perfil")
-		fmt.Println("2. Explorar Contenido")
-		fmt.Println("3. Gestionar Usuarios")
-		fmt.Println("4. Gestionar Contenido Audiovisual")
-		fmt.Println("5. Gestionar Contenido de Audio")
-		fmt.Println("6. Cerrar Sesión")
-		fmt.Println("7. Salir")
-	} else {
-		fmt.Println("1. Mi Perfil")
-		fmt.Println("2. Explorar Contenido")
-		fmt.Println("3. Mi Lista (Próximamente en AA2)")
-		fmt.Println("4. Historial de Reproducción (Próximamente en AA2)")
-		fmt.Println("5. Configuraciones")
-		fmt.Println("6. Cerrar Sesión")
-		fmt.Println("7. Salir")
+	entries := mainMenuEntries()
+	for i, e := range entries {
+		fmt.Printf("%d. %s\n", i+1, e.label)
 	}
 
 	fmt.Println("────────────────────────────────────────────────────────────")
 
 	option := readInput("Seleccione una opción: ")
-
-	switch option {
-	case "1":
-		showUserProfile()
-	case "2":
-		showContentMenu(false)
-	case "3":
-		if currentUser.IsAdmin {
-			showUserManagement()
-		} else {
-			fmt.Println("Funcionalidad para AA2")
-			waitForEnter()
-		}
-	case "4":
-		if currentUser.IsAdmin {
-			showAudiovisualManagement()
-		} else {
-			fmt.Println("Funcionalidad para AA2")
-			waitForEnter()
-		}
-	case "5":
-		if currentUser.IsAdmin {
-			showAudioManagement()
-		} else {
-			fmt.Println("Funcionalidad para AA2")
-			waitForEnter()
-		}
-	case "6":
-		currentUser = nil
-		fmt.Println("Sesión cerrada")
-		waitForEnter()
-	case "7":
-		fmt.Print("\033[H\033[2J")
-		fmt.Printf("Hasta luego, %s\n", currentUser.Name)
-		os.Exit(0)
-	default:
+	num, err := strconv.Atoi(option)
+	if err != nil || num < 1 || num > len(entries) {
 		if option != "" {
 			fmt.Println("Opción inválida")
 			waitForEnter()
 		}
+		return
 	}
+	entries[num-1].action()
 }
 
 // Mostrar perfil de usuario
@@ -288,36 +426,63 @@ func showUserProfile() {
 	fmt.Println("═════════")
 
 	fmt.Printf("Nombre: %s\n", currentUser.Name)
-	fmt.Printf("Email: %s\n", currentUser.Email)
+	if currentUser.EmailVerified {
+		fmt.Printf("Email: %s (verificado)\n", currentUser.Email)
+	} else {
+		fmt.Printf("Email: %s (sin verificar)\n", currentUser.Email)
+	}
+	if email, purpose, ok := verification.Pending(currentUser.ID); ok && purpose == verification.PurposeEmailChange {
+		fmt.Printf("Cambio pendiente a: %s\n", email)
+	}
 	fmt.Printf("Plan: %s\n", currentUser.Plan)
 	fmt.Printf("Edad: %d años\n", currentUser.Age)
 	fmt.Printf("Clasificación: %s\n", currentUser.AgeRating)
 	fmt.Printf("Último acceso: %s\n", currentUser.LastLogin.Format("02/01/2006 15:04"))
+	deletionAt, deletionPending := account.DeletionScheduled(currentUser.ID)
+	if deletionPending {
+		fmt.Printf("Eliminación programada para el %s\n", deletionAt.Format("02/01/2006 15:04"))
+	}
 
 	fmt.Println("────────────────────────────────────────────────────────────")
-	waitForEnter()
-}
-
-// Mostrar menú de contenido
-func showContentMenu(isGuest bool) {
-	fmt.Print("\033[H\033[2J")
-	showHeader()
-	fmt.Println("Explorar Contenido")
-	fmt.Println("══════════════════")
-	fmt.Println()
-	fmt.Println("1. Contenido Audiovisual")
-	fmt.Println("2. Contenido de Audio")
-	fmt.Println("3. Volver al Menú Principal")
+	fmt.Println("1. Mis Calificaciones")
+	fmt.Println("2. Cambiar Nombre")
+	fmt.Println("3. Verificar Email")
+	fmt.Println("4. Cambiar Email")
+	fmt.Println("5. Cambiar Contraseña")
+	fmt.Println("6. Verificación en Dos Pasos")
+	fmt.Println("7. Sesiones Activas")
+	if deletionPending {
+		fmt.Println("8. Cancelar Eliminación de Cuenta")
+	} else {
+		fmt.Println("8. Eliminar Cuenta")
+	}
+	fmt.Println("9. Volver al Menú Principal")
 	fmt.Println("────────────────────────────────────────────────────────────")
 
 	option := readInput("Seleccione una opción: ")
 
 	switch option {
 	case "1":
-		showAudiovisualContent(isGuest)
+		showMyRatings()
 	case "2":
-		showAudioContent(isGuest)
+		changeName()
 	case "3":
+		verifyEmail()
+	case "4":
+		changeEmail()
+	case "5":
+		changePassword()
+	case "6":
+		showTwoFactorSettings()
+	case "7":
+		showActiveSessions()
+	case "8":
+		if deletionPending {
+			cancelAccountDeletion()
+		} else {
+			deleteAccount()
+		}
+	case "9", "0":
 		return
 	default:
 		if option != "" {
@@ -327,170 +492,1801 @@ func showContentMenu(isGuest bool) {
 	}
 }
 
-// Mostrar contenido audiovisual
-func showAudiovisualContent(isGuest bool) {
-	fmt.Print("\033[H\033[2J")
-	showHeader()
-	fmt.Println("Contenido Audiovisual")
-	fmt.Println("═════════════════════")
-
-	contents := audiovisual.ListAll()
-	if len(contents) == 0 {
-		fmt.Println("No hay contenido disponible")
-		waitForEnter()
+// Cambiar el nombre del usuario
+func changeName() {
+	name := readInput(fmt.Sprintf("Nuevo nombre [%s]: ", currentUser.Name))
+	if name == "" || name == "0" {
 		return
 	}
-
-	for _, c := range contents {
-		// Verificar clasificación
-		if !isGuest && !contentclass.CanAccessContent(currentUser.Age, c.AgeRating) {
-			continue
-		}
-
-		fmt.Printf("ID: %d | %s\n", c.ID, c.Title)
-		fmt.Printf("   %s • %s • %s\n", c.Type, c.Genre, utils.FormatDuration(c.Duration))
-		fmt.Printf("   Clasificación: %s • Rating: %s\n", c.AgeRating, utils.FormatRating(c.AverageRating))
-		fmt.Println("────────────────────────────────────────────────────────────")
-	}
-
-	if !isGuest {
-		contentIDStr := readInput("ID para calificar (0 para volver): ")
-		if contentIDStr != "0" {
-			contentID, err := strconv.Atoi(contentIDStr)
-			if err == nil && contentID > 0 {
-				rateAudiovisualContent(contentID)
-			}
-		}
+	if err := account.UpdateName(currentUser.ID, name); err != nil {
+		errors.HandleAppError(err)
 	} else {
-		waitForEnter()
+		currentUser, _ = profiles.FindByID(currentUser.ID)
+		fmt.Println(" Nombre actualizado")
 	}
+	waitForEnter()
 }
 
-// Mostrar contenido de audio
-func showAudioContent(isGuest bool) {
-	fmt.Print("\033[H\033[2J")
-	showHeader()
-	fmt.Println("Contenido de Audio")
-	fmt.Println("══════════════════")
-
-	contents := audio.ListAll()
-	if len(contents) == 0 {
-		fmt.Println("No hay contenido disponible")
+// Cambiar la contraseña confirmando la actual
+func changePassword() {
+	current := readInput("Contraseña actual: ")
+	if current == "" || current == "0" {
+		return
+	}
+	password := readInput("Nueva contraseña: ")
+	if readInput("Repita la nueva contraseña: ") != password {
+		fmt.Println("Las contraseñas no coinciden")
 		waitForEnter()
 		return
 	}
-
-	for _, c := range contents {
-		// Verificar clasificación
-		if !isGuest && !contentclass.CanAccessContent(currentUser.Age, c.AgeRating) {
-			continue
+	closed, err := account.ChangePassword(currentUser.ID, currentSessionID, current, password)
+	if err != nil {
+		errors.HandleAppError(err)
+	} else {
+		fmt.Println(" Contraseña actualizada")
+		if closed > 0 {
+			fmt.Printf("Se cerraron sus otras %d sesiones\n", closed)
 		}
-
-		fmt.Printf("ID: %d | %s\n", c.ID, c.Title)
-		fmt.Printf("   %s • %s • %s\n", c.Type, c.Genre, utils.FormatDuration(c.Duration))
-		fmt.Printf("   Clasificación: %s • Rating: %s\n", c.AgeRating, utils.FormatRating(c.AverageRating))
-		fmt.Println("────────────────────────────────────────────────────────────")
 	}
+	waitForEnter()
+}
 
-	if !isGuest {
-		contentIDStr := readInput("ID para calificar (0 para volver): ")
-		if contentIDStr != "0" {
-			contentID, err := strconv.Atoi(contentIDStr)
-			if err == nil && contentID > 0 {
-				rateAudioContent(contentID)
+// Ver las sesiones abiertas del usuario y cerrar las que no reconozca
+func showActiveSessions() {
+	for {
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Println("Sesiones Activas")
+		fmt.Println("════════════════")
+
+		list := sessions.ForUser(currentUser.ID)
+		for i, s := range list {
+			current := ""
+			if s.ID == currentSessionID {
+				current = " (esta sesión)"
 			}
+			fmt.Printf("%d. %s%s\n", i+1, sourceLabel(s.Source), current)
+			fmt.Printf("   Inicio: %s • Última actividad: %s\n", s.CreatedAt.Format("02/01/2006 15:04"), s.LastSeen.Format("02/01/2006 15:04"))
+		}
+		fmt.Println("────────────────────────────────────────────────────────────")
+
+		option := readInput("Número de la sesión a cerrar, T para cerrar las demás (Enter para volver): ")
+		if option == "" || option == "0" {
+			return
+		}
+		if strings.ToLower(option) == "t" {
+			fmt.Printf(" %d sesiones cerradas\n", sessions.RevokeOthers(currentUser.ID, currentSessionID))
+			waitForEnter()
+			continue
+		}
+		num, err := strconv.Atoi(option)
+		if err != nil || num < 1 || num > len(list) {
+			fmt.Println("Opción inválida")
+			waitForEnter()
+			continue
+		}
+		if list[num-1].ID == currentSessionID {
+			fmt.Println("Para cerrar esta sesión use Cerrar Sesión en el menú principal")
+			waitForEnter()
+			continue
+		}
+		if err := sessions.RevokeOwn(currentUser.ID, list[num-1].ID); err != nil {
+			errors.HandleAppError(err)
+		} else {
+			fmt.Println(" Sesión cerrada")
 		}
-	} else {
 		waitForEnter()
 	}
 }
 
-// Calificar contenido audiovisual
-func rateAudiovisualContent(contentID int) {
-	c, err := audiovisual.GetByID(contentID)
-	if err != nil {
-		fmt.Println("Contenido no encontrado")
-		waitForEnter()
+// Pedir la eliminación de la cuenta, que se hace efectiva al vencer el plazo
+func deleteAccount() {
+	fmt.Printf("Su cuenta, sus calificaciones y sus reseñas se eliminarán en %.0f días.\n", account.DeletionGracePeriod.Hours()/24)
+	fmt.Println("Hasta entonces puede cancelarla iniciando sesión.")
+	password := readInput("Contraseña para confirmar (Enter para cancelar): ")
+	if password == "" {
 		return
 	}
-
-	fmt.Print("\033[H\033[2J")
-	showHeader()
-	fmt.Printf("Calificar: %s\n", c.Title)
-	fmt.Println("══════════════")
-
-	ratingStr := readInput("Calificación (1.0 - 10.0): ")
-	rating, err := utils.ToFloat(ratingStr)
-	if err != nil || rating < 1.0 || rating > 10.0 {
-		fmt.Println("Calificación inválida")
+	at, err := account.RequestDeletion(currentUser.ID, password)
+	if err != nil {
+		errors.HandleAppError(err)
 		waitForEnter()
 		return
 	}
+	fmt.Printf(" Eliminación programada para el %s. Se cerraron sus sesiones.\n", at.Format("02/01/2006 15:04"))
+	currentUser = nil
+	waitForEnter()
+}
 
-	message, err := audiovisual.RateContent(contentID, currentUser.ID, rating)
-	if err != nil {
-		fmt.Println("Error al calificar")
+// Cancelar una eliminación de cuenta programada
+func cancelAccountDeletion() {
+	if err := account.CancelDeletion(currentUser.ID); err != nil {
+		errors.HandleAppError(err)
 	} else {
-		fmt.Printf(" %s\n", message)
+		fmt.Println(" Eliminación cancelada")
 	}
 	waitForEnter()
 }
 
-// Calificar contenido de audio
-func rateAudioContent(contentID int) {
-	c, err := audio.GetByID(contentID)
-	if err != nil {
-		fmt.Println("Contenido no encontrado")
-		waitForEnter()
-		return
+// Ingresar un código pendiente o reenviar el de registro
+func verifyEmail() {
+	if _, _, ok := verification.Pending(currentUser.ID); !ok {
+		if currentUser.EmailVerified {
+			fmt.Println("Su email ya está verificado")
+			waitForEnter()
+			return
+		}
+		if err := verification.SendRegistrationCode(currentUser.ID); err != nil {
+			errors.HandleAppError(err)
+			waitForEnter()
+			return
+		}
+		fmt.Printf("Le enviamos un código de verificación a %s\n", currentUser.Email)
 	}
+	confirmCode(currentUser.ID, "Código de verificación: ")
+	waitForEnter()
+}
 
-	fmt.Print("\033[H\033[2J")
-	showHeader()
-	fmt.Printf("Calificar: %s\n", c.Title)
-	fmt.Println("══════════════")
-
-	ratingStr := readInput("Calificación (1.0 - 10.0): ")
-	rating, err := utils.ToFloat(ratingStr)
-	if err != nil || rating < 1.0 || rating > 10.0 {
-		fmt.Println("Calificación inválida")
+// Pedir la contraseña actual y un email nuevo, y confirmarlo con el código enviado a esa dirección
+func changeEmail() {
+	current := readInput("Contraseña actual: ")
+	if current == "" || current == "0" {
+		return
+	}
+	email := readInput("Nuevo email: ")
+	if email == "" || email == "0" {
+		return
+	}
+	if err := account.RequestEmailChange(currentUser.ID, current, email); err != nil {
+		errors.HandleAppError(err)
 		waitForEnter()
 		return
 	}
+	fmt.Printf("Le enviamos un código a %s; su email no cambia hasta confirmarlo\n", email)
+	confirmCode(currentUser.ID, "Código de verificación (Enter para confirmar más tarde): ")
+	waitForEnter()
+}
 
-	message, err := audio.RateContent(contentID, currentUser.ID, rating)
+// Confirmar un código de verificación y refrescar el usuario de la sesión
+func confirmCode(userID int, prompt string) {
+	code := readInput(prompt)
+	if code == "" {
+		fmt.Println("Puede ingresar el código desde Mi Perfil > Verificar Email")
+		return
+	}
+	purpose, err := verification.Confirm(userID, code)
 	if err != nil {
-		fmt.Println("Error al calificar")
+		errors.HandleAppError(err)
+		return
+	}
+	if purpose == verification.PurposeEmailChange {
+		fmt.Println(" Email actualizado")
 	} else {
-		fmt.Printf(" %s\n", message)
+		fmt.Println(" Email verificado")
+	}
+	if currentUser != nil && currentUser.ID == userID {
+		currentUser, _ = profiles.FindByID(userID)
 	}
-	waitForEnter()
 }
 
-// Gestión de usuarios (admin)
+// Activar la verificación en dos pasos: mostrar el secreto, confirmar un
+// código de la app y entregar los códigos de recuperación
+func enrollTwoFactor(userID int) bool {
+	secret, uri, err := twofactor.BeginEnrollment(userID)
+	if err != nil {
+		errors.HandleAppError(err)
+		return false
+	}
+	fmt.Println()
+	fmt.Println("Cargue esta cuenta en su app autenticadora (Google Authenticator, Authy, ...)")
+	fmt.Printf("Clave: %s\n", secret)
+	fmt.Printf("URI:   %s\n", uri)
+	codes, err := twofactor.ConfirmEnrollment(userID, readInput("Código que muestra la app: "))
+	if err != nil {
+		errors.HandleAppError(err)
+		return false
+	}
+	fmt.Println(" Verificación en dos pasos activada")
+	printRecoveryCodes(codes)
+	return true
+}
+
+// Mostrar códigos de recuperación recién generados
+func printRecoveryCodes(codes []string) {
+	fmt.Println("Guarde estos códigos de recuperación; cada uno sirve una vez si pierde la app:")
+	for _, c := range codes {
+		fmt.Printf("   %s\n", c)
+	}
+}
+
+// Configurar la verificación en dos pasos del usuario
+func showTwoFactorSettings() {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Verificación en Dos Pasos")
+	fmt.Println("═════════════════════════")
+
+	if !twofactor.IsEnabled(currentUser.ID) {
+		fmt.Println("Estado: desactivada")
+		fmt.Println("────────────────────────────────────────────────────────────")
+		fmt.Println("1. Activar")
+		fmt.Println("2. Volver")
+		if readInput("Seleccione una opción: ") == "1" {
+			enrollTwoFactor(currentUser.ID)
+			waitForEnter()
+		}
+		return
+	}
+
+	fmt.Println("Estado: activada")
+	fmt.Printf("Códigos de recuperación sin usar: %d\n", twofactor.RecoveryCodesLeft(currentUser.ID))
+	fmt.Println("────────────────────────────────────────────────────────────")
+	fmt.Println("1. Generar Nuevos Códigos de Recuperación")
+	fmt.Println("2. Desactivar")
+	fmt.Println("3. Volver")
+
+	switch readInput("Seleccione una opción: ") {
+	case "1":
+		codes, err := twofactor.RegenerateRecoveryCodes(currentUser.ID, readInput("Código de verificación: "))
+		if err != nil {
+			errors.HandleAppError(err)
+		} else {
+			printRecoveryCodes(codes)
+		}
+		waitForEnter()
+	case "2":
+		if err := twofactor.Disable(currentUser.ID, readInput("Código de verificación: ")); err != nil {
+			errors.HandleAppError(err)
+		} else {
+			fmt.Println(" Verificación en dos pasos desactivada")
+		}
+		waitForEnter()
+	}
+}
+
+// Verificar que el usuario pueda calificar
+func requireVerifiedEmail() bool {
+	if currentUser.EmailVerified {
+		return true
+	}
+	fmt.Println("Debe verificar su email desde Mi Perfil para poder calificar")
+	waitForEnter()
+	return false
+}
+
+// Mostrar las calificaciones del usuario con su historial de cambios
+func showMyRatings() {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Mis Calificaciones")
+	fmt.Println("══════════════════")
+
+	rated := ratings.ByUser(currentUser.ID)
+	if len(rated) == 0 {
+		fmt.Println("Todavía no ha calificado contenido")
+		waitForEnter()
+		return
+	}
+
+	for _, r := range rated {
+		item, err := catalog.Get(r.Ref)
+		if err != nil {
+			continue
+		}
+		fmt.Printf("%s • Mi calificación: %s\n", item.Title, utils.FormatRating(r.Rating.Rating))
+		fmt.Printf("   Calificado: %s", r.Rating.CreatedAt.Format("02/01/2006 15:04"))
+		if !r.Rating.UpdatedAt.Equal(r.Rating.CreatedAt) {
+			fmt.Printf(" • Actualizado: %s", r.Rating.UpdatedAt.Format("02/01/2006 15:04"))
+		}
+		fmt.Println()
+		for _, change := range r.Rating.History {
+			fmt.Printf("   Antes: %s (cambiado el %s)\n", utils.FormatRating(change.Rating), change.ChangedAt.Format("02/01/2006 15:04"))
+		}
+		fmt.Println("────────────────────────────────────────────────────────────")
+	}
+	waitForEnter()
+}
+
+// Mostrar menú de contenido
+func showContentMenu(isGuest bool) {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Explorar Contenido")
+	fmt.Println("══════════════════")
+	fmt.Println()
+	fmt.Println("1. Contenido Audiovisual")
+	fmt.Println("2. Contenido de Audio")
+	fmt.Println("3. Buscar")
+	fmt.Println("4. Filtrar y Ordenar")
+	fmt.Println("5. Consulta Avanzada")
+	fmt.Println("6. Recomendado para ti")
+	fmt.Println("7. Rankings")
+	fmt.Println("8. Personas")
+	fmt.Println("9. Volver al Menú Principal")
+	fmt.Println("────────────────────────────────────────────────────────────")
+
+	option := readInput("Seleccione una opción: ")
+
+	switch option {
+	case "1":
+		showAudiovisualContent(isGuest)
+	case "2":
+		showAudioContent(isGuest)
+	case "3":
+		showSearch(isGuest)
+	case "4":
+		showCatalogFilter(isGuest)
+	case "5":
+		showAdvancedQuery(isGuest)
+	case "6":
+		showRecommendations(isGuest)
+	case "7":
+		showCharts(isGuest)
+	case "8":
+		showPeopleSearch(isGuest)
+	case "9":
+		return
+	default:
+		if option != "" {
+			fmt.Println("Opción inválida")
+			waitForEnter()
+		}
+	}
+}
+
+// Mostrar contenido audiovisual
+func showAudiovisualContent(isGuest bool) {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Contenido Audiovisual")
+	fmt.Println("═════════════════════")
+
+	contents := audiovisual.ListAll()
+	if len(contents) == 0 {
+		fmt.Println("No hay contenido disponible")
+		waitForEnter()
+		return
+	}
+
+	var shown []categories.ContentRef
+	for _, c := range contents {
+		// Verificar clasificación
+		if !isGuest && !contentclass.CanAccessContent(currentUser.Age, c.AgeRating) {
+			continue
+		}
+
+		ref := categories.ContentRef{Kind: categories.KindAudiovisual, ID: c.ID}
+		fmt.Printf("ID: %d | %s\n", c.ID, c.Title)
+		fmt.Printf("   %s • %s • %s\n", c.Type, c.Genre, utils.FormatDuration(c.Duration))
+		fmt.Printf("   Clasificación: %s • Rating: %s\n", c.AgeRating, ratingSummary(ref, c.AverageRating))
+		fmt.Println("────────────────────────────────────────────────────────────")
+		shown = append(shown, ref)
+	}
+
+	promptDetail(shown, isGuest)
+}
+
+// Mostrar contenido de audio
+func showAudioContent(isGuest bool) {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Contenido de Audio")
+	fmt.Println("══════════════════")
+
+	contents := audio.ListAll()
+	if len(contents) == 0 {
+		fmt.Println("No hay contenido disponible")
+		waitForEnter()
+		return
+	}
+
+	var shown []categories.ContentRef
+	for _, c := range contents {
+		// Verificar clasificación
+		if !isGuest && !contentclass.CanAccessContent(currentUser.Age, c.AgeRating) {
+			continue
+		}
+
+		ref := categories.ContentRef{Kind: categories.KindAudio, ID: c.ID}
+		fmt.Printf("ID: %d | %s\n", c.ID, c.Title)
+		fmt.Printf("   %s • %s • %s\n", c.Type, c.Genre, utils.FormatDuration(c.Duration))
+		fmt.Printf("   Clasificación: %s • Rating: %s\n", c.AgeRating, ratingSummary(ref, c.AverageRating))
+		fmt.Println("────────────────────────────────────────────────────────────")
+		shown = append(shown, ref)
+	}
+
+	promptDetail(shown, isGuest)
+}
+
+// Buscar contenido en todo el catálogo
+func showSearch(isGuest bool) {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Buscar Contenido")
+	fmt.Println("════════════════")
+
+	query := readInput("Buscar (0 para volver): ")
+	if query == "0" || query == "" {
+		return
+	}
+
+	// Verificar clasificación antes de limitar los resultados
+	allowed := func(r search.Result) bool {
+		return isGuest || contentclass.CanAccessContent(currentUser.Age, r.AgeRating)
+	}
+	var shown []categories.ContentRef
+	for _, r := range search.SearchAllowed(query, 20, allowed) {
+		switch r.Ref.Kind {
+		case categories.KindAudiovisual:
+			c, err := audiovisual.GetByID(r.Ref.ID)
+			if err != nil {
+				continue
+			}
+			fmt.Printf("ID: %d | %s [Audiovisual]\n", c.ID, c.Title)
+			fmt.Printf("   %s • %s • %s\n", c.Type, c.Genre, utils.FormatDuration(c.Duration))
+			fmt.Printf("   Clasificación: %s • Rating: %s\n", c.AgeRating, ratingSummary(categories.ContentRef{Kind: categories.KindAudiovisual, ID: c.ID}, c.AverageRating))
+		case categories.KindAudio:
+			c, err := audio.GetByID(r.Ref.ID)
+			if err != nil {
+				continue
+			}
+			fmt.Printf("ID: %d | %s [Audio]\n", c.ID, c.Title)
+			fmt.Printf("   %s • %s • %s\n", c.Type, c.Genre, utils.FormatDuration(c.Duration))
+			fmt.Printf("   Clasificación: %s • Rating: %s\n", c.AgeRating, ratingSummary(categories.ContentRef{Kind: categories.KindAudio, ID: c.ID}, c.AverageRating))
+		}
+		fmt.Println("────────────────────────────────────────────────────────────")
+		shown = append(shown, r.Ref)
+	}
+
+	if len(shown) == 0 {
+		fmt.Println("No se encontraron resultados")
+
+		// Ofrecer sugerencias por si hubo errores de tipeo
+		var suggestions []string
+		for _, s := range search.Suggest(query, 5) {
+			if s.Kind == search.SuggestTitle && !isGuest && !contentclass.CanAccessContent(currentUser.Age, s.AgeRating) {
+				continue
+			}
+			suggestions = append(suggestions, s.Text)
+		}
+		if len(suggestions) > 0 {
+			fmt.Println()
+			fmt.Println("¿Quisiste decir?")
+			for _, text := range suggestions {
+				fmt.Printf("   • %s\n", text)
+			}
+		}
+		waitForEnter()
+		return
+	}
+	promptDetail(shown, isGuest)
+}
+
+// Filtrar y ordenar el catálogo completo
+func showCatalogFilter(isGuest bool) {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Filtrar y Ordenar")
+	fmt.Println("═════════════════")
+	fmt.Println("Deje un campo vacío para no filtrar por él (0 para volver)")
+	fmt.Println()
+
+	var q catalog.Query
+
+	kind := readInput("Tipo de catálogo (1. Audiovisual  2. Audio): ")
+	switch kind {
+	case "0":
+		return
+	case "1":
+		q.Kind = categories.KindAudiovisual
+	case "2":
+		q.Kind = categories.KindAudio
+	}
+
+	if q.Type = readInput("Tipo (Película, Serie, Música...): "); q.Type == "0" {
+		return
+	}
+	if q.Genre = readInput("Género: "); q.Genre == "0" {
+		return
+	}
+	if q.Tag = readInput("Etiqueta (relajante, para entrenar...): "); q.Tag == "0" {
+		return
+	}
+
+	if q.Kind != categories.KindAudio {
+		yearFrom := readInput("Año desde: ")
+		if yearFrom == "0" {
+			return
+		}
+		q.YearFrom, _ = strconv.Atoi(yearFrom)
+		yearTo := readInput("Año hasta: ")
+		if yearTo == "0" {
+			return
+		}
+		q.YearTo, _ = strconv.Atoi(yearTo)
+	}
+
+	minRating := readInput("Rating mínimo: ")
+	if minRating == "0" {
+		return
+	}
+	q.MinRating, _ = utils.ToFloat(minRating)
+
+	minDuration := readInput("Duración mínima (minutos): ")
+	if minDuration == "0" {
+		return
+	}
+	q.MinDuration, _ = strconv.Atoi(minDuration)
+	maxDuration := readInput("Duración máxima (minutos): ")
+	if maxDuration == "0" {
+		return
+	}
+	q.MaxDuration, _ = strconv.Atoi(maxDuration)
+
+	fmt.Println("Ordenar por: 1. Título  2. Año  3. Rating  4. Duración")
+	switch readInput("Orden (1-4): ") {
+	case "0":
+		return
+	case "2":
+		q.SortBy = catalog.SortYear
+	case "3":
+		q.SortBy = catalog.SortRating
+	case "4":
+		q.SortBy = catalog.SortDuration
+	default:
+		q.SortBy = catalog.SortTitle
+	}
+	q.Descending = strings.EqualFold(readInput("¿Descendente? (s/n): "), "s")
+
+	if !isGuest {
+		q.ViewerAge = currentUser.Age
+	}
+	showCatalogPages(q, isGuest)
+}
+
+// Consultar el catálogo con el lenguaje de consultas
+func showAdvancedQuery(isGuest bool) {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Consulta Avanzada")
+	fmt.Println("═════════════════")
+	fmt.Println("Campos: title, type, genre, tag, age, year, rating, duration, kind, available, sort, limit")
+	fmt.Println("Operadores: :  =  !=  >  >=  <  <=")
+	fmt.Println(`Ejemplo: genre:Comedia year>=2020 rating>7 type:Serie sort:-rating`)
+	fmt.Println()
+
+	expr := readInput("Consulta (0 para volver): ")
+	if expr == "0" || expr == "" {
+		return
+	}
+
+	q, err := catalog.Parse(expr)
+	if err != nil {
+		errors.HandleAppError(err)
+		waitForEnter()
+		return
+	}
+
+	if !isGuest {
+		q.ViewerAge = currentUser.Age
+	}
+	// Solo quienes gestionan contenido ven contenido no disponible
+	if isGuest || !rbac.Can(currentUser.ID, rbac.PermContentManage) {
+		q.Availability = catalog.AvailableOnly
+	}
+	showCatalogPages(q, isGuest)
+}
+
+// Mostrar recomendaciones personalizadas según las calificaciones
+func showRecommendations(isGuest bool) {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Recomendado para ti")
+	fmt.Println("═══════════════════")
+
+	if isGuest {
+		fmt.Println("Inicie sesión y califique contenido para recibir recomendaciones")
+		waitForEnter()
+		return
+	}
+
+	recommendations := recommend.ForUser(*currentUser, 10)
+	if len(recommendations) == 0 {
+		fmt.Println("Aún no tenemos recomendaciones para usted.")
+		fmt.Println("Califique más contenido para que podamos conocer sus gustos.")
+	}
+
+	var shown []categories.ContentRef
+	for _, r := range recommendations {
+		item, err := catalog.Get(r.Ref)
+		if err != nil {
+			continue
+		}
+		shown = append(shown, item.Ref)
+		fmt.Printf("ID: %d | %s\n", item.Ref.ID, item.Title)
+		fmt.Printf("   %s • %s • %s\n", item.Type, item.Genre, utils.FormatDuration(item.Duration))
+		fmt.Printf("   Clasificación: %s • Rating: %s\n", item.AgeRating, ratingSummary(item.Ref, item.AverageRating))
+		if because, err := catalog.Get(r.Because); err == nil {
+			fmt.Printf("   Porque calificó \"%s\"\n", because.Title)
+		}
+		fmt.Println("────────────────────────────────────────────────────────────")
+	}
+
+	// Quien audita calificaciones también puede evaluar el recomendador desde acá
+	if rbac.Can(currentUser.ID, rbac.PermRatingsAudit) {
+		answer := readInput("ID para ver detalle, E para evaluar el recomendador (0 para volver): ")
+		switch {
+		case strings.EqualFold(answer, "e"):
+			showRecommenderEvaluation()
+		case answer != "0" && answer != "":
+			openDetail(shown, answer, isGuest)
+		}
+		return
+	}
+	promptDetail(shown, isGuest)
+}
+
+// Evaluar el recomendador con las calificaciones reales
+func showRecommenderEvaluation() {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Evaluación del Recomendador")
+	fmt.Println("═══════════════════════════")
+	fmt.Println("Se separa una parte de las calificaciones de cada usuario y se mide")
+	fmt.Println("cuántas de las que le gustaron aparecen entre sus recomendaciones.")
+	fmt.Println()
+
+	holdout, k, threshold := 0.2, 10, 7.0
+	if value := readInput("Fracción separada (Enter para 0.2): "); value != "" {
+		parsed, err := utils.ToFloat(value)
+		if err != nil {
+			fmt.Println("Fracción inválida")
+			waitForEnter()
+			return
+		}
+		holdout = parsed
+	}
+	if value := readInput("Tamaño de la lista K (Enter para 10): "); value != "" {
+		parsed, err := utils.ToInt(value)
+		if err != nil {
+			fmt.Println("K inválido")
+			waitForEnter()
+			return
+		}
+		k = parsed
+	}
+	if value := readInput("Calificación mínima para que un contenido gustó (Enter para 7): "); value != "" {
+		parsed, err := utils.ToFloat(value)
+		if err != nil {
+			fmt.Println("Calificación inválida")
+			waitForEnter()
+			return
+		}
+		threshold = parsed
+	}
+
+	result, err := admin.EvaluateRecommender(currentUser.ID, holdout, k, threshold)
+	if err != nil {
+		errors.HandleAppError(err)
+		waitForEnter()
+		return
+	}
+	fmt.Println()
+	if result.Users == 0 {
+		fmt.Println("No hay usuarios con calificaciones relevantes separadas para evaluar")
+		waitForEnter()
+		return
+	}
+	fmt.Printf("Usuarios evaluados: %d (K=%d, umbral %.1f)\n", result.Users, result.K, result.Threshold)
+	fmt.Printf("%-28s precision@%d=%.3f  recall@%d=%.3f\n", "Filtrado colaborativo", result.K, result.Precision, result.K, result.Recall)
+	fmt.Printf("%-28s precision@%d=%.3f  recall@%d=%.3f\n", "Referencia por popularidad", result.K, result.PopularPrecision, result.K, result.PopularRecall)
+	waitForEnter()
+}
+
+// Mostrar rankings de mejor calificados y tendencias
+func showCharts(isGuest bool) {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Rankings")
+	fmt.Println("════════")
+	fmt.Println()
+	fmt.Println("1. Mejor Calificados")
+	fmt.Println("2. Mejor Calificados - Audiovisual")
+	fmt.Println("3. Mejor Calificados - Audio")
+	fmt.Println("4. Mejor Calificados por Género")
+	fmt.Println("5. Tendencias")
+	fmt.Println("6. Volver")
+	fmt.Println("────────────────────────────────────────────────────────────")
+
+	option := readInput("Seleccione una opción: ")
+
+	filter := charts.Filter{}
+	if !isGuest {
+		filter.Allow = func(item catalog.Item) bool {
+			return contentclass.CanAccessContent(currentUser.Age, item.AgeRating)
+		}
+	}
+
+	var title string
+	var entries []charts.Entry
+	switch option {
+	case "1":
+		title = "Mejor Calificados"
+		entries = charts.TopRated(filter, 10, charts.DefaultConfig)
+	case "2":
+		title = "Mejor Calificados - Audiovisual"
+		filter.Kind = categories.KindAudiovisual
+		entries = charts.TopRated(filter, 10, charts.DefaultConfig)
+	case "3":
+		title = "Mejor Calificados - Audio"
+		filter.Kind = categories.KindAudio
+		entries = charts.TopRated(filter, 10, charts.DefaultConfig)
+	case "4":
+		filter.Genre = readInput("Género: ")
+		if filter.Genre == "0" || filter.Genre == "" {
+			return
+		}
+		title = "Mejor Calificados - " + filter.Genre
+		entries = charts.TopRated(filter, 10, charts.DefaultConfig)
+	case "5":
+		title = "Tendencias"
+		entries = charts.Trending(filter, 10, charts.DefaultConfig, time.Now())
+	case "6":
+		return
+	default:
+		if option != "" {
+			fmt.Println("Opción inválida")
+			waitForEnter()
+		}
+		return
+	}
+
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println(title)
+	fmt.Println("══════════════════════════════")
+
+	if len(entries) == 0 {
+		fmt.Println("Todavía no hay calificaciones suficientes para este ranking")
+		waitForEnter()
+		return
+	}
+
+	for i, e := range entries {
+		fmt.Printf("%d. %s\n", i+1, e.Item.Title)
+		fmt.Printf("   %s • %s • %s\n", e.Item.Type, e.Item.Genre, utils.FormatDuration(e.Item.Duration))
+		if option == "5" {
+			fmt.Printf("   Calificaciones recientes: %d\n", e.Votes)
+		} else {
+			fmt.Printf("   Puntaje: %s • Rating: %s (%d votos)\n", utils.FormatRating(e.Score), utils.FormatRating(e.Item.AverageRating), e.Votes)
+		}
+		fmt.Println("────────────────────────────────────────────────────────────")
+	}
+
+	position, err := strconv.Atoi(readInput("Posición para ver detalle (0 para volver): "))
+	if err == nil && position >= 1 && position <= len(entries) {
+		showContentDetail(entries[position-1].Item.Ref, isGuest)
+	}
+}
+
+// Mostrar los resultados de una consulta página por página
+func showCatalogPages(q catalog.Query, isGuest bool) {
+	for {
+		page, err := catalog.Find(q)
+		if err != nil {
+			errors.HandleAppError(err)
+			waitForEnter()
+			return
+		}
+
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Printf("Resultados (%d)\n", page.Total)
+		fmt.Println("══════════════")
+
+		if page.Total == 0 {
+			fmt.Println("No hay contenido que cumpla los filtros")
+			waitForEnter()
+			return
+		}
+
+		var shown []categories.ContentRef
+		for _, item := range page.Items {
+			shown = append(shown, item.Ref)
+			kindLabel := "Audiovisual"
+			if item.Ref.Kind == categories.KindAudio {
+				kindLabel = "Audio"
+			}
+			fmt.Printf("ID: %d | %s [%s]\n", item.Ref.ID, item.Title, kindLabel)
+			fmt.Printf("   %s • %s • %s\n", item.Type, item.Genre, utils.FormatDuration(item.Duration))
+			if item.ReleaseYear > 0 {
+				fmt.Printf("   Año: %d • ", item.ReleaseYear)
+			} else {
+				fmt.Print("   ")
+			}
+			fmt.Printf("Clasificación: %s • Rating: %s\n", item.AgeRating, ratingSummary(item.Ref, item.AverageRating))
+			fmt.Println("────────────────────────────────────────────────────────────")
+		}
+
+		if page.NextCursor == "" {
+			promptDetail(shown, isGuest)
+			return
+		}
+		// En una página intermedia, un ID abre el detalle y Enter avanza
+		answer := readInput("ID para ver detalle, Enter para ver más, 0 para volver: ")
+		if answer == "0" {
+			return
+		}
+		if answer != "" {
+			openDetail(shown, answer, isGuest)
+			continue
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// Pedir un ID de los contenidos mostrados y abrir su detalle
+func promptDetail(shown []categories.ContentRef, isGuest bool) {
+	if len(shown) == 0 {
+		waitForEnter()
+		return
+	}
+	answer := readInput("ID para ver detalle (0 para volver): ")
+	if answer == "0" || answer == "" {
+		return
+	}
+	openDetail(shown, answer, isGuest)
+}
+
+// Abrir el detalle del contenido mostrado con el ID indicado
+func openDetail(shown []categories.ContentRef, idStr string, isGuest bool) {
+	contentID, err := strconv.Atoi(idStr)
+	if err != nil {
+		fmt.Println("ID inválido")
+		waitForEnter()
+		return
+	}
+
+	var matches []categories.ContentRef
+	for _, ref := range shown {
+		if ref.ID == contentID {
+			matches = append(matches, ref)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		fmt.Println("Contenido no encontrado")
+		waitForEnter()
+	case 1:
+		showContentDetail(matches[0], isGuest)
+	default:
+		// El mismo ID existe en ambos catálogos
+		switch readInput("1. Audiovisual  2. Audio: ") {
+		case "1":
+			showContentDetail(categories.ContentRef{Kind: categories.KindAudiovisual, ID: contentID}, isGuest)
+		case "2":
+			showContentDetail(categories.ContentRef{Kind: categories.KindAudio, ID: contentID}, isGuest)
+		}
+	}
+}
+
+// Mostrar el detalle completo de un contenido con sus acciones
+func showContentDetail(ref categories.ContentRef, isGuest bool) {
+	for {
+		item, err := catalog.Get(ref)
+		// Solo quienes gestionan contenido ven contenido no disponible
+		if err != nil || (!item.IsAvailable && (isGuest || !rbac.Can(currentUser.ID, rbac.PermContentManage))) {
+			fmt.Println("Contenido no encontrado")
+			waitForEnter()
+			return
+		}
+		if !isGuest && !contentclass.CanAccessContent(currentUser.Age, item.AgeRating) {
+			fmt.Println("Contenido no disponible para su clasificación")
+			waitForEnter()
+			return
+		}
+
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Println(item.Title)
+		fmt.Println("══════════════════════════════")
+		fmt.Printf("Tipo: %s • Duración: %s\n", item.Type, utils.FormatDuration(item.Duration))
+		fmt.Printf("Géneros: %s\n", strings.Join(item.Genres, ", "))
+		if len(item.Tags) > 0 {
+			fmt.Printf("Etiquetas: %s\n", strings.Join(item.Tags, ", "))
+		}
+		switch ref.Kind {
+		case categories.KindAudiovisual:
+			if c, err := audiovisual.GetByID(ref.ID); err == nil {
+				fmt.Printf("Año: %d\n", c.ReleaseYear)
+				fmt.Println("Sinopsis:")
+				fmt.Printf("   %s\n", c.Synopsis)
+			}
+		case categories.KindAudio:
+			if c, err := audio.GetByID(ref.ID); err == nil {
+				fmt.Printf("Álbum: %s • Pista: %d\n", c.Album, c.TrackNumber)
+			}
+		}
+		// Director y artista se muestran como personas acreditadas
+		credits := people.CreditsFor(ref)
+		for _, c := range credits {
+			if p, err := people.GetByID(c.PersonID); err == nil {
+				fmt.Printf("%s: %s (ID persona: %d)\n", people.RoleLabels[c.Role], p.Name, p.ID)
+			}
+		}
+		fmt.Printf("Clasificación: %s\n", item.AgeRating)
+		if !item.IsAvailable {
+			fmt.Println("Estado: no disponible")
+		}
+		fmt.Printf("Rating: %s\n", ratingSummary(ref, item.AverageRating))
+		if count := len(reviews.ListForContent(ref)); count > 0 {
+			fmt.Printf("Reseñas: %d\n", count)
+		}
+
+		if !isGuest {
+			if own, ok := ratings.GetUserRating(ref, currentUser.ID); ok {
+				fmt.Printf("Su calificación: %s\n", utils.FormatRating(own.Rating))
+			} else {
+				fmt.Println("Su calificación: sin calificar")
+			}
+			if library.InList(currentUser.ID, ref) {
+				fmt.Println("En Mi Lista")
+			}
+		}
+		showSimilar(ref)
+
+		fmt.Println("────────────────────────────────────────────────────────────")
+		if isGuest {
+			fmt.Println("Inicie sesión para calificar, guardar o reproducir contenido")
+			waitForEnter()
+			return
+		}
+
+		inList := library.InList(currentUser.ID, ref)
+		fmt.Println("1. Calificar")
+		if inList {
+			fmt.Println("2. Quitar de Mi Lista")
+		} else {
+			fmt.Println("2. Agregar a Mi Lista")
+		}
+		fmt.Println("3. Reproducir")
+		fmt.Println("4. Ver Reseñas")
+		fmt.Println("5. Ver Persona")
+		fmt.Println("6. Volver")
+		fmt.Println("────────────────────────────────────────────────────────────")
+
+		option := readInput("Seleccione una opción: ")
+		switch option {
+		case "1":
+			if ref.Kind == categories.KindAudio {
+				rateAudioContent(ref.ID)
+			} else {
+				rateAudiovisualContent(ref.ID)
+			}
+		case "2":
+			if inList {
+				err = library.RemoveFromList(currentUser.ID, ref)
+			} else {
+				err = library.AddToList(currentUser.ID, ref)
+			}
+			if err != nil {
+				errors.HandleAppError(err)
+				waitForEnter()
+			}
+		case "3":
+			if err := library.RecordPlay(currentUser.ID, ref); err != nil {
+				errors.HandleAppError(err)
+			} else {
+				fmt.Printf("▶ Reproduciendo: %s (%s)\n", item.Title, utils.FormatDuration(item.Duration))
+			}
+			waitForEnter()
+		case "4":
+			showReviews(ref)
+		case "5":
+			if len(credits) == 0 {
+				fmt.Println("Este contenido no tiene personas acreditadas")
+				waitForEnter()
+				continue
+			}
+			personID, err := strconv.Atoi(readInput("ID de la persona: "))
+			if err == nil {
+				showPersonPage(personID, isGuest)
+			}
+		case "6", "0":
+			return
+		default:
+			if option != "" {
+				fmt.Println("Opción inválida")
+				waitForEnter()
+			}
+		}
+	}
+}
+
+// Buscar personas por nombre y abrir su página
+func showPeopleSearch(isGuest bool) {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Personas")
+	fmt.Println("════════")
+
+	query := readInput("Nombre (0 para volver): ")
+	if query == "0" || query == "" {
+		return
+	}
+
+	results := search.SearchPeople(query, 20)
+	if len(results) == 0 {
+		fmt.Println("No se encontraron personas")
+		waitForEnter()
+		return
+	}
+	for _, r := range results {
+		fmt.Printf("ID: %d | %s (%d créditos)\n", r.Person.ID, r.Person.Name, r.Credits)
+	}
+	fmt.Println("────────────────────────────────────────────────────────────")
+
+	answer := readInput("ID de la persona (0 para volver): ")
+	if answer == "0" || answer == "" {
+		return
+	}
+	personID, err := strconv.Atoi(answer)
+	if err != nil {
+		fmt.Println("ID inválido")
+		waitForEnter()
+		return
+	}
+	showPersonPage(personID, isGuest)
+}
+
+// Mostrar la filmografía o discografía de una persona agrupada por rol
+func showPersonPage(personID int, isGuest bool) {
+	person, err := people.GetByID(personID)
+	if err != nil {
+		errors.HandleAppError(err)
+		waitForEnter()
+		return
+	}
+
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println(person.Name)
+	fmt.Println("══════════════════════════════")
+
+	var shown []categories.ContentRef
+	lastRole := ""
+	for _, c := range people.CreditsOf(person.ID) {
+		item, err := catalog.Get(c.Ref)
+		if err != nil || !item.IsAvailable {
+			continue
+		}
+		if !isGuest && !contentclass.CanAccessContent(currentUser.Age, item.AgeRating) {
+			continue
+		}
+		if c.Role != lastRole {
+			fmt.Println()
+			fmt.Println(people.RoleLabels[c.Role])
+			fmt.Println("───────────────────────")
+			lastRole = c.Role
+		}
+		kindLabel := "Audiovisual"
+		if item.Ref.Kind == categories.KindAudio {
+			kindLabel = "Audio"
+		}
+		if item.ReleaseYear > 0 {
+			fmt.Printf("ID: %d | %s (%d) [%s]\n", item.Ref.ID, item.Title, item.ReleaseYear, kindLabel)
+		} else {
+			fmt.Printf("ID: %d | %s [%s]\n", item.Ref.ID, item.Title, kindLabel)
+		}
+		shown = append(shown, item.Ref)
+	}
+
+	if len(shown) == 0 {
+		fmt.Println("No hay contenido disponible de esta persona")
+	}
+	fmt.Println()
+	promptDetail(shown, isGuest)
+}
+
+// Mostrar la lista de contenidos guardados por el usuario
+func showMyList() {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Mi Lista")
+	fmt.Println("════════")
+
+	var shown []categories.ContentRef
+	for _, e := range library.List(currentUser.ID) {
+		item, err := catalog.Get(e.Ref)
+		if err != nil || !item.IsAvailable {
+			continue
+		}
+		fmt.Printf("ID: %d | %s\n", item.Ref.ID, item.Title)
+		fmt.Printf("   %s • %s • %s\n", item.Type, item.Genre, utils.FormatDuration(item.Duration))
+		fmt.Printf("   Agregado: %s\n", e.AddedAt.Format("02/01/2006 15:04"))
+		fmt.Println("────────────────────────────────────────────────────────────")
+		shown = append(shown, item.Ref)
+	}
+
+	if len(shown) == 0 {
+		fmt.Println("Su lista está vacía. Agregue contenido desde su detalle.")
+	}
+	promptDetail(shown, false)
+}
+
+// Mostrar el historial de reproducción del usuario
+func showPlayHistory() {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Historial de Reproducción")
+	fmt.Println("═════════════════════════")
+
+	var shown []categories.ContentRef
+	for _, p := range library.History(currentUser.ID) {
+		item, err := catalog.Get(p.Ref)
+		if err != nil {
+			continue
+		}
+		fmt.Printf("ID: %d | %s • %s\n", item.Ref.ID, item.Title, p.PlayedAt.Format("02/01/2006 15:04"))
+		shown = append(shown, item.Ref)
+	}
+
+	if len(shown) == 0 {
+		fmt.Println("Todavía no reprodujo contenido")
+	}
+	promptDetail(shown, false)
+}
+
+// Calificar contenido audiovisual
+func rateAudiovisualContent(contentID int) {
+	if !requireVerifiedEmail() {
+		return
+	}
+	c, err := audiovisual.GetByID(contentID)
+	if err != nil {
+		fmt.Println("Contenido no encontrado")
+		waitForEnter()
+		return
+	}
+
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Printf("Calificar: %s\n", c.Title)
+	fmt.Println("══════════════")
+	ref := categories.ContentRef{Kind: categories.KindAudiovisual, ID: contentID}
+	showRatingDistribution(ref)
+
+	prompt := "Calificación (1.0 - 10.0): "
+	_, hasRating := ratings.GetUserRating(ref, currentUser.ID)
+	if hasRating {
+		prompt = "Calificación (1.0 - 10.0, R para retirar la suya): "
+	}
+	hasReviews := len(reviews.ListForContent(ref)) > 0
+	if hasReviews {
+		prompt = strings.TrimSuffix(prompt, "): ") + ", V para ver reseñas): "
+	}
+
+	ratingStr := readInput(prompt)
+	if hasReviews && strings.EqualFold(ratingStr, "v") {
+		showReviews(ref)
+		return
+	}
+	if hasRating && strings.EqualFold(ratingStr, "r") {
+		if err := audiovisual.WithdrawRating(contentID, currentUser.ID); err != nil {
+			errors.HandleAppError(err)
+		} else {
+			fmt.Println(" Calificación retirada")
+		}
+		waitForEnter()
+		return
+	}
+	rating, err := utils.ToFloat(ratingStr)
+	if err != nil || rating < 1.0 || rating > 10.0 {
+		fmt.Println("Calificación inválida")
+		waitForEnter()
+		return
+	}
+
+	message, err := audiovisual.RateContent(contentID, currentUser.ID, rating)
+	if err != nil {
+		errors.HandleAppError(err)
+	} else {
+		fmt.Printf(" %s\n", message)
+		writeReview(ref)
+	}
+	showSimilar(ref)
+	waitForEnter()
+}
+
+// Calificar contenido de audio
+func rateAudioContent(contentID int) {
+	if !requireVerifiedEmail() {
+		return
+	}
+	c, err := audio.GetByID(contentID)
+	if err != nil {
+		fmt.Println("Contenido no encontrado")
+		waitForEnter()
+		return
+	}
+
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Printf("Calificar: %s\n", c.Title)
+	fmt.Println("══════════════")
+	ref := categories.ContentRef{Kind: categories.KindAudio, ID: contentID}
+	showRatingDistribution(ref)
+
+	prompt := "Calificación (1.0 - 10.0): "
+	_, hasRating := ratings.GetUserRating(ref, currentUser.ID)
+	if hasRating {
+		prompt = "Calificación (1.0 - 10.0, R para retirar la suya): "
+	}
+	hasReviews := len(reviews.ListForContent(ref)) > 0
+	if hasReviews {
+		prompt = strings.TrimSuffix(prompt, "): ") + ", V para ver reseñas): "
+	}
+
+	ratingStr := readInput(prompt)
+	if hasReviews && strings.EqualFold(ratingStr, "v") {
+		showReviews(ref)
+		return
+	}
+	if hasRating && strings.EqualFold(ratingStr, "r") {
+		if err := audio.WithdrawRating(contentID, currentUser.ID); err != nil {
+			errors.HandleAppError(err)
+		} else {
+			fmt.Println(" Calificación retirada")
+		}
+		waitForEnter()
+		return
+	}
+	rating, err := utils.ToFloat(ratingStr)
+	if err != nil || rating < 1.0 || rating > 10.0 {
+		fmt.Println("Calificación inválida")
+		waitForEnter()
+		return
+	}
+
+	message, err := audio.RateContent(contentID, currentUser.ID, rating)
+	if err != nil {
+		errors.HandleAppError(err)
+	} else {
+		fmt.Printf(" %s\n", message)
+		writeReview(ref)
+	}
+	showSimilar(ref)
+	waitForEnter()
+}
+
+// Mostrar la distribución de calificaciones de un contenido
+func showRatingDistribution(ref categories.ContentRef) {
+	distribution := ratings.Distribution(ref)
+	total := ratings.Count(ref)
+	if total == 0 {
+		fmt.Println("Aún no tiene calificaciones")
+		fmt.Println()
+		return
+	}
+
+	fmt.Printf("Distribución de calificaciones (%d votos)\n", total)
+	for i := len(distribution) - 1; i >= 0; i-- {
+		bar := strings.Repeat("█", distribution[i]*30/total)
+		fmt.Printf("%2d │ %-30s %d\n", i+1, bar, distribution[i])
+	}
+	if own, ok := ratings.GetUserRating(ref, currentUser.ID); ok {
+		fmt.Printf("Su calificación actual: %s\n", utils.FormatRating(own.Rating))
+	}
+	fmt.Println()
+}
+
+// Ofrecer escribir una reseña junto a la calificación recién hecha
+func writeReview(ref categories.ContentRef) {
+	answer := readInput("¿Desea escribir una reseña? (s/n): ")
+	if !strings.EqualFold(answer, "s") {
+		return
+	}
+
+	text := readInput("Reseña (10 a 1000 caracteres): ")
+	review, err := reviews.AddReview(ref, currentUser.ID, text)
+	if err != nil {
+		errors.HandleAppError(err)
+		return
+	}
+	if review.Status == reviews.StatusPending {
+		fmt.Printf(" Reseña enviada a moderación (%s)\n", strings.Join(review.Flags, ", "))
+	} else {
+		fmt.Println(" Reseña publicada")
+	}
+}
+
+// Mostrar las reseñas aprobadas de un contenido y permitir votarlas
+func showReviews(ref categories.ContentRef) {
+	for {
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		title := ""
+		if item, err := catalog.Get(ref); err == nil {
+			title = item.Title
+		}
+		fmt.Printf("Reseñas: %s\n", title)
+		fmt.Println("══════════════")
+
+		list := reviews.ListForContent(ref)
+		if len(list) == 0 {
+			fmt.Println("No hay reseñas publicadas")
+			waitForEnter()
+			return
+		}
+		for _, r := range list {
+			author := "Usuario eliminado"
+			if user, err := profiles.FindByID(r.UserID); err == nil {
+				author = user.Name
+			}
+			fmt.Printf("[%d] %s - %s/10 (%s)\n", r.ID, author, utils.FormatRating(r.Rating), r.CreatedAt.Format("2006-01-02"))
+			fmt.Printf("    %s\n", r.Text)
+			fmt.Printf("    Útil: %d | No útil: %d\n", r.Helpful(), r.Unhelpful())
+			fmt.Println()
+		}
+
+		idStr := readInput("ID de la reseña para votarla (Enter para volver): ")
+		if idStr == "" {
+			return
+		}
+		reviewID, err := utils.ToInt(idStr)
+		if err != nil {
+			fmt.Println("ID inválido")
+			waitForEnter()
+			continue
+		}
+		answer := readInput("¿Le resultó útil? (s/n): ")
+		if err := reviews.Vote(reviewID, currentUser.ID, strings.EqualFold(answer, "s")); err != nil {
+			errors.HandleAppError(err)
+			waitForEnter()
+		}
+	}
+}
+
+// Cola de moderación de reseñas (admin)
+func showModerationQueue() {
+	for {
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Println("Moderación de Reseñas")
+		fmt.Println("═════════════════════")
+
+		queue, err := admin.GetModerationQueue(currentUser.ID)
+		if err != nil {
+			fmt.Println("No tienes permisos")
+			waitForEnter()
+			return
+		}
+		if len(queue) == 0 {
+			fmt.Println("No hay reseñas pendientes")
+			waitForEnter()
+			return
+		}
+
+		for _, r := range queue {
+			title := ""
+			if item, err := catalog.Get(r.Ref); err == nil {
+				title = item.Title
+			}
+			fmt.Printf("[%d] %s - usuario %d, %s/10\n", r.ID, title, r.UserID, utils.FormatRating(r.Rating))
+			fmt.Printf("    %s\n", r.Text)
+			fmt.Printf("    Motivos: %s\n", strings.Join(r.Flags, ", "))
+			fmt.Println()
+		}
+
+		idStr := readInput("ID de la reseña a moderar (Enter para volver): ")
+		if idStr == "" {
+			return
+		}
+		reviewID, err := utils.ToInt(idStr)
+		if err != nil {
+			fmt.Println("ID inválido")
+			waitForEnter()
+			continue
+		}
+
+		fmt.Println("1. Aprobar")
+		fmt.Println("2. Ocultar")
+		fmt.Println("3. Eliminar")
+		var action string
+		switch readInput("Seleccione una acción: ") {
+		case "1":
+			action = reviews.ActionApprove
+		case "2":
+			action = reviews.ActionHide
+		case "3":
+			action = reviews.ActionDelete
+		default:
+			fmt.Println("Acción inválida")
+			waitForEnter()
+			continue
+		}
+
+		reason := readInput("Motivo: ")
+		if err := admin.ModerateReview(currentUser.ID, reviewID, action, reason); err != nil {
+			errors.HandleAppError(err)
+		} else {
+			fmt.Println(" Reseña moderada")
+		}
+		waitForEnter()
+	}
+}
+
+// Revisión de calificaciones sospechosas (admin)
+func showRatingAnomalies() {
+	for {
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Println("Calificaciones Sospechosas")
+		fmt.Println("══════════════════════════")
+
+		flags, err := admin.ScanRatings(currentUser.ID)
+		if err != nil {
+			fmt.Println("No tienes permisos")
+			waitForEnter()
+			return
+		}
+		if len(flags) == 0 {
+			fmt.Println("No hay alertas pendientes")
+		}
+		for _, f := range flags {
+			title := ""
+			if item, err := catalog.Get(f.Ref); err == nil {
+				title = item.Title
+			}
+			email := "usuario eliminado"
+			if user, err := profiles.FindByID(f.UserID); err == nil {
+				email = user.Email
+			}
+			fmt.Printf("[%d] %s - %s calificó %s\n", f.ID, title, email, utils.FormatRating(f.Rating))
+			fmt.Printf("    %s: %s\n", f.Rule, f.Detail)
+		}
+		fmt.Println()
+
+		idStr := readInput("ID de la alerta a resolver, A para ver la auditoría (Enter para volver): ")
+		if idStr == "" {
+			return
+		}
+		if strings.EqualFold(idStr, "a") {
+			showExclusionAudit()
+			continue
+		}
+		flagID, err := utils.ToInt(idStr)
+		if err != nil {
+			fmt.Println("ID inválido")
+			waitForEnter()
+			continue
+		}
+
+		fmt.Println("1. Excluir del promedio")
+		fmt.Println("2. Descartar alerta")
+		var exclude bool
+		switch readInput("Seleccione una acción: ") {
+		case "1":
+			exclude = true
+		case "2":
+			exclude = false
+		default:
+			fmt.Println("Acción inválida")
+			waitForEnter()
+			continue
+		}
+
+		reason := readInput("Motivo: ")
+		if err := admin.ResolveRatingFlag(currentUser.ID, flagID, exclude, reason); err != nil {
+			errors.HandleAppError(err)
+		} else {
+			fmt.Println(" Alerta resuelta")
+		}
+		waitForEnter()
+	}
+}
+
+// Auditoría de calificaciones excluidas y restauradas (admin)
+func showExclusionAudit() {
+	fmt.Print("\033[H\033[2J")
+	showHeader()
+	fmt.Println("Auditoría de Exclusiones")
+	fmt.Println("════════════════════════")
+
+	entries, err := admin.GetExclusionAudit(currentUser.ID)
+	if err != nil {
+		fmt.Println("No tienes permisos")
+		waitForEnter()
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Println("No hay decisiones registradas")
+		waitForEnter()
+		return
+	}
+
+	for i, e := range entries {
+		title := ""
+		if item, err := catalog.Get(e.Ref); err == nil {
+			title = item.Title
+		}
+		fmt.Printf("%d. %s %s - usuario %d, %s (%s)\n", i+1, e.At.Format("2006-01-02 15:04"), e.Action, e.UserID, title, utils.FormatRating(e.Rating))
+		fmt.Printf("   Motivo: %s | Administrador %d\n", e.Reason, e.AdminID)
+	}
+	fmt.Println()
+
+	// Solo se puede restaurar una calificación que sigue excluida
+	numStr := readInput("Número de una exclusión para restaurarla (Enter para volver): ")
+	if numStr == "" {
+		return
+	}
+	num, err := utils.ToInt(numStr)
+	if err != nil || num < 1 || num > len(entries) || entries[num-1].Action != anomaly.ActionExclude {
+		fmt.Println("Número inválido")
+		waitForEnter()
+		return
+	}
+
+	entry := entries[num-1]
+	reason := readInput("Motivo: ")
+	if err := admin.RestoreRating(currentUser.ID, entry.Ref, entry.UserID, reason); err != nil {
+		errors.HandleAppError(err)
+	} else {
+		fmt.Println(" Calificación restaurada")
+	}
+	waitForEnter()
+}
+
+// Mostrar contenido parecido al indicado
+func showSimilar(ref categories.ContentRef) {
+	similar, err := recommend.Similar(ref, 3, func(item catalog.Item) bool {
+		return currentUser == nil || contentclass.CanAccessContent(currentUser.Age, item.AgeRating)
+	})
+	if err != nil || len(similar) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Más como esto")
+	fmt.Println("───────────────────────")
+	for _, s := range similar {
+		if item, err := catalog.Get(s.Ref); err == nil {
+			fmt.Printf("• %s (%s • %s)\n", item.Title, item.Type, item.Genre)
+		}
+	}
+}
+
+// Gestión de usuarios (admin)
 func showUserManagement() {
-	fmt.Print("\033[H\033[2J")
-	showHeader()
-	fmt.Println("Gestión de Usuarios")
-	fmt.Println("═══════════════════")
+	for {
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Println("Gestión de Usuarios")
+		fmt.Println("═══════════════════")
+
+		users, err := admin.GetAllUsers(currentUser.ID)
+		if err != nil {
+			fmt.Println("No tienes permisos")
+			waitForEnter()
+			return
+		}
+		sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+		for _, u := range users {
+			fmt.Printf("ID: %d | %s%s\n", u.ID, u.Name, roleTags(u))
+			fmt.Printf("   %s • %d años • %s • %s\n", u.Email, u.Age, u.Plan, u.AgeRating)
+			if u.Disabled {
+				fmt.Println("   Cuenta deshabilitada")
+			}
+			if u.MustResetPassword {
+				fmt.Println("   Debe restablecer su contraseña")
+			}
+			fmt.Println("────────────────────────────────────────────────────────────")
+		}
+
+		perms := rbac.PermissionsOf(currentUser.ID)
+		var options []menuEntry
+		if perms[rbac.PermUsersManage] {
+			options = append(options,
+				menuEntry{"Editar Usuario", editUser},
+				menuEntry{"Deshabilitar / Rehabilitar Cuenta", toggleUserDisabled},
+				menuEntry{"Forzar Restablecimiento de Contraseña", forcePasswordReset},
+			)
+		}
+		if perms[rbac.PermUsersDelete] {
+			options = append(options, menuEntry{"Eliminar Usuario", deleteUser})
+		}
+		if perms[rbac.PermRolesAssign] {
+			options = append(options, menuEntry{"Asignar Roles", assignRoles})
+		}
+		if perms[rbac.PermPlansManage] {
+			options = append(options, menuEntry{"Cambiar Plan", changeUserPlan})
+		}
+		for i, o := range options {
+			fmt.Printf("%d. %s\n", i+1, o.label)
+		}
+		fmt.Printf("%d. Volver\n", len(options)+1)
+
+		num, err := strconv.Atoi(readInput("Seleccione una opción: "))
+		if err != nil || num < 1 || num > len(options) {
+			return
+		}
+		options[num-1].action()
+		// Los cambios sobre la propia cuenta se reflejan al volver al menú
+		if user, err := profiles.FindByID(currentUser.ID); err == nil {
+			currentUser = user
+		}
+	}
+}
 
-	users, err := admin.GetAllUsers(currentUser.ID)
+// Mostrar los roles de un usuario entre corchetes
+func roleTags(u categories.User) string {
+	tags := ""
+	for _, r := range u.Roles {
+		tags += " [" + rbac.RoleLabels[r] + "]"
+	}
+	return tags
+}
+
+// Editar el nombre y la clasificación por edad de un usuario (admin)
+func editUser() {
+	userID, err := strconv.Atoi(readInput("ID del usuario: "))
 	if err != nil {
-		fmt.Println("No tienes permisos")
+		return
+	}
+	user, err := profiles.FindByID(userID)
+	if err != nil {
+		errors.HandleAppError(err)
 		waitForEnter()
 		return
 	}
 
-	for _, u := range users {
-		adminTag := ""
-		if u.IsAdmin {
-			adminTag = " [ADMIN]"
+	name := user.Name
+	if input := readInput(fmt.Sprintf("Nombre [%s]: ", user.Name)); input != "" {
+		name = input
+	}
+	ageRating := user.AgeRating
+	fmt.Println("Clasificación por edad:")
+	for i, r := range contentclass.AgeRatings {
+		fmt.Printf("%d. %s\n", i+1, r)
+	}
+	if input := readInput(fmt.Sprintf("Clasificación [%s]: ", user.AgeRating)); input != "" {
+		i, err := strconv.Atoi(input)
+		if err != nil || i < 1 || i > len(contentclass.AgeRatings) {
+			fmt.Println("Clasificación inválida")
+			waitForEnter()
+			return
 		}
-		fmt.Printf("ID: %d | %s%s\n", u.ID, u.Name, adminTag)
-		fmt.Printf("   %s • %d años • %s\n", u.Email, u.Age, u.Plan)
-		fmt.Println("────────────────────────────────────────────────────────────")
+		ageRating = contentclass.AgeRatings[i-1]
+	}
+
+	if err := admin.UpdateUser(currentUser.ID, userID, name, ageRating); err != nil {
+		errors.HandleAppError(err)
+	} else {
+		fmt.Println(" Usuario actualizado")
+	}
+	waitForEnter()
+}
+
+// Deshabilitar una cuenta habilitada o rehabilitar una deshabilitada (admin)
+func toggleUserDisabled() {
+	userID, err := strconv.Atoi(readInput("ID del usuario: "))
+	if err != nil {
+		return
+	}
+	user, err := profiles.FindByID(userID)
+	if err != nil {
+		errors.HandleAppError(err)
+		waitForEnter()
+		return
+	}
+
+	action := "deshabilitar"
+	if user.Disabled {
+		action = "rehabilitar"
+	}
+	if strings.ToLower(readInput(fmt.Sprintf("¿Confirma %s la cuenta de %s? (s/n): ", action, user.Email))) != "s" {
+		return
+	}
+	if err := admin.SetUserDisabled(currentUser.ID, userID, !user.Disabled); err != nil {
+		errors.HandleAppError(err)
+	} else if user.Disabled {
+		fmt.Println(" Cuenta rehabilitada")
+	} else {
+		fmt.Println(" Cuenta deshabilitada y sesiones cerradas")
+	}
+	waitForEnter()
+}
+
+// Obligar a un usuario a restablecer su contraseña (admin)
+func forcePasswordReset() {
+	userID, err := strconv.Atoi(readInput("ID del usuario: "))
+	if err != nil {
+		return
+	}
+	if err := admin.ForcePasswordReset(currentUser.ID, userID); err != nil {
+		errors.HandleAppError(err)
+	} else {
+		fmt.Println(" Se cerraron sus sesiones y se le envió un código por email")
+	}
+	waitForEnter()
+}
+
+// Eliminar una cuenta junto con sus calificaciones (admin)
+func deleteUser() {
+	userID, err := strconv.Atoi(readInput("ID del usuario: "))
+	if err != nil {
+		return
+	}
+	user, err := profiles.FindByID(userID)
+	if err != nil {
+		errors.HandleAppError(err)
+		waitForEnter()
+		return
+	}
+
+	fmt.Printf("Se eliminará la cuenta de %s (%s) con todas sus calificaciones y reseñas.\n", user.Name, user.Email)
+	if readInput("Escriba el email de la cuenta para confirmar: ") != user.Email {
+		fmt.Println("Eliminación cancelada")
+		waitForEnter()
+		return
+	}
+	removed, err := admin.DeleteUser(currentUser.ID, userID)
+	if err != nil {
+		errors.HandleAppError(err)
+	} else {
+		fmt.Printf(" Cuenta eliminada (%d calificaciones retiradas)\n", removed)
+	}
+	waitForEnter()
+}
+
+// Asignar los roles administrativos de un usuario (admin)
+func assignRoles() {
+	userID, err := strconv.Atoi(readInput("ID del usuario: "))
+	if err != nil {
+		return
+	}
+	fmt.Println("Roles:")
+	for i, r := range rbac.Roles {
+		fmt.Printf("%d. %s\n", i+1, rbac.RoleLabels[r])
+	}
+	var roles []string
+	for _, n := range strings.Split(readInput("Números de los roles separados por coma (vacío para quitar todos): "), ",") {
+		if n = strings.TrimSpace(n); n == "" {
+			continue
+		}
+		i, err := strconv.Atoi(n)
+		if err != nil || i < 1 || i > len(rbac.Roles) {
+			fmt.Println("Rol inválido")
+			waitForEnter()
+			return
+		}
+		roles = append(roles, rbac.Roles[i-1])
+	}
+	if err := admin.AssignRoles(currentUser.ID, userID, roles); err != nil {
+		errors.HandleAppError(err)
+	} else {
+		fmt.Println(" Roles actualizados")
 	}
+	waitForEnter()
+}
 
+// Cambiar el plan de un usuario (admin)
+func changeUserPlan() {
+	userID, err := strconv.Atoi(readInput("ID del usuario: "))
+	if err != nil {
+		return
+	}
+	plan := "Free"
+	if readInput("Plan (1. Free  2. Premium): ") == "2" {
+		plan = "Premium"
+	}
+	if err := admin.ChangePlan(currentUser.ID, userID, plan); err != nil {
+		errors.HandleAppError(err)
+	} else {
+		fmt.Println(" Plan actualizado")
+	}
 	waitForEnter()
 }
 
@@ -503,7 +2299,9 @@ func showAudiovisualManagement() {
 
 	fmt.Println("1. Listar Contenido")
 	fmt.Println("2. Agregar Contenido")
-	fmt.Println("3. Volver al Menú Principal")
+	fmt.Println("3. Gestionar Créditos")
+	fmt.Println("4. Géneros y Etiquetas")
+	fmt.Println("5. Volver al Menú Principal")
 	fmt.Println("────────────────────────────────────────────────────────────")
 
 	option := readInput("Seleccione una opción: ")
@@ -514,6 +2312,10 @@ func showAudiovisualManagement() {
 	case "2":
 		addAudiovisualContent()
 	case "3":
+		showCreditManagement(categories.KindAudiovisual)
+	case "4":
+		showGenreManagement(categories.KindAudiovisual)
+	case "5":
 		return
 	default:
 		if option != "" {
@@ -532,7 +2334,9 @@ func showAudioManagement() {
 
 	fmt.Println("1. Listar Contenido")
 	fmt.Println("2. Agregar Contenido")
-	fmt.Println("3. Volver al Menú Principal")
+	fmt.Println("3. Gestionar Créditos")
+	fmt.Println("4. Géneros y Etiquetas")
+	fmt.Println("5. Volver al Menú Principal")
 	fmt.Println("────────────────────────────────────────────────────────────")
 
 	option := readInput("Seleccione una opción: ")
@@ -543,6 +2347,10 @@ func showAudioManagement() {
 	case "2":
 		addAudioContent()
 	case "3":
+		showCreditManagement(categories.KindAudio)
+	case "4":
+		showGenreManagement(categories.KindAudio)
+	case "5":
 		return
 	default:
 		if option != "" {
@@ -552,6 +2360,125 @@ func showAudioManagement() {
 	}
 }
 
+// Gestionar las personas acreditadas en un contenido (admin)
+func showCreditManagement(kind string) {
+	contentID, err := strconv.Atoi(readInput("ID del contenido (0 para volver): "))
+	if err != nil || contentID <= 0 {
+		return
+	}
+	ref := categories.ContentRef{Kind: kind, ID: contentID}
+
+	for {
+		item, err := catalog.Get(ref)
+		if err != nil {
+			fmt.Println("Contenido no encontrado")
+			waitForEnter()
+			return
+		}
+
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Printf("Créditos: %s\n", item.Title)
+		fmt.Println("══════════════")
+		credits := people.CreditsFor(ref)
+		if len(credits) == 0 {
+			fmt.Println("Sin personas acreditadas")
+		}
+		for i, c := range credits {
+			if p, err := people.GetByID(c.PersonID); err == nil {
+				fmt.Printf("%d. %s - %s\n", i+1, p.Name, c.Role)
+			}
+		}
+		fmt.Println("────────────────────────────────────────────────────────────")
+		fmt.Println("1. Agregar Crédito")
+		fmt.Println("2. Quitar Crédito")
+		fmt.Println("3. Volver")
+
+		switch readInput("Seleccione una opción: ") {
+		case "1":
+			name := readInput("Nombre de la persona: ")
+			fmt.Printf("Roles: %s\n", strings.Join(people.Roles, ", "))
+			role := strings.ToLower(readInput("Rol: "))
+			if err := admin.AddCredit(currentUser.ID, ref, name, role); err != nil {
+				errors.HandleAppError(err)
+				waitForEnter()
+			}
+		case "2":
+			num, err := strconv.Atoi(readInput("Número del crédito: "))
+			if err != nil || num < 1 || num > len(credits) {
+				fmt.Println("Número inválido")
+				waitForEnter()
+				continue
+			}
+			c := credits[num-1]
+			if err := admin.RemoveCredit(currentUser.ID, c.PersonID, ref, c.Role); err != nil {
+				errors.HandleAppError(err)
+				waitForEnter()
+			}
+		case "3", "0", "":
+			return
+		}
+	}
+}
+
+// Gestionar los géneros y las etiquetas de un contenido (admin)
+func showGenreManagement(kind string) {
+	contentID, err := strconv.Atoi(readInput("ID del contenido (0 para volver): "))
+	if err != nil || contentID <= 0 {
+		return
+	}
+	ref := categories.ContentRef{Kind: kind, ID: contentID}
+
+	for {
+		item, err := catalog.Get(ref)
+		if err != nil {
+			fmt.Println("Contenido no encontrado")
+			waitForEnter()
+			return
+		}
+
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Printf("Géneros y Etiquetas: %s\n", item.Title)
+		fmt.Println("══════════════")
+		fmt.Printf("Género principal: %s\n", item.Genre)
+		fmt.Printf("Géneros: %s\n", strings.Join(item.Genres, ", "))
+		fmt.Printf("Etiquetas: %s\n", strings.Join(item.Tags, ", "))
+		fmt.Println("────────────────────────────────────────────────────────────")
+		fmt.Println("1. Cambiar Géneros")
+		fmt.Println("2. Agregar Etiqueta")
+		fmt.Println("3. Quitar Etiqueta")
+		fmt.Println("4. Volver")
+
+		switch readInput("Seleccione una opción: ") {
+		case "1":
+			primary := readInput("Género principal: ")
+			var secondary []string
+			for _, g := range strings.Split(readInput("Géneros secundarios (separados por coma): "), ",") {
+				if g = strings.TrimSpace(g); g != "" {
+					secondary = append(secondary, g)
+				}
+			}
+			if err := admin.SetContentGenres(currentUser.ID, ref, primary, secondary); err != nil {
+				errors.HandleAppError(err)
+				waitForEnter()
+			}
+		case "2":
+			if err := admin.AddContentTag(currentUser.ID, ref, readInput("Etiqueta: ")); err != nil {
+				errors.HandleAppError(err)
+				waitForEnter()
+			}
+		case "3":
+			if err := admin.RemoveContentTag(currentUser.ID, ref, readInput("Etiqueta: ")); err != nil {
+				errors.HandleAppError(err)
+				waitForEnter()
+			}
+		case "4", "0", "":
+			return
+		}
+	}
+}
+
 // Agregar contenido audiovisual
 func addAudiovisualContent() {
 	fmt.Print("\033[H\033[2J")
@@ -611,9 +2538,19 @@ func addAudiovisualContent() {
 
 	ageRating := ratings[ratingNum-1].Name
 
-	err = audiovisual.AddContent(title, contentType, "Acción", duration, ageRating, "Sinopsis", 2024, "Director")
+	genre := readGenre(contentType)
+	if genre == "0" {
+		return
+	}
+
+	director := readInput("Director: ")
+	if director == "0" {
+		return
+	}
+
+	err = admin.AddAudiovisualContent(currentUser.ID, title, contentType, genre, duration, ageRating, "Sinopsis", 2024, director)
 	if err != nil {
-		fmt.Println("Error al agregar contenido")
+		errors.HandleAppError(err)
 	} else {
 		fmt.Println(" Contenido agregado")
 	}
@@ -679,15 +2616,323 @@ func addAudioContent() {
 
 	ageRating := ratings[ratingNum-1].Name
 
-	err = audio.AddContent(title, contentType, "Música", duration, ageRating, "Artista", "Álbum", 1)
+	genre := readGenre(contentType)
+	if genre == "0" {
+		return
+	}
+
+	artist := readInput("Artista, narrador o conductor: ")
+	if artist == "0" {
+		return
+	}
+
+	err = admin.AddAudioContent(currentUser.ID, title, contentType, genre, duration, ageRating, artist, "Álbum", 1)
 	if err != nil {
-		fmt.Println("Error al agregar contenido")
+		errors.HandleAppError(err)
 	} else {
 		fmt.Println(" Contenido agregado")
 	}
 	waitForEnter()
 }
 
+// Pedir un género entre los que admite un tipo de contenido
+func readGenre(contentType string) string {
+	var names []string
+	for _, g := range genres.FilterByType(contentType) {
+		names = append(names, g.Name)
+	}
+	fmt.Printf("Géneros: %s\n", strings.Join(names, ", "))
+	return readInput("Género: ")
+}
+
+// Mostrar los intentos de inicio de sesión y las cuentas bloqueadas (admin)
+func showLoginActivity() {
+	for {
+		lockouts, err := admin.GetLockouts(currentUser.ID)
+		if err != nil {
+			errors.HandleAppError(err)
+			waitForEnter()
+			return
+		}
+		attempts, _ := admin.GetLoginAttempts(currentUser.ID)
+
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Println("Accesos y Bloqueos")
+		fmt.Println("══════════════════")
+		fmt.Println("Cuentas bloqueadas:")
+		if len(lockouts) == 0 {
+			fmt.Println("   Ninguna")
+		}
+		for _, l := range lockouts {
+			fmt.Printf("   %s - %d fallos, hasta las %s\n", l.Email, l.Failures, l.LockedUntil.Format("15:04"))
+		}
+		fmt.Println()
+		fmt.Println("Últimos intentos:")
+		if len(attempts) == 0 {
+			fmt.Println("   Sin intentos registrados")
+		}
+		for i, a := range attempts {
+			if i == 20 {
+				break
+			}
+			fmt.Printf("   %s  %-30s %-16s %s\n", a.At.Format("02/01 15:04:05"), a.Email, sourceLabel(a.Source), a.Result)
+		}
+		fmt.Println("────────────────────────────────────────────────────────────")
+		fmt.Println("1. Desbloquear Cuenta")
+		fmt.Println("2. Volver")
+
+		switch readInput("Seleccione una opción: ") {
+		case "1":
+			if err := admin.UnlockAccount(currentUser.ID, readInput("Email de la cuenta: ")); err != nil {
+				errors.HandleAppError(err)
+			} else {
+				fmt.Println(" Cuenta desbloqueada")
+			}
+			waitForEnter()
+		case "2", "0", "":
+			return
+		}
+	}
+}
+
+// Consultar, verificar y exportar la auditoría de operaciones administrativas (admin)
+func showAuditLog() {
+	for {
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Println("Auditoría")
+		fmt.Println("═════════")
+		fmt.Println("1. Consultar")
+		fmt.Println("2. Exportar a CSV")
+		fmt.Println("3. Verificar Integridad")
+		fmt.Println("4. Volver")
+
+		switch readInput("Seleccione una opción: ") {
+		case "1":
+			filter, ok := readAuditFilter()
+			if !ok {
+				continue
+			}
+			entries, err := admin.QueryAuditLog(currentUser.ID, filter)
+			if err != nil {
+				errors.HandleAppError(err)
+				waitForEnter()
+				continue
+			}
+			printAuditEntries(entries)
+		case "2":
+			filter, ok := readAuditFilter()
+			if !ok {
+				continue
+			}
+			defaultPath := fmt.Sprintf("auditoria-%s.csv", time.Now().Format("20060102-150405"))
+			path := readInput(fmt.Sprintf("Archivo [%s]: ", defaultPath))
+			if path == "" {
+				path = defaultPath
+			}
+			file, err := os.Create(path)
+			if err != nil {
+				fmt.Printf("️  No se pudo crear el archivo: %v\n", err)
+				waitForEnter()
+				continue
+			}
+			count, err := admin.ExportAuditLog(currentUser.ID, filter, file)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				errors.HandleAppError(err)
+			} else {
+				fmt.Printf(" %d entradas exportadas a %s\n", count, path)
+			}
+			waitForEnter()
+		case "3":
+			if count, err := admin.VerifyAuditLog(currentUser.ID); err != nil {
+				errors.HandleAppError(err)
+			} else {
+				fmt.Printf(" Cadena íntegra: %d entradas verificadas\n", count)
+			}
+			waitForEnter()
+		case "4", "0", "":
+			return
+		}
+	}
+}
+
+// Pedir los criterios para filtrar la auditoría; vacío no filtra
+func readAuditFilter() (audit.Filter, bool) {
+	var filter audit.Filter
+	if email := readInput("Email del actor (vacío para todos): "); email != "" {
+		user, err := profiles.FindByEmail(email)
+		if err != nil {
+			errors.HandleAppError(err)
+			waitForEnter()
+			return filter, false
+		}
+		filter.ActorID = user.ID
+	}
+	filter.Action = readInput("Acción o prefijo, por ejemplo usuarios. (vacío para todas): ")
+
+	for _, d := range []struct {
+		prompt string
+		value  *time.Time
+		days   int
+	}{
+		{"Desde (dd/mm/aaaa, vacío sin límite): ", &filter.From, 0},
+		{"Hasta (dd/mm/aaaa inclusive, vacío sin límite): ", &filter.To, 1},
+	} {
+		input := readInput(d.prompt)
+		if input == "" {
+			continue
+		}
+		date, err := time.ParseInLocation("02/01/2006", input, time.Local)
+		if err != nil {
+			fmt.Println("Fecha inválida")
+			waitForEnter()
+			return filter, false
+		}
+		*d.value = date.AddDate(0, 0, d.days)
+	}
+	return filter, true
+}
+
+// Mostrar entradas de la auditoría, la más reciente primero
+func printAuditEntries(entries []audit.Entry) {
+	fmt.Printf("\n%d entradas\n", len(entries))
+	for i := len(entries) - 1; i >= 0 && i >= len(entries)-30; i-- {
+		e := entries[i]
+		fmt.Println("────────────────────────────────────────────────────────────")
+		fmt.Printf("#%d  %s  %s  %s\n", e.Seq, e.At.Format("02/01/2006 15:04:05"), e.Actor, e.Action)
+		if e.Target != "" {
+			fmt.Printf("   Objetivo: %s\n", e.Target)
+		}
+		if e.Before != "" {
+			fmt.Printf("   Antes:    %s\n", e.Before)
+		}
+		if e.After != "" {
+			fmt.Printf("   Después:  %s\n", e.After)
+		}
+		fmt.Printf("   Resultado: %s\n", e.Result)
+	}
+	if len(entries) > 30 {
+		fmt.Println("────────────────────────────────────────────────────────────")
+		fmt.Println("Se muestran las 30 más recientes; exporte para ver todas")
+	}
+	waitForEnter()
+}
+
+// Gestionar la taxonomía de géneros (admin)
+func showGenreTaxonomy() {
+	for {
+		fmt.Print("\033[H\033[2J")
+		showHeader()
+		fmt.Println("Gestionar Géneros")
+		fmt.Println("═════════════════")
+		printGenreTree(0, 0)
+		fmt.Println("────────────────────────────────────────────────────────────")
+		fmt.Println("1. Agregar Género")
+		fmt.Println("2. Renombrar")
+		fmt.Println("3. Fusionar")
+		fmt.Println("4. Cambiar Género Padre")
+		fmt.Println("5. Cambiar Tipos de Contenido")
+		fmt.Println("6. Agregar Alias")
+		fmt.Println("7. Quitar Alias")
+		fmt.Println("8. Eliminar")
+		fmt.Println("9. Volver")
+
+		option := readInput("Seleccione una opción: ")
+		if option == "9" || option == "0" || option == "" {
+			return
+		}
+
+		var err error
+		switch option {
+		case "1":
+			name := readInput("Nombre: ")
+			kinds := readGenreKinds()
+			parentID, _ := strconv.Atoi(readInput("ID del género padre (0 si no tiene): "))
+			_, err = admin.AddGenre(currentUser.ID, name, kinds, parentID)
+		case "2":
+			id, _ := strconv.Atoi(readInput("ID del género: "))
+			var updated int
+			updated, err = admin.RenameGenre(currentUser.ID, id, readInput("Nuevo nombre: "))
+			if err == nil {
+				fmt.Printf("Género renombrado; %d contenidos actualizados. El nombre anterior queda como alias\n", updated)
+				waitForEnter()
+			}
+		case "3":
+			sourceID, _ := strconv.Atoi(readInput("ID del género a fusionar: "))
+			targetID, _ := strconv.Atoi(readInput("ID del género que lo absorbe: "))
+			var updated int
+			updated, err = admin.MergeGenre(currentUser.ID, sourceID, targetID)
+			if err == nil {
+				fmt.Printf("Géneros fusionados; %d contenidos actualizados\n", updated)
+				waitForEnter()
+			}
+		case "4":
+			id, _ := strconv.Atoi(readInput("ID del género: "))
+			parentID, _ := strconv.Atoi(readInput("ID del nuevo género padre (0 para dejarlo como raíz): "))
+			err = admin.SetGenreParent(currentUser.ID, id, parentID)
+		case "5":
+			id, _ := strconv.Atoi(readInput("ID del género: "))
+			err = admin.SetGenreKinds(currentUser.ID, id, readGenreKinds())
+		case "6":
+			id, _ := strconv.Atoi(readInput("ID del género: "))
+			err = admin.AddGenreAlias(currentUser.ID, id, readInput("Alias: "))
+		case "7":
+			id, _ := strconv.Atoi(readInput("ID del género: "))
+			err = admin.RemoveGenreAlias(currentUser.ID, id, readInput("Alias: "))
+		case "8":
+			id, _ := strconv.Atoi(readInput("ID del género: "))
+			err = admin.DeleteGenre(currentUser.ID, id)
+		default:
+			fmt.Println("Opción inválida")
+			waitForEnter()
+		}
+		if err != nil {
+			errors.HandleAppError(err)
+			waitForEnter()
+		}
+	}
+}
+
+// Mostrar los géneros como árbol, con sus tipos, alias y cuántos contenidos los usan
+func printGenreTree(parentID, depth int) {
+	for _, g := range genres.Children(parentID) {
+		audiovisualCount, audioCount, _ := admin.GetGenreUsage(currentUser.ID, g.ID)
+		fmt.Printf("%s[%d] %s (%s) - %d audiovisual, %d audio\n",
+			strings.Repeat("   ", depth), g.ID, g.Name, strings.Join(g.Kinds, ", "), audiovisualCount, audioCount)
+		if len(g.Aliases) > 0 {
+			fmt.Printf("%s     alias: %s\n", strings.Repeat("   ", depth), strings.Join(g.Aliases, ", "))
+		}
+		printGenreTree(g.ID, depth+1)
+	}
+}
+
+// Pedir los tipos de contenido que pueden usar un género
+func readGenreKinds() []string {
+	fmt.Println("Tipos: 1. Audiovisual  2. Audio  3. Ambos")
+	switch readInput("Tipo (1-3): ") {
+	case "1":
+		return []string{categories.KindAudiovisual}
+	case "2":
+		return []string{categories.KindAudio}
+	case "3":
+		return []string{categories.KindAudiovisual, categories.KindAudio}
+	}
+	return nil
+}
+
+// Formatear el rating promedio junto a la cantidad de votos
+func ratingSummary(ref categories.ContentRef, average float64) string {
+	count := ratings.Count(ref)
+	if count == 1 {
+		return fmt.Sprintf("%s (1 voto)", utils.FormatRating(average))
+	}
+	return fmt.Sprintf("%s (%d votos)", utils.FormatRating(average), count)
+}
+
 // Leer entrada del usuario
 func readInput(prompt string) string {
 	fmt.Print(prompt)
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"SDGEStreaming/internal/categories"
+	"SDGEStreaming/internal/recommend"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+)
+
+// Evaluación offline del recomendador con calificaciones sintéticas: genero gustos
+// por grupo de contenidos y evalúo con recommend.Evaluate. Las calificaciones reales
+// se evalúan desde la consola, en Calificaciones Sospechosas.
+
+// Genero usuarios con afinidad por algunos grupos de contenidos
+func generate(users, items, groups, perUser int, rng *rand.Rand) map[categories.ContentRef][]categories.UserRating {
+	itemGroup := make([]int, items)
+	quality := make([]float64, items)
+	for i := range itemGroup {
+		itemGroup[i] = rng.Intn(groups)
+		quality[i] = rng.NormFloat64() * 0.8
+	}
+
+	data := make(map[categories.ContentRef][]categories.UserRating)
+	for u := 1; u <= users; u++ {
+		affinity := make([]float64, groups)
+		for g := range affinity {
+			affinity[g] = rng.NormFloat64() * 2
+		}
+		bias := rng.NormFloat64() * 0.7
+
+		chosen := make(map[int]bool)
+		var order []int // orden de elección, para que la semilla reproduzca el resultado
+		for len(chosen) < perUser {
+			i := rng.Intn(items)
+			// Los usuarios tienden a ver lo que les gusta
+			if chosen[i] || affinity[itemGroup[i]] < 0 && rng.Float64() < 0.6 {
+				continue
+			}
+			chosen[i] = true
+			order = append(order, i)
+		}
+		for _, i := range order {
+			rating := 6 + bias + affinity[itemGroup[i]] + quality[i] + rng.NormFloat64()*0.8
+			rating = math.Round(math.Max(1, math.Min(10, rating))*2) / 2
+			kind := categories.KindAudiovisual
+			if i%3 == 0 {
+				kind = categories.KindAudio
+			}
+			ref := categories.ContentRef{Kind: kind, ID: i + 1}
+			data[ref] = append(data[ref], categories.UserRating{UserID: u, Rating: rating})
+		}
+	}
+	return data
+}
+
+// Muestro un error de parámetros y termino
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "sdgeeval: "+format+"\n", args...)
+	os.Exit(2)
+}
+
+func main() {
+	users := flag.Int("users", 500, "usuarios sintéticos")
+	items := flag.Int("items", 400, "contenidos sintéticos")
+	groups := flag.Int("groups", 8, "grupos de gustos")
+	perUser := flag.Int("per-user", 40, "calificaciones por usuario")
+	holdout := flag.Float64("holdout", 0.2, "fracción de calificaciones separadas para evaluar")
+	k := flag.Int("k", 10, "tamaño de la lista recomendada")
+	threshold := flag.Float64("threshold", 7, "calificación mínima para considerar que un contenido gustó")
+	seed := flag.Int64("seed", 1, "semilla aleatoria")
+	flag.Parse()
+
+	// Cada usuario califica contenidos distintos, así que no puede calificar más de los que hay
+	if *users <= 0 || *items <= 0 || *groups <= 0 {
+		fail("-users, -items y -groups deben ser mayores a 0")
+	}
+	if *perUser <= 0 || *perUser > *items {
+		fail("-per-user debe estar entre 1 y -items (%d), no %d", *items, *perUser)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	result, err := recommend.Evaluate(generate(*users, *items, *groups, *perUser, rng), *holdout, *k, *threshold, rng)
+	if err != nil {
+		fail("%v", err)
+	}
+	if result.Users == 0 {
+		fmt.Println("No hay usuarios con calificaciones relevantes separadas para evaluar")
+		return
+	}
+
+	fmt.Printf("Usuarios evaluados: %d (K=%d, umbral %.1f)\n", result.Users, result.K, result.Threshold)
+	fmt.Printf("%-28s precision@%d=%.3f  recall@%d=%.3f\n", "Filtrado colaborativo", result.K, result.Precision, result.K, result.Recall)
+	fmt.Printf("%-28s precision@%d=%.3f  recall@%d=%.3f\n", "Referencia por popularidad", result.K, result.PopularPrecision, result.K, result.PopularRecall)
+}
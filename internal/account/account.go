@@ -0,0 +1,181 @@
+package account
+
+import (
+    "fmt"
+    "strings"
+    "time"
+    "SDGEStreaming/internal/audio"
+    "SDGEStreaming/internal/audiovisual"
+    "SDGEStreaming/internal/auth"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/library"
+    "SDGEStreaming/internal/mailer"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/ratings"
+    "SDGEStreaming/internal/rbac"
+    "SDGEStreaming/internal/recovery"
+    "SDGEStreaming/internal/reviews"
+    "SDGEStreaming/internal/sessions"
+    "SDGEStreaming/internal/twofactor"
+    "SDGEStreaming/internal/verification"
+)
+
+// Plazo entre que un usuario pide eliminar su cuenta y que se elimina
+var DeletionGracePeriod = 7 * 24 * time.Hour
+
+// Variables globales para almacenamiento en memoria
+var (
+    deletions = make(map[int]time.Time) // usuario -> momento en que se elimina su cuenta
+)
+
+// Mismo error para cualquier operación que pide la contraseña actual
+var errWrongPassword = errors.NewAppError("ACCOUNT_001", "Contraseña actual incorrecta", "")
+
+// Verifico la contraseña actual de un usuario antes de un cambio sensible
+func checkPassword(userID int, password string) (*categories.User, error) {
+    user, err := profiles.FindByID(userID)
+    if err != nil {
+        return nil, err
+    }
+    if user.Password != password {
+        return nil, errWrongPassword
+    }
+    return user, nil
+}
+
+// Cambio el nombre de un usuario
+func UpdateName(userID int, name string) error {
+    user, err := profiles.FindByID(userID)
+    if err != nil {
+        return err
+    }
+    return profiles.UpdateUser(userID, name, user.AgeRating)
+}
+
+// Cambio la contraseña después de verificar la actual. Cierro las demás
+// sesiones del usuario, salvo la que hizo el cambio, y devuelvo cuántas cerré
+func ChangePassword(userID int, sessionID, current, newPassword string) (int, error) {
+    user, err := checkPassword(userID, current)
+    if err != nil {
+        return 0, err
+    }
+    if err := profiles.SetPassword(userID, newPassword); err != nil {
+        return 0, err
+    }
+    closed := sessions.RevokeOthers(userID, sessionID)
+    mailer.Send(user.Email, "Su contraseña fue cambiada",
+        "La contraseña de su cuenta de SDGEStreaming se cambió y se cerraron sus otras sesiones.\nSi no fue usted, restablézcala desde ¿Olvidaste tu contraseña?")
+    return closed, nil
+}
+
+// Pido cambiar el email después de verificar la contraseña actual; el cambio
+// se aplica al confirmar el código enviado a la dirección nueva. Aviso también
+// a la dirección actual, por si el pedido no lo hizo el dueño de la cuenta
+func RequestEmailChange(userID int, password, newEmail string) error {
+    user, err := checkPassword(userID, password)
+    if err != nil {
+        return err
+    }
+    if err := verification.RequestEmailChange(userID, newEmail); err != nil {
+        return err
+    }
+    mailer.Send(user.Email, "Cambio de email solicitado",
+        fmt.Sprintf("Se pidió cambiar el email de su cuenta de SDGEStreaming a %s.\nSi no fue usted, cambie su contraseña: el cambio no se aplica sin el código enviado a la dirección nueva.", strings.TrimSpace(newEmail)))
+    return nil
+}
+
+// Pido eliminar la cuenta. Se elimina al vencer el plazo salvo que el
+// usuario cancele antes; mientras tanto se cierran todas sus sesiones
+func RequestDeletion(userID int, password string) (time.Time, error) {
+    user, err := checkPassword(userID, password)
+    if err != nil {
+        return time.Time{}, err
+    }
+    if _, pending := deletions[userID]; pending {
+        return time.Time{}, errors.NewAppError("ACCOUNT_002", "La eliminación ya está programada", "")
+    }
+    if err := rbac.CheckRemoval(userID); err != nil {
+        return time.Time{}, err
+    }
+
+    at := time.Now().Add(DeletionGracePeriod)
+    deletions[userID] = at
+    sessions.RevokeAll(userID)
+    mailer.Send(user.Email, "Eliminación de cuenta programada",
+        fmt.Sprintf("Su cuenta de SDGEStreaming, sus calificaciones y sus reseñas se eliminarán el %s.\nPara cancelar, inicie sesión antes de esa fecha.", at.Format("02/01/2006 15:04")))
+    return at, nil
+}
+
+// Cancelo una eliminación programada
+func CancelDeletion(userID int) error {
+    if _, pending := deletions[userID]; !pending {
+        return errors.NewAppError("ACCOUNT_003", "No hay una eliminación programada", "")
+    }
+    delete(deletions, userID)
+    if user, err := profiles.FindByID(userID); err == nil {
+        mailer.Send(user.Email, "Eliminación de cuenta cancelada", "Canceló la eliminación de su cuenta de SDGEStreaming; sigue activa.")
+    }
+    return nil
+}
+
+// Obtengo cuándo se elimina la cuenta de un usuario, si lo pidió
+func DeletionScheduled(userID int) (time.Time, bool) {
+    at, pending := deletions[userID]
+    return at, pending
+}
+
+// Recalculo el promedio guardado en un contenido después de retirar calificaciones
+func refreshAverage(ref categories.ContentRef) error {
+    if ref.Kind == categories.KindAudio {
+        return audio.RefreshAverage(ref.ID)
+    }
+    return audiovisual.RefreshAverage(ref.ID)
+}
+
+// Elimino una cuenta junto con sus calificaciones, reseñas, votos y sesiones, y
+// recalculo el promedio de lo que había calificado. También olvido todo lo que
+// se guarda por usuario: verificación en dos pasos, códigos pendientes, lista,
+// historial e intentos de inicio de sesión. No se puede eliminar al último
+// superadministrador. Devuelvo cuántas calificaciones retiré
+func Delete(userID int) (int, error) {
+    user, err := profiles.FindByID(userID)
+    if err != nil {
+        return 0, err
+    }
+    if err := rbac.CheckRemoval(userID); err != nil {
+        return 0, err
+    }
+    rated := ratings.ByUser(userID)
+    removed := ratings.RemoveAllByUser(userID)
+    for _, rc := range rated {
+        refreshAverage(rc.Ref)
+    }
+    reviews.RemoveAllByUser(userID)
+    sessions.RevokeAll(userID)
+    twofactor.Remove(userID)
+    verification.Discard(userID)
+    recovery.DiscardTokens(userID)
+    library.RemoveAllByUser(userID)
+    auth.Forget(userID, user.Email)
+    delete(deletions, userID)
+    return removed, profiles.DeleteUser(userID)
+}
+
+// Elimino las cuentas cuyo plazo venció y devuelvo cuántas eliminé. Si una
+// ya no se puede eliminar, por ser el último superadministrador, la eliminación
+// queda cancelada
+func PurgeDue(now time.Time) int {
+    count := 0
+    for userID, at := range deletions {
+        if now.Before(at) {
+            continue
+        }
+        if _, err := Delete(userID); err != nil {
+            delete(deletions, userID)
+            continue
+        }
+        count++
+    }
+    return count
+}
@@ -0,0 +1,271 @@
+package account
+
+import (
+    "fmt"
+    "regexp"
+    "strings"
+    "testing"
+    "time"
+    "SDGEStreaming/internal/audiovisual"
+    "SDGEStreaming/internal/auth"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/library"
+    "SDGEStreaming/internal/mailer"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/recovery"
+    "SDGEStreaming/internal/reviews"
+    "SDGEStreaming/internal/totp"
+    "SDGEStreaming/internal/twofactor"
+    "SDGEStreaming/internal/verification"
+)
+
+// Obtengo el código de un error de la aplicación, o vacío si no es uno
+func errorCode(err error) string {
+    if appErr, ok := err.(*errors.AppError); ok {
+        return appErr.Code
+    }
+    return ""
+}
+
+// Bandeja que guarda en memoria los correos enviados durante una prueba
+type outbox struct {
+    messages []mailer.Message
+}
+
+func (o *outbox) Send(msg mailer.Message) error {
+    o.messages = append(o.messages, msg)
+    return nil
+}
+
+// Obtengo los correos enviados a una dirección
+func (o *outbox) to(email string) []mailer.Message {
+    var list []mailer.Message
+    for _, m := range o.messages {
+        if m.To == email {
+            list = append(list, m)
+        }
+    }
+    return list
+}
+
+// Cuentas registradas por las pruebas, para no repetir emails
+var testUsers = 0
+
+// Registro una cuenta verificada y devuelvo su ID
+func newUser(t *testing.T) int {
+    t.Helper()
+    testUsers++
+    user, err := profiles.AddUser("Usuario Prueba", 30, fmt.Sprintf("cuenta%d@prueba.com", testUsers), "clave123", "Free", "Adulto", nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    profiles.MarkEmailVerified(user.ID)
+    return user.ID
+}
+
+// Verifico que eliminar una cuenta, directamente o al vencer el plazo, retire sus
+// calificaciones del promedio guardado y elimine sus reseñas y sus votos
+func TestDeleteCascades(t *testing.T) {
+    mailer.SetMailer(mailer.FileOutbox{Dir: t.TempDir()})
+
+    tests := []struct {
+        name   string
+        delete func(userID int) error
+    }{
+        {"Delete", func(userID int) error {
+            _, err := Delete(userID)
+            return err
+        }},
+        {"PurgeDue", func(userID int) error {
+            if _, err := RequestDeletion(userID, "clave123"); err != nil {
+                return err
+            }
+            if purged := PurgeDue(time.Now().Add(DeletionGracePeriod + time.Minute)); purged != 1 {
+                return fmt.Errorf("PurgeDue eliminó %d cuentas, quiero 1", purged)
+            }
+            return nil
+        }},
+    }
+    for i, tt := range tests {
+        if err := audiovisual.AddContent(fmt.Sprintf("Contenido Cuenta %d", i), "Película", "Drama", 90, "Adulto", "", 2020, ""); err != nil {
+            t.Fatal(err)
+        }
+        list := audiovisual.ListAll()
+        contentID := list[len(list)-1].ID
+        ref := categories.ContentRef{Kind: categories.KindAudiovisual, ID: contentID}
+
+        leaving, staying := newUser(t), newUser(t)
+        audiovisual.RateContent(contentID, leaving, 10)
+        audiovisual.RateContent(contentID, staying, 4)
+        own, err := reviews.AddReview(ref, leaving, "Una reseña que se va con la cuenta")
+        if err != nil {
+            t.Fatal(err)
+        }
+        other, err := reviews.AddReview(ref, staying, "Una reseña que se queda")
+        if err != nil {
+            t.Fatal(err)
+        }
+        reviews.Vote(own.ID, staying, true)
+        reviews.Vote(other.ID, leaving, true)
+
+        if err := tt.delete(leaving); err != nil {
+            t.Fatalf("%s: %v", tt.name, err)
+        }
+
+        if content, _ := audiovisual.GetByID(contentID); content.AverageRating != 4 {
+            t.Errorf("%s: promedio guardado %g, quiero 4", tt.name, content.AverageRating)
+        }
+        if _, err := reviews.GetByID(own.ID); err == nil {
+            t.Errorf("%s: la reseña de la cuenta eliminada sigue existiendo", tt.name)
+        }
+        if kept, err := reviews.GetByID(other.ID); err != nil || len(kept.Votes) != 0 {
+            t.Errorf("%s: reseña ajena %+v (%v), quiero sin el voto de la cuenta eliminada", tt.name, kept, err)
+        }
+        if _, err := profiles.FindByID(leaving); err == nil {
+            t.Errorf("%s: la cuenta sigue existiendo", tt.name)
+        }
+    }
+}
+
+// Verifico que cambiar el email pida la contraseña actual antes de enviar el código
+func TestRequestEmailChangeRequiresPassword(t *testing.T) {
+    mailer.SetMailer(mailer.FileOutbox{Dir: t.TempDir()})
+    userID := newUser(t)
+
+    tests := []struct {
+        password string
+        email    string
+        code     string
+    }{
+        {"incorrecta", "nuevo@prueba.com", "ACCOUNT_001"},
+        {"", "nuevo@prueba.com", "ACCOUNT_001"},
+        {"clave123", "no-es-un-email", "AUTH_001"},
+        {"clave123", "user@demo.com", "AUTH_004"},
+        {"clave123", "nuevo@prueba.com", ""},
+    }
+    for _, tt := range tests {
+        err := RequestEmailChange(userID, tt.password, tt.email)
+        if errorCode(err) != tt.code || (tt.code == "") != (err == nil) {
+            t.Errorf("RequestEmailChange(%q, %q) = %v, quiero %q", tt.password, tt.email, err, tt.code)
+        }
+        email, purpose, pending := verification.Pending(userID)
+        if wantPending := tt.code == ""; pending != wantPending {
+            t.Errorf("RequestEmailChange(%q, %q): código pendiente = %v, quiero %v", tt.password, tt.email, pending, wantPending)
+        } else if pending && (email != tt.email || purpose != verification.PurposeEmailChange) {
+            t.Errorf("código pendiente para %s (%s), quiero %s", email, purpose, tt.email)
+        }
+    }
+}
+
+// Verifico que pedir el cambio de email avise también a la dirección actual
+func TestRequestEmailChangeNotifiesOldEmail(t *testing.T) {
+    sent := &outbox{}
+    mailer.SetMailer(sent)
+    userID := newUser(t)
+    user, _ := profiles.FindByID(userID)
+
+    if err := RequestEmailChange(userID, "clave123", "destino-nuevo@prueba.com"); err != nil {
+        t.Fatal(err)
+    }
+    notices := sent.to(user.Email)
+    if len(notices) != 1 || !strings.Contains(notices[0].Body, "destino-nuevo@prueba.com") {
+        t.Errorf("avisos a la dirección actual = %+v, quiero uno que nombre la dirección nueva", notices)
+    }
+    if codes := sent.to("destino-nuevo@prueba.com"); len(codes) != 1 {
+        t.Errorf("correos a la dirección nueva = %d, quiero 1 con el código", len(codes))
+    }
+
+    // Un pedido rechazado no avisa a nadie
+    sent.messages = nil
+    RequestEmailChange(userID, "incorrecta", "otro-destino@prueba.com")
+    if len(sent.messages) != 0 {
+        t.Errorf("un pedido con contraseña incorrecta envió %+v", sent.messages)
+    }
+}
+
+// Verifico que eliminar una cuenta borre cada dato que se guarda por usuario,
+// para que no quede nada asociado a su ID ni a su email
+func TestDeleteClearsUserState(t *testing.T) {
+    sent := &outbox{}
+    mailer.SetMailer(sent)
+    threshold := auth.LockoutThreshold
+    t.Cleanup(func() { auth.LockoutThreshold = threshold })
+    auth.LockoutThreshold = 1
+
+    userID := newUser(t)
+    user, _ := profiles.FindByID(userID)
+    ref := categories.ContentRef{Kind: categories.KindAudiovisual, ID: 1}
+
+    // Verificación en dos pasos
+    secret, _, err := twofactor.BeginEnrollment(userID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    code, _ := totp.Code(secret, time.Now())
+    if _, err := twofactor.ConfirmEnrollment(userID, code); err != nil {
+        t.Fatal(err)
+    }
+    // Código pendiente de cambio de email
+    if err := RequestEmailChange(userID, "clave123", "pendiente-borrado@prueba.com"); err != nil {
+        t.Fatal(err)
+    }
+    // Código para restablecer la contraseña
+    if err := recovery.RequestReset(user.Email); err != nil {
+        t.Fatal(err)
+    }
+    var resetCode string
+    for _, m := range sent.to(user.Email) {
+        if match := regexp.MustCompile(`Su código es (\S+) `).FindStringSubmatch(m.Body); match != nil {
+            resetCode = match[1]
+        }
+    }
+    if resetCode == "" {
+        t.Fatal("no se envió el código para restablecer la contraseña")
+    }
+    // Lista e historial
+    if err := library.AddToList(userID, ref); err != nil {
+        t.Fatal(err)
+    }
+    if err := library.RecordPlay(userID, ref); err != nil {
+        t.Fatal(err)
+    }
+    // Bloqueo e intentos de inicio de sesión
+    auth.Login(user.Email, "incorrecta", "prueba-borrado")
+
+    if _, err := Delete(userID); err != nil {
+        t.Fatal(err)
+    }
+
+    checks := []struct {
+        store string
+        left  bool
+    }{
+        {"verificación en dos pasos", twofactor.IsEnabled(userID)},
+        {"código de verificación pendiente", func() bool { _, _, pending := verification.Pending(userID); return pending }()},
+        {"código para restablecer la contraseña", errorCode(recovery.ResetPassword(resetCode, "clave456")) != "RESET_001"},
+        {"lista", len(library.List(userID)) > 0},
+        {"historial", len(library.History(userID)) > 0},
+        {"bloqueo", func() bool {
+            for _, l := range auth.Lockouts() {
+                if l.Email == strings.ToLower(user.Email) {
+                    return true
+                }
+            }
+            return false
+        }()},
+        {"intentos de inicio de sesión", func() bool {
+            for _, a := range auth.Attempts() {
+                if a.UserID == userID || a.Email == user.Email {
+                    return true
+                }
+            }
+            return false
+        }()},
+    }
+    for _, c := range checks {
+        if c.left {
+            t.Errorf("después de eliminar la cuenta quedó: %s", c.store)
+        }
+    }
+}
@@ -1,74 +1,695 @@
 package admin
 
 import (
+    "fmt"
+    "io"
+    "math/rand"
+    "strings"
+    "time"
+    "SDGEStreaming/internal/account"
+    "SDGEStreaming/internal/anomaly"
+    "SDGEStreaming/internal/audit"
+    "SDGEStreaming/internal/auth"
     "SDGEStreaming/internal/audio"
     "SDGEStreaming/internal/audiovisual"
     "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/contentclass"
     "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/genres"
+    "SDGEStreaming/internal/people"
     "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/ratings"
+    "SDGEStreaming/internal/rbac"
+    "SDGEStreaming/internal/recommend"
+    "SDGEStreaming/internal/recovery"
+    "SDGEStreaming/internal/reviews"
+    "SDGEStreaming/internal/sessions"
 )
 
-// Verifico si un usuario tiene permisos de administrador
-func IsAdmin(userID int) bool {
+// Verifico el permiso de una operación; los rechazos también quedan en la auditoría
+func authorize(adminUserID int, perm, action, target string) error {
+    if err := rbac.Require(adminUserID, perm); err != nil {
+        audit.Record(adminUserID, action, target, "", "", err)
+        return err
+    }
+    return nil
+}
+
+// Describo un usuario para la auditoría
+func userTarget(userID int) string {
+    if user, err := profiles.FindByID(userID); err == nil {
+        return fmt.Sprintf("usuario %d (%s)", userID, user.Email)
+    }
+    return fmt.Sprintf("usuario %d", userID)
+}
+
+// Obtengo el estado de un usuario que puede cambiar una operación
+func userState(userID int) string {
     user, err := profiles.FindByID(userID)
     if err != nil {
-        return false
+        return ""
+    }
+    return fmt.Sprintf("nombre=%s; plan=%s; clasificación=%s; roles=%s; deshabilitada=%t; debe restablecer=%t",
+        user.Name, user.Plan, user.AgeRating, strings.Join(user.Roles, ","), user.Disabled, user.MustResetPassword)
+}
+
+// Describo un contenido para la auditoría
+func refTarget(ref categories.ContentRef) string {
+    return fmt.Sprintf("%s %d", ref.Kind, ref.ID)
+}
+
+// Obtengo los géneros y etiquetas de un contenido
+func contentState(ref categories.ContentRef) string {
+    var genreList, tags []string
+    if ref.Kind == categories.KindAudio {
+        if c, err := audio.GetByID(ref.ID); err == nil {
+            genreList, tags = c.Genres, c.Tags
+        }
+    } else if c, err := audiovisual.GetByID(ref.ID); err == nil {
+        genreList, tags = c.Genres, c.Tags
     }
-    return user.IsAdmin
+    return fmt.Sprintf("géneros=%s; etiquetas=%s", strings.Join(genreList, ","), strings.Join(tags, ","))
 }
 
-// Obtengo todos los usuarios (solo administradores)
+// Describo un género para la auditoría
+func genreTarget(genreID int) string {
+    if genre, err := genres.GetByID(genreID); err == nil {
+        return fmt.Sprintf("género %d (%s)", genreID, genre.Name)
+    }
+    return fmt.Sprintf("género %d", genreID)
+}
+
+// Obtengo el estado de un género que puede cambiar una operación
+func genreState(genreID int) string {
+    genre, err := genres.GetByID(genreID)
+    if err != nil {
+        return ""
+    }
+    return fmt.Sprintf("nombre=%s; tipos=%s; padre=%d; sinónimos=%s",
+        genre.Name, strings.Join(genre.Kinds, ","), genre.ParentID, strings.Join(genre.Aliases, ","))
+}
+
+// Obtengo todos los usuarios (requiere ver usuarios)
 func GetAllUsers(adminUserID int) ([]categories.User, error) {
-    if !IsAdmin(adminUserID) {
-        return nil, errors.ErrPermissionDenied
+    if err := authorize(adminUserID, rbac.PermUsersView, "usuarios.listar", ""); err != nil {
+        return nil, err
     }
-    return profiles.GetAllUsers(), nil
+    users := profiles.GetAllUsers()
+    audit.Record(adminUserID, "usuarios.listar", "", "", fmt.Sprintf("%d usuarios", len(users)), nil)
+    return users, nil
 }
 
-// Obtengo todo el contenido audiovisual (solo administradores)
+// Obtengo todo el contenido audiovisual (requiere gestionar contenido)
 func GetAllAudiovisualContent(adminUserID int) ([]audiovisual.AudiovisualContent, error) {
-    if !IsAdmin(adminUserID) {
-        return nil, errors.ErrPermissionDenied
+    if err := authorize(adminUserID, rbac.PermContentManage, "contenido.listar", categories.KindAudiovisual); err != nil {
+        return nil, err
     }
-    return audiovisual.ListAll(), nil
+    contents := audiovisual.ListAll()
+    audit.Record(adminUserID, "contenido.listar", categories.KindAudiovisual, "", fmt.Sprintf("%d contenidos", len(contents)), nil)
+    return contents, nil
 }
 
-// Obtengo todo el contenido de audio (solo administradores)
+// Obtengo todo el contenido de audio (requiere gestionar contenido)
 func GetAllAudioContent(adminUserID int) ([]audio.AudioContent, error) {
-    if !IsAdmin(adminUserID) {
-        return nil, errors.ErrPermissionDenied
+    if err := authorize(adminUserID, rbac.PermContentManage, "contenido.listar", categories.KindAudio); err != nil {
+        return nil, err
     }
-    return audio.ListAll(), nil
+    contents := audio.ListAll()
+    audit.Record(adminUserID, "contenido.listar", categories.KindAudio, "", fmt.Sprintf("%d contenidos", len(contents)), nil)
+    return contents, nil
 }
 
-// Agrego contenido audiovisual (solo administradores)
+// Agrego contenido audiovisual (requiere gestionar contenido)
 func AddAudiovisualContent(adminUserID int, title, contentType, genre string, duration int, ageRating string, synopsis string, releaseYear int, director string) error {
-    if !IsAdmin(adminUserID) {
-        return errors.ErrPermissionDenied
+    target := categories.KindAudiovisual + ": " + title
+    if err := authorize(adminUserID, rbac.PermContentManage, "contenido.agregar", target); err != nil {
+        return err
     }
-    return audiovisual.AddContent(title, contentType, genre, duration, ageRating, synopsis, releaseYear, director)
+    err := audiovisual.AddContent(title, contentType, genre, duration, ageRating, synopsis, releaseYear, director)
+    audit.Record(adminUserID, "contenido.agregar", target, "",
+        fmt.Sprintf("tipo=%s; género=%s; duración=%d; clasificación=%s; año=%d; director=%s", contentType, genre, duration, ageRating, releaseYear, director), err)
+    if err != nil {
+        return err
+    }
+    // Acredito al director como persona
+    people.ImportExisting()
+    return nil
 }
 
-// Agrego contenido de audio (solo administradores)
+// Agrego contenido de audio (requiere gestionar contenido)
 func AddAudioContent(adminUserID int, title, contentType, genre string, duration int, ageRating string, artist string, album string, trackNumber int) error {
-    if !IsAdmin(adminUserID) {
-        return errors.ErrPermissionDenied
+    target := categories.KindAudio + ": " + title
+    if err := authorize(adminUserID, rbac.PermContentManage, "contenido.agregar", target); err != nil {
+        return err
+    }
+    err := audio.AddContent(title, contentType, genre, duration, ageRating, artist, album, trackNumber)
+    audit.Record(adminUserID, "contenido.agregar", target, "",
+        fmt.Sprintf("tipo=%s; género=%s; duración=%d; clasificación=%s; artista=%s; álbum=%s; pista=%d", contentType, genre, duration, ageRating, artist, album, trackNumber), err)
+    if err != nil {
+        return err
     }
-    return audio.AddContent(title, contentType, genre, duration, ageRating, artist, album, trackNumber)
+    // Acredito al artista, narrador o conductor como persona
+    people.ImportExisting()
+    return nil
 }
 
-// Obtengo calificaciones individuales para contenido audiovisual
+// Obtengo calificaciones individuales para contenido audiovisual (requiere auditar calificaciones)
 func GetAudiovisualIndividualRatings(adminUserID, contentID int) ([]categories.UserRating, error) {
-    if !IsAdmin(adminUserID) {
-        return nil, errors.ErrPermissionDenied
+    target := refTarget(categories.ContentRef{Kind: categories.KindAudiovisual, ID: contentID})
+    if err := authorize(adminUserID, rbac.PermRatingsAudit, "calificaciones.ver", target); err != nil {
+        return nil, err
     }
-    return audiovisual.GetIndividualRatings(contentID)
+    list, err := audiovisual.GetIndividualRatings(contentID)
+    audit.Record(adminUserID, "calificaciones.ver", target, "", fmt.Sprintf("%d calificaciones", len(list)), err)
+    return list, err
 }
 
-// Obtengo calificaciones individuales para contenido de audio
+// Obtengo calificaciones individuales para contenido de audio (requiere auditar calificaciones)
 func GetAudioIndividualRatings(adminUserID, contentID int) ([]categories.UserRating, error) {
-    if !IsAdmin(adminUserID) {
-        return nil, errors.ErrPermissionDenied
+    target := refTarget(categories.ContentRef{Kind: categories.KindAudio, ID: contentID})
+    if err := authorize(adminUserID, rbac.PermRatingsAudit, "calificaciones.ver", target); err != nil {
+        return nil, err
+    }
+    list, err := audio.GetIndividualRatings(contentID)
+    audit.Record(adminUserID, "calificaciones.ver", target, "", fmt.Sprintf("%d calificaciones", len(list)), err)
+    return list, err
+}
+
+// Obtengo las reseñas pendientes de moderación (requiere moderar reseñas)
+func GetModerationQueue(adminUserID int) ([]reviews.Review, error) {
+    if err := authorize(adminUserID, rbac.PermReviewsModerate, "resenas.listar", ""); err != nil {
+        return nil, err
+    }
+    queue := reviews.PendingQueue()
+    audit.Record(adminUserID, "resenas.listar", "", "", fmt.Sprintf("%d pendientes", len(queue)), nil)
+    return queue, nil
+}
+
+// Apruebo, oculto o elimino una reseña (requiere moderar reseñas)
+func ModerateReview(adminUserID, reviewID int, action, reason string) error {
+    target := fmt.Sprintf("reseña %d", reviewID)
+    if err := authorize(adminUserID, rbac.PermReviewsModerate, "resenas.moderar", target); err != nil {
+        return err
+    }
+    before := ""
+    if r, err := reviews.GetByID(reviewID); err == nil {
+        before = "estado=" + r.Status
+    }
+    err := reviews.Moderate(reviewID, adminUserID, action, reason)
+    audit.Record(adminUserID, "resenas.moderar", target, before, fmt.Sprintf("acción=%s; motivo=%s", action, reason), err)
+    return err
+}
+
+// Recalculo el promedio guardado en el contenido después de excluir o restaurar
+func refreshAverage(ref categories.ContentRef) error {
+    if ref.Kind == categories.KindAudio {
+        return audio.RefreshAverage(ref.ID)
+    }
+    return audiovisual.RefreshAverage(ref.ID)
+}
+
+// Busco calificaciones sospechosas y obtengo las alertas pendientes (requiere auditar calificaciones)
+func ScanRatings(adminUserID int) ([]anomaly.Flag, error) {
+    if err := authorize(adminUserID, rbac.PermRatingsAudit, "calificaciones.analizar", ""); err != nil {
+        return nil, err
+    }
+    anomaly.Scan(anomaly.DefaultConfig, time.Now())
+    pending := anomaly.Pending()
+    audit.Record(adminUserID, "calificaciones.analizar", "", "", fmt.Sprintf("%d alertas pendientes", len(pending)), nil)
+    return pending, nil
+}
+
+// Excluyo del promedio la calificación de una alerta o la descarto (requiere auditar calificaciones)
+func ResolveRatingFlag(adminUserID, flagID int, exclude bool, reason string) error {
+    target := fmt.Sprintf("alerta %d", flagID)
+    if err := authorize(adminUserID, rbac.PermRatingsAudit, "calificaciones.resolver", target); err != nil {
+        return err
+    }
+    flag, err := anomaly.Resolve(flagID, adminUserID, exclude, reason)
+    after := fmt.Sprintf("excluir=%t; motivo=%s", exclude, reason)
+    if err == nil {
+        after = fmt.Sprintf("%s; contenido=%s; usuario=%d", after, refTarget(flag.Ref), flag.UserID)
+        if exclude {
+            err = refreshAverage(flag.Ref)
+        }
+    }
+    audit.Record(adminUserID, "calificaciones.resolver", target, "", after, err)
+    return err
+}
+
+// Restauro una calificación excluida (requiere auditar calificaciones)
+func RestoreRating(adminUserID int, ref categories.ContentRef, userID int, reason string) error {
+    target := fmt.Sprintf("%s, usuario %d", refTarget(ref), userID)
+    if err := authorize(adminUserID, rbac.PermRatingsAudit, "calificaciones.restaurar", target); err != nil {
+        return err
     }
-    return audio.GetIndividualRatings(contentID)
-}
\ No newline at end of file
+    err := anomaly.Restore(ref, userID, adminUserID, reason)
+    if err == nil {
+        err = refreshAverage(ref)
+    }
+    audit.Record(adminUserID, "calificaciones.restaurar", target, "excluida", "motivo="+reason, err)
+    return err
+}
+
+// Obtengo la auditoría de calificaciones excluidas y restauradas (requiere auditar calificaciones)
+func GetExclusionAudit(adminUserID int) ([]anomaly.AuditEntry, error) {
+    if err := authorize(adminUserID, rbac.PermRatingsAudit, "calificaciones.historial", ""); err != nil {
+        return nil, err
+    }
+    list := anomaly.Audit()
+    audit.Record(adminUserID, "calificaciones.historial", "", "", fmt.Sprintf("%d registros", len(list)), nil)
+    return list, nil
+}
+
+// Evalúo el recomendador con las calificaciones actuales, separando una fracción
+// de las de cada usuario (requiere auditar calificaciones)
+func EvaluateRecommender(adminUserID int, holdout float64, k int, threshold float64) (recommend.Evaluation, error) {
+    if err := authorize(adminUserID, rbac.PermRatingsAudit, "recomendador.evaluar", ""); err != nil {
+        return recommend.Evaluation{}, err
+    }
+    // Semilla fija para que dos evaluaciones sobre los mismos datos coincidan
+    result, err := recommend.Evaluate(ratings.All(), holdout, k, threshold, rand.New(rand.NewSource(1)))
+    audit.Record(adminUserID, "recomendador.evaluar", "",
+        fmt.Sprintf("separado=%g; k=%d; umbral=%g", holdout, k, threshold),
+        fmt.Sprintf("%d usuarios evaluados", result.Users), err)
+    return result, err
+}
+
+// Acredito a una persona en un contenido, creándola si no existe (requiere gestionar contenido)
+func AddCredit(adminUserID int, ref categories.ContentRef, name, role string) error {
+    target := refTarget(ref)
+    if err := authorize(adminUserID, rbac.PermContentManage, "creditos.agregar", target); err != nil {
+        return err
+    }
+    person, err := people.FindOrCreate(name)
+    if err == nil {
+        err = people.AddCredit(person.ID, ref, role)
+    }
+    audit.Record(adminUserID, "creditos.agregar", target, "", fmt.Sprintf("persona=%s; rol=%s", name, role), err)
+    return err
+}
+
+// Quito el crédito de una persona en un contenido (requiere gestionar contenido)
+func RemoveCredit(adminUserID, personID int, ref categories.ContentRef, role string) error {
+    target := refTarget(ref)
+    if err := authorize(adminUserID, rbac.PermContentManage, "creditos.quitar", target); err != nil {
+        return err
+    }
+    before := fmt.Sprintf("persona=%d; rol=%s", personID, role)
+    if person, err := people.GetByID(personID); err == nil {
+        before = fmt.Sprintf("persona=%s; rol=%s", person.Name, role)
+    }
+    err := people.RemoveCredit(personID, ref, role)
+    audit.Record(adminUserID, "creditos.quitar", target, before, "", err)
+    return err
+}
+
+// Asigno el género principal y los secundarios de un contenido (requiere gestionar contenido)
+func SetContentGenres(adminUserID int, ref categories.ContentRef, primary string, secondary []string) error {
+    target := refTarget(ref)
+    if err := authorize(adminUserID, rbac.PermContentManage, "contenido.generos", target); err != nil {
+        return err
+    }
+    before := contentState(ref)
+    var err error
+    if ref.Kind == categories.KindAudio {
+        err = audio.SetGenres(ref.ID, primary, secondary)
+    } else {
+        err = audiovisual.SetGenres(ref.ID, primary, secondary)
+    }
+    audit.Record(adminUserID, "contenido.generos", target, before, contentState(ref), err)
+    return err
+}
+
+// Agrego una etiqueta libre a un contenido (requiere gestionar contenido)
+func AddContentTag(adminUserID int, ref categories.ContentRef, tag string) error {
+    target := refTarget(ref)
+    if err := authorize(adminUserID, rbac.PermContentManage, "contenido.etiquetar", target); err != nil {
+        return err
+    }
+    before := contentState(ref)
+    var err error
+    if ref.Kind == categories.KindAudio {
+        err = audio.AddTag(ref.ID, tag)
+    } else {
+        err = audiovisual.AddTag(ref.ID, tag)
+    }
+    audit.Record(adminUserID, "contenido.etiquetar", target, before, contentState(ref), err)
+    return err
+}
+
+// Quito una etiqueta de un contenido (requiere gestionar contenido)
+func RemoveContentTag(adminUserID int, ref categories.ContentRef, tag string) error {
+    target := refTarget(ref)
+    if err := authorize(adminUserID, rbac.PermContentManage, "contenido.desetiquetar", target); err != nil {
+        return err
+    }
+    before := contentState(ref)
+    var err error
+    if ref.Kind == categories.KindAudio {
+        err = audio.RemoveTag(ref.ID, tag)
+    } else {
+        err = audiovisual.RemoveTag(ref.ID, tag)
+    }
+    audit.Record(adminUserID, "contenido.desetiquetar", target, before, contentState(ref), err)
+    return err
+}
+
+// Cuento los contenidos de cada tipo que usan un género
+func genreUsage(name string) map[string]int {
+    return map[string]int{
+        categories.KindAudiovisual: audiovisual.CountByGenre(name),
+        categories.KindAudio:       audio.CountByGenre(name),
+    }
+}
+
+// Obtengo cuántos contenidos audiovisuales y de audio usan un género (requiere gestionar géneros)
+func GetGenreUsage(adminUserID, genreID int) (int, int, error) {
+    target := genreTarget(genreID)
+    if err := authorize(adminUserID, rbac.PermGenresManage, "generos.uso", target); err != nil {
+        return 0, 0, err
+    }
+    genre, err := genres.GetByID(genreID)
+    if err != nil {
+        audit.Record(adminUserID, "generos.uso", target, "", "", err)
+        return 0, 0, err
+    }
+    usage := genreUsage(genre.Name)
+    audit.Record(adminUserID, "generos.uso", target, "",
+        fmt.Sprintf("%d audiovisuales; %d de audio", usage[categories.KindAudiovisual], usage[categories.KindAudio]), nil)
+    return usage[categories.KindAudiovisual], usage[categories.KindAudio], nil
+}
+
+// Agrego un género a la taxonomía (requiere gestionar géneros)
+func AddGenre(adminUserID int, name string, kinds []string, parentID int) (*categories.Genre, error) {
+    target := "género " + name
+    if err := authorize(adminUserID, rbac.PermGenresManage, "generos.agregar", target); err != nil {
+        return nil, err
+    }
+    genre, err := genres.AddGenre(name, kinds, parentID)
+    after := ""
+    if err == nil {
+        target, after = genreTarget(genre.ID), genreState(genre.ID)
+    }
+    audit.Record(adminUserID, "generos.agregar", target, "", after, err)
+    return genre, err
+}
+
+// Renombro un género y actualizo todo el contenido que lo usa (requiere gestionar géneros)
+func RenameGenre(adminUserID, genreID int, newName string) (int, error) {
+    target := genreTarget(genreID)
+    if err := authorize(adminUserID, rbac.PermGenresManage, "generos.renombrar", target); err != nil {
+        return 0, err
+    }
+    before := genreState(genreID)
+    oldName, err := genres.Rename(genreID, newName)
+    if err != nil {
+        audit.Record(adminUserID, "generos.renombrar", target, before, "", err)
+        return 0, err
+    }
+    genre, _ := genres.GetByID(genreID)
+    updated := 0
+    if genre.Name != oldName {
+        updated = audiovisual.ReplaceGenre(oldName, genre.Name) + audio.ReplaceGenre(oldName, genre.Name)
+    }
+    audit.Record(adminUserID, "generos.renombrar", target, before, fmt.Sprintf("%s; %d contenidos actualizados", genreState(genreID), updated), nil)
+    return updated, nil
+}
+
+// Fusiono un género en otro y paso su contenido al destino (requiere gestionar géneros)
+func MergeGenre(adminUserID, sourceID, targetID int) (int, error) {
+    target := genreTarget(sourceID) + " en " + genreTarget(targetID)
+    if err := authorize(adminUserID, rbac.PermGenresManage, "generos.fusionar", target); err != nil {
+        return 0, err
+    }
+    before := genreState(sourceID)
+    sourceName, targetName, err := genres.Merge(sourceID, targetID)
+    if err != nil {
+        audit.Record(adminUserID, "generos.fusionar", target, before, "", err)
+        return 0, err
+    }
+    updated := audiovisual.ReplaceGenre(sourceName, targetName) + audio.ReplaceGenre(sourceName, targetName)
+    audit.Record(adminUserID, "generos.fusionar", target, before, fmt.Sprintf("%s; %d contenidos actualizados", genreState(targetID), updated), nil)
+    return updated, nil
+}
+
+// Cambio el género padre; 0 lo deja como género raíz (requiere gestionar géneros)
+func SetGenreParent(adminUserID, genreID, parentID int) error {
+    target := genreTarget(genreID)
+    if err := authorize(adminUserID, rbac.PermGenresManage, "generos.padre", target); err != nil {
+        return err
+    }
+    before := genreState(genreID)
+    err := genres.SetParent(genreID, parentID)
+    audit.Record(adminUserID, "generos.padre", target, before, genreState(genreID), err)
+    return err
+}
+
+// Cambio los tipos de contenido de un género; no se puede quitar un tipo que
+// todavía tiene contenido con ese género (requiere gestionar géneros)
+func SetGenreKinds(adminUserID, genreID int, kinds []string) error {
+    target := genreTarget(genreID)
+    if err := authorize(adminUserID, rbac.PermGenresManage, "generos.tipos", target); err != nil {
+        return err
+    }
+    before := genreState(genreID)
+    err := setGenreKinds(genreID, kinds)
+    audit.Record(adminUserID, "generos.tipos", target, before, genreState(genreID), err)
+    return err
+}
+
+// Cambio los tipos de un género si ningún contenido usa los que se quitan
+func setGenreKinds(genreID int, kinds []string) error {
+    genre, err := genres.GetByID(genreID)
+    if err != nil {
+        return err
+    }
+    for kind, count := range genreUsage(genre.Name) {
+        if count == 0 {
+            continue
+        }
+        kept := false
+        for _, k := range kinds {
+            kept = kept || k == kind
+        }
+        if !kept {
+            return errors.NewAppError("GENRE_004", "El género está en uso", fmt.Sprintf("%d contenidos de tipo %s", count, kind))
+        }
+    }
+    return genres.SetKinds(genreID, kinds)
+}
+
+// Agrego un sinónimo a un género (requiere gestionar géneros)
+func AddGenreAlias(adminUserID, genreID int, alias string) error {
+    target := genreTarget(genreID)
+    if err := authorize(adminUserID, rbac.PermGenresManage, "generos.sinonimo", target); err != nil {
+        return err
+    }
+    before := genreState(genreID)
+    err := genres.AddAlias(genreID, alias)
+    audit.Record(adminUserID, "generos.sinonimo", target, before, genreState(genreID), err)
+    return err
+}
+
+// Quito un sinónimo de un género (requiere gestionar géneros)
+func RemoveGenreAlias(adminUserID, genreID int, alias string) error {
+    target := genreTarget(genreID)
+    if err := authorize(adminUserID, rbac.PermGenresManage, "generos.quitarsinonimo", target); err != nil {
+        return err
+    }
+    before := genreState(genreID)
+    err := genres.RemoveAlias(genreID, alias)
+    audit.Record(adminUserID, "generos.quitarsinonimo", target, before, genreState(genreID), err)
+    return err
+}
+
+// Elimino un género que ningún contenido usa (requiere gestionar géneros)
+func DeleteGenre(adminUserID, genreID int) error {
+    target := genreTarget(genreID)
+    if err := authorize(adminUserID, rbac.PermGenresManage, "generos.eliminar", target); err != nil {
+        return err
+    }
+    before := genreState(genreID)
+    err := deleteGenre(genreID)
+    audit.Record(adminUserID, "generos.eliminar", target, before, "", err)
+    return err
+}
+
+// Elimino un género si ningún contenido lo usa
+func deleteGenre(genreID int) error {
+    genre, err := genres.GetByID(genreID)
+    if err != nil {
+        return err
+    }
+    usage := genreUsage(genre.Name)
+    if total := usage[categories.KindAudiovisual] + usage[categories.KindAudio]; total > 0 {
+        return errors.NewAppError("GENRE_004", "El género está en uso", fmt.Sprintf("%d contenidos", total))
+    }
+    return genres.Delete(genreID)
+}
+
+// Obtengo el historial de intentos de inicio de sesión (requiere gestionar accesos)
+func GetLoginAttempts(adminUserID int) ([]auth.Attempt, error) {
+    if err := authorize(adminUserID, rbac.PermAccessManage, "accesos.historial", ""); err != nil {
+        return nil, err
+    }
+    list := auth.Attempts()
+    audit.Record(adminUserID, "accesos.historial", "", "", fmt.Sprintf("%d intentos", len(list)), nil)
+    return list, nil
+}
+
+// Obtengo las cuentas bloqueadas por intentos fallidos (requiere gestionar accesos)
+func GetLockouts(adminUserID int) ([]auth.Lockout, error) {
+    if err := authorize(adminUserID, rbac.PermAccessManage, "accesos.bloqueos", ""); err != nil {
+        return nil, err
+    }
+    list := auth.Lockouts()
+    audit.Record(adminUserID, "accesos.bloqueos", "", "", fmt.Sprintf("%d cuentas bloqueadas", len(list)), nil)
+    return list, nil
+}
+
+// Desbloqueo una cuenta bloqueada por intentos fallidos (requiere gestionar accesos)
+func UnlockAccount(adminUserID int, email string) error {
+    if err := authorize(adminUserID, rbac.PermAccessManage, "accesos.desbloquear", email); err != nil {
+        return err
+    }
+    err := auth.Unlock(email)
+    audit.Record(adminUserID, "accesos.desbloquear", email, "bloqueada", "desbloqueada", err)
+    return err
+}
+
+// Asigno los roles administrativos de un usuario (requiere asignar roles)
+func AssignRoles(adminUserID, userID int, roles []string) error {
+    target := userTarget(userID)
+    if err := authorize(adminUserID, rbac.PermRolesAssign, "usuarios.roles", target); err != nil {
+        return err
+    }
+    before := userState(userID)
+    err := rbac.AssignRoles(userID, roles)
+    audit.Record(adminUserID, "usuarios.roles", target, before, userState(userID), err)
+    return err
+}
+
+// Cambio el plan de un usuario (requiere gestionar planes)
+func ChangePlan(adminUserID, userID int, plan string) error {
+    if err := requireOver(adminUserID, userID, rbac.PermPlansManage, "usuarios.plan"); err != nil {
+        return err
+    }
+    target, before := userTarget(userID), userState(userID)
+    err := profiles.SetPlan(userID, plan)
+    audit.Record(adminUserID, "usuarios.plan", target, before, userState(userID), err)
+    return err
+}
+
+// Verifico que un administrador pueda actuar sobre una cuenta: además del
+// permiso pedido, solo quien asigna roles puede tocar cuentas con roles
+func requireOver(adminUserID, userID int, perm, action string) error {
+    if err := authorize(adminUserID, perm, action, userTarget(userID)); err != nil {
+        return err
+    }
+    if rbac.IsStaff(userID) && adminUserID != userID {
+        return authorize(adminUserID, rbac.PermRolesAssign, action, userTarget(userID))
+    }
+    return nil
+}
+
+// Edito el nombre y la clasificación por edad de un usuario (requiere gestionar usuarios)
+func UpdateUser(adminUserID, userID int, name, ageRating string) error {
+    if err := requireOver(adminUserID, userID, rbac.PermUsersManage, "usuarios.editar"); err != nil {
+        return err
+    }
+    target, before := userTarget(userID), userState(userID)
+    _, err := contentclass.GetRatingByName(ageRating)
+    if err == nil {
+        err = profiles.UpdateUser(userID, name, ageRating)
+    }
+    audit.Record(adminUserID, "usuarios.editar", target, before, userState(userID), err)
+    return err
+}
+
+// Deshabilito o rehabilito una cuenta (requiere gestionar usuarios). Una cuenta
+// deshabilitada no puede iniciar sesión y se cierran sus sesiones abiertas
+func SetUserDisabled(adminUserID, userID int, disabled bool) error {
+    action := "usuarios.rehabilitar"
+    if disabled {
+        action = "usuarios.deshabilitar"
+    }
+    if err := requireOver(adminUserID, userID, rbac.PermUsersManage, action); err != nil {
+        return err
+    }
+    target, before := userTarget(userID), userState(userID)
+    err := setUserDisabled(userID, disabled)
+    audit.Record(adminUserID, action, target, before, userState(userID), err)
+    return err
+}
+
+// Cambio el estado de una cuenta cuidando que quede algún superadministrador
+func setUserDisabled(userID int, disabled bool) error {
+    if disabled {
+        if err := rbac.CheckRemoval(userID); err != nil {
+            return err
+        }
+    }
+    if err := profiles.SetDisabled(userID, disabled); err != nil {
+        return err
+    }
+    if disabled {
+        sessions.RevokeAll(userID)
+    }
+    return nil
+}
+
+// Obligo a un usuario a restablecer su contraseña (requiere gestionar usuarios)
+func ForcePasswordReset(adminUserID, userID int) error {
+    if err := requireOver(adminUserID, userID, rbac.PermUsersManage, "usuarios.restablecer"); err != nil {
+        return err
+    }
+    target, before := userTarget(userID), userState(userID)
+    err := recovery.ForceReset(userID)
+    audit.Record(adminUserID, "usuarios.restablecer", target, before, userState(userID), err)
+    return err
+}
+
+// Elimino una cuenta junto con sus calificaciones (requiere eliminar usuarios).
+// Devuelvo cuántas calificaciones se retiraron
+func DeleteUser(adminUserID, userID int) (int, error) {
+    if err := requireOver(adminUserID, userID, rbac.PermUsersDelete, "usuarios.eliminar"); err != nil {
+        return 0, err
+    }
+    target, before := userTarget(userID), userState(userID)
+    removed, err := account.Delete(userID)
+    audit.Record(adminUserID, "usuarios.eliminar", target, before, fmt.Sprintf("%d calificaciones retiradas", removed), err)
+    return removed, err
+}
+
+// Consulto la auditoría de operaciones administrativas (requiere ver la auditoría)
+func QueryAuditLog(adminUserID int, filter audit.Filter) ([]audit.Entry, error) {
+    if err := authorize(adminUserID, rbac.PermAuditView, "auditoria.consultar", ""); err != nil {
+        return nil, err
+    }
+    list := audit.Query(filter)
+    audit.Record(adminUserID, "auditoria.consultar", "", "", fmt.Sprintf("%d entradas", len(list)), nil)
+    return list, nil
+}
+
+// Verifico que nadie haya alterado la auditoría (requiere ver la auditoría).
+// Devuelvo la cantidad de entradas verificadas
+func VerifyAuditLog(adminUserID int) (int, error) {
+    if err := authorize(adminUserID, rbac.PermAuditView, "auditoria.verificar", ""); err != nil {
+        return 0, err
+    }
+    count, err := audit.Verify()
+    audit.Record(adminUserID, "auditoria.verificar", "", "", fmt.Sprintf("%d entradas verificadas", count), err)
+    return count, err
+}
+
+// Exporto en CSV las entradas de la auditoría que cumplen un filtro (requiere ver la auditoría)
+func ExportAuditLog(adminUserID int, filter audit.Filter, w io.Writer) (int, error) {
+    if err := authorize(adminUserID, rbac.PermAuditView, "auditoria.exportar", ""); err != nil {
+        return 0, err
+    }
+    list := audit.Query(filter)
+    err := audit.Export(w, list)
+    audit.Record(adminUserID, "auditoria.exportar", "", "", fmt.Sprintf("%d entradas", len(list)), err)
+    return len(list), err
+}
@@ -0,0 +1,111 @@
+package admin
+
+import (
+    "fmt"
+    "testing"
+    "SDGEStreaming/internal/audiovisual"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/reviews"
+)
+
+// Cuentas registradas por las pruebas, para no repetir emails
+var testUsers = 0
+
+// Registro una cuenta con los roles indicados y devuelvo su ID
+func newUser(t *testing.T, roles ...string) int {
+    t.Helper()
+    testUsers++
+    user, err := profiles.AddUser("Usuario Prueba", 30, fmt.Sprintf("admin%d@prueba.com", testUsers), "clave123", "Free", "Adulto", roles)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return user.ID
+}
+
+// Verifico que cambiar el plan de una cuenta con roles requiera poder asignar roles
+func TestChangePlanRequiresRolesOverStaff(t *testing.T) {
+    superadmin := newUser(t, categories.RoleSuperAdmin)
+    billing := newUser(t, categories.RoleBilling)
+    support := newUser(t, categories.RoleSupport)
+    customer := newUser(t)
+
+    tests := []struct {
+        name    string
+        actor   int
+        target  int
+        allowed bool
+    }{
+        {"facturación sobre un cliente", billing, customer, true},
+        {"facturación sobre sí mismo", billing, billing, true},
+        {"facturación sobre un superadministrador", billing, superadmin, false},
+        {"facturación sobre soporte", billing, support, false},
+        {"soporte sin permiso de planes", support, customer, false},
+        {"superadministrador sobre facturación", superadmin, billing, true},
+    }
+    for _, tt := range tests {
+        before, _ := profiles.FindByID(tt.target)
+        plan := "Premium"
+        if before.Plan == "Premium" {
+            plan = "Free"
+        }
+        err := ChangePlan(tt.actor, tt.target, plan)
+        if tt.allowed && err != nil {
+            t.Errorf("%s: %v", tt.name, err)
+            continue
+        }
+        if !tt.allowed {
+            if err != errors.ErrPermissionDenied {
+                t.Errorf("%s: error %v, quiero %v", tt.name, err, errors.ErrPermissionDenied)
+            }
+            if after, _ := profiles.FindByID(tt.target); after.Plan != before.Plan {
+                t.Errorf("%s: el plan cambió a %s", tt.name, after.Plan)
+            }
+        }
+    }
+}
+
+// Verifico que eliminar una cuenta retire sus calificaciones del promedio
+// guardado y elimine sus reseñas y sus votos
+func TestDeleteUserCascades(t *testing.T) {
+    superadmin := newUser(t, categories.RoleSuperAdmin)
+    if err := audiovisual.AddContent("Contenido Eliminar Cuenta", "Película", "Drama", 90, "Adulto", "", 2020, ""); err != nil {
+        t.Fatal(err)
+    }
+    list := audiovisual.ListAll()
+    contentID := list[len(list)-1].ID
+    ref := categories.ContentRef{Kind: categories.KindAudiovisual, ID: contentID}
+
+    leaving, staying := newUser(t), newUser(t)
+    profiles.MarkEmailVerified(leaving)
+    profiles.MarkEmailVerified(staying)
+    audiovisual.RateContent(contentID, leaving, 10)
+    audiovisual.RateContent(contentID, staying, 4)
+    own, err := reviews.AddReview(ref, leaving, "Una reseña que se va con la cuenta")
+    if err != nil {
+        t.Fatal(err)
+    }
+    other, err := reviews.AddReview(ref, staying, "Una reseña que se queda")
+    if err != nil {
+        t.Fatal(err)
+    }
+    reviews.Vote(own.ID, staying, true)
+    reviews.Vote(other.ID, leaving, true)
+
+    if removed, err := DeleteUser(superadmin, leaving); err != nil || removed != 1 {
+        t.Fatalf("DeleteUser = %d, %v; quiero 1 calificación retirada", removed, err)
+    }
+    if content, _ := audiovisual.GetByID(contentID); content.AverageRating != 4 {
+        t.Errorf("promedio guardado %g, quiero 4", content.AverageRating)
+    }
+    if _, err := reviews.GetByID(own.ID); err == nil {
+        t.Error("la reseña de la cuenta eliminada sigue existiendo")
+    }
+    if kept, err := reviews.GetByID(other.ID); err != nil || len(kept.Votes) != 0 {
+        t.Errorf("reseña ajena %+v (%v), quiero sin el voto de la cuenta eliminada", kept, err)
+    }
+    if _, err := profiles.FindByID(leaving); err == nil {
+        t.Error("la cuenta sigue existiendo")
+    }
+}
@@ -0,0 +1,388 @@
+package anomaly
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "strings"
+    "time"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/ratings"
+)
+
+// Reglas que pueden marcar una calificación como sospechosa
+const (
+    RuleBurst     = "ráfaga de cuentas nuevas"
+    RuleIdentical = "calificaciones idénticas"
+    RuleSwing     = "cambio brusco de promedio"
+)
+
+// Estados de una alerta
+const (
+    StatusPending   = "pendiente"
+    StatusExcluded  = "excluida"
+    StatusDismissed = "descartada"
+)
+
+// Acciones que quedan en la auditoría
+const (
+    ActionExclude = "excluir"
+    ActionRestore = "restaurar"
+    ActionDismiss = "descartar"
+)
+
+// Umbrales de las reglas de detección
+type Config struct {
+    // Una ráfaga son BurstSize calificaciones de cuentas nuevas a un mismo
+    // contenido dentro de BurstWindow; una cuenta es nueva durante NewAccountAge
+    BurstWindow   time.Duration
+    BurstSize     int
+    NewAccountAge time.Duration
+    // Cantidad mínima de calificaciones con el mismo valor para sospechar de un usuario
+    IdenticalMin int
+    // Un contenido con al menos SwingMinBase calificaciones previas cuyo promedio
+    // se mueve SwingThreshold puntos dentro de SwingWindow
+    SwingWindow    time.Duration
+    SwingThreshold float64
+    SwingMinBase   int
+}
+
+// Configuración por defecto de la detección
+var DefaultConfig = Config{
+    BurstWindow:    time.Hour,
+    BurstSize:      5,
+    NewAccountAge:  7 * 24 * time.Hour,
+    IdenticalMin:   5,
+    SwingWindow:    24 * time.Hour,
+    SwingThreshold: 1.5,
+    SwingMinBase:   5,
+}
+
+// Calificación marcada como sospechosa por una regla
+type Flag struct {
+    ID         int
+    Ref        categories.ContentRef
+    UserID     int
+    Rating     float64
+    Rule       string
+    Detail     string
+    DetectedAt time.Time
+    Status     string
+    ReviewedBy int
+    ReviewedAt time.Time
+    Reason     string
+}
+
+// Registro de una decisión sobre una calificación
+type AuditEntry struct {
+    Ref     categories.ContentRef
+    UserID  int
+    Rating  float64
+    Action  string
+    Rule    string // vacío al restaurar
+    Reason  string
+    AdminID int
+    At      time.Time
+}
+
+// Clave de una alerta: una regla marca una vez cada versión de una
+// calificación, así una alerta descartada puede volver si el usuario la cambia
+type flagKey struct {
+    ref       categories.ContentRef
+    userID    int
+    rule      string
+    updatedAt int64 // momento de la última modificación, en nanosegundos
+}
+
+// Variables globales para almacenamiento en memoria
+var (
+    flags  = make(map[int]*Flag)
+    byKey  = make(map[flagKey]int)
+    nextID = 1
+    audit  []AuditEntry
+)
+
+// Calificación vigente de un usuario a un contenido
+type rated struct {
+    ref categories.ContentRef
+    categories.UserRating
+}
+
+// Reviso las calificaciones vigentes con todas las reglas y devuelvo las alertas nuevas
+func Scan(cfg Config, now time.Time) []Flag {
+    var all []rated
+    for ref, list := range ratings.All() {
+        for _, r := range list {
+            all = append(all, rated{ref: ref, UserRating: r})
+        }
+    }
+    // Ordeno para que las alertas se generen siempre en el mismo orden
+    sort.Slice(all, func(i, j int) bool {
+        if !all[i].UpdatedAt.Equal(all[j].UpdatedAt) {
+            return all[i].UpdatedAt.Before(all[j].UpdatedAt)
+        }
+        if all[i].ref != all[j].ref {
+            return all[i].ref.Kind < all[j].ref.Kind || (all[i].ref.Kind == all[j].ref.Kind && all[i].ref.ID < all[j].ref.ID)
+        }
+        return all[i].UserID < all[j].UserID
+    })
+
+    var created []Flag
+    created = append(created, detectBursts(all, cfg)...)
+    created = append(created, detectIdentical(all, cfg)...)
+    created = append(created, detectSwings(all, cfg, now)...)
+    return created
+}
+
+// Registro una alerta salvo que la misma regla ya haya marcado esa versión de la calificación
+func raise(r rated, rule, detail string) (Flag, bool) {
+    key := flagKey{r.ref, r.UserID, rule, r.UpdatedAt.UnixNano()}
+    if _, exists := byKey[key]; exists {
+        return Flag{}, false
+    }
+    f := &Flag{
+        ID:         nextID,
+        Ref:        r.ref,
+        UserID:     r.UserID,
+        Rating:     r.Rating,
+        Rule:       rule,
+        Detail:     detail,
+        DetectedAt: time.Now(),
+        Status:     StatusPending,
+    }
+    flags[nextID] = f
+    byKey[key] = nextID
+    nextID++
+    return *f, true
+}
+
+// Detecto muchas calificaciones a un contenido desde cuentas recién creadas en poco tiempo
+func detectBursts(all []rated, cfg Config) []Flag {
+    fromNew := make(map[categories.ContentRef][]rated)
+    for _, r := range all {
+        user, err := profiles.FindByID(r.UserID)
+        if err != nil {
+            continue
+        }
+        if r.UpdatedAt.Sub(user.CreatedAt) <= cfg.NewAccountAge {
+            fromNew[r.ref] = append(fromNew[r.ref], r)
+        }
+    }
+
+    var created []Flag
+    for _, list := range fromNew {
+        // Ventana deslizante sobre las calificaciones ya ordenadas por fecha; cada
+        // calificación queda con la ráfaga más grande de la que forma parte
+        burst := make([]int, len(list))
+        start := 0
+        for end := range list {
+            for list[end].UpdatedAt.Sub(list[start].UpdatedAt) > cfg.BurstWindow {
+                start++
+            }
+            size := end - start + 1
+            if size < cfg.BurstSize {
+                continue
+            }
+            for i := start; i <= end; i++ {
+                burst[i] = max(burst[i], size)
+            }
+        }
+
+        for i, r := range list {
+            if burst[i] == 0 {
+                continue
+            }
+            detail := fmt.Sprintf("%d cuentas nuevas calificaron en menos de %.0f minutos", burst[i], cfg.BurstWindow.Minutes())
+            if f, ok := raise(r, RuleBurst, detail); ok {
+                created = append(created, f)
+            }
+        }
+    }
+    return created
+}
+
+// Detecto usuarios que dan exactamente la misma calificación a todo
+func detectIdentical(all []rated, cfg Config) []Flag {
+    byUser := make(map[int][]rated)
+    for _, r := range all {
+        byUser[r.UserID] = append(byUser[r.UserID], r)
+    }
+
+    var created []Flag
+    for _, list := range byUser {
+        if len(list) < cfg.IdenticalMin {
+            continue
+        }
+        same := true
+        for _, r := range list[1:] {
+            if r.Rating != list[0].Rating {
+                same = false
+                break
+            }
+        }
+        if !same {
+            continue
+        }
+        detail := fmt.Sprintf("calificó %d contenidos con %.1f", len(list), list[0].Rating)
+        for _, r := range list {
+            if f, ok := raise(r, RuleIdentical, detail); ok {
+                created = append(created, f)
+            }
+        }
+    }
+    return created
+}
+
+// Detecto contenidos cuyo promedio establecido cambió de golpe y marco las
+// calificaciones recientes que lo empujaron
+func detectSwings(all []rated, cfg Config, now time.Time) []Flag {
+    type split struct {
+        before, recent []rated
+    }
+    perRef := make(map[categories.ContentRef]*split)
+    since := now.Add(-cfg.SwingWindow)
+    for _, r := range all {
+        s := perRef[r.ref]
+        if s == nil {
+            s = &split{}
+            perRef[r.ref] = s
+        }
+        if r.UpdatedAt.Before(since) {
+            s.before = append(s.before, r)
+        } else {
+            s.recent = append(s.recent, r)
+        }
+    }
+
+    var created []Flag
+    for _, s := range perRef {
+        if len(s.before) < cfg.SwingMinBase || len(s.recent) == 0 {
+            continue
+        }
+        var sumBefore, sumRecent float64
+        for _, r := range s.before {
+            sumBefore += r.Rating
+        }
+        for _, r := range s.recent {
+            sumRecent += r.Rating
+        }
+        avgBefore := sumBefore / float64(len(s.before))
+        avgNow := (sumBefore + sumRecent) / float64(len(s.before)+len(s.recent))
+        swing := avgNow - avgBefore
+        if math.Abs(swing) < cfg.SwingThreshold {
+            continue
+        }
+
+        detail := fmt.Sprintf("el promedio pasó de %.1f a %.1f en menos de %.0f horas", avgBefore, avgNow, cfg.SwingWindow.Hours())
+        for _, r := range s.recent {
+            // Solo marco las calificaciones que empujan en la dirección del cambio
+            push := r.Rating - avgBefore
+            if push*swing > 0 && math.Abs(push) >= cfg.SwingThreshold {
+                if f, ok := raise(r, RuleSwing, detail); ok {
+                    created = append(created, f)
+                }
+            }
+        }
+    }
+    return created
+}
+
+// Listo las alertas pendientes de revisión, las más antiguas primero
+func Pending() []Flag {
+    var list []Flag
+    for _, f := range flags {
+        if f.Status == StatusPending {
+            list = append(list, *f)
+        }
+    }
+    sort.Slice(list, func(i, j int) bool {
+        return list[i].ID < list[j].ID
+    })
+    return list
+}
+
+// Resuelvo una alerta: excluyo la calificación del promedio o descarto la alerta
+func Resolve(flagID, adminID int, exclude bool, reason string) (Flag, error) {
+    f, exists := flags[flagID]
+    if !exists {
+        return Flag{}, errors.NewAppError("ANOMALY_001", "Alerta no encontrada", "")
+    }
+    if f.Status != StatusPending {
+        return Flag{}, errors.NewAppError("ANOMALY_003", "La alerta ya fue resuelta", f.Status)
+    }
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return Flag{}, errors.NewAppError("ANOMALY_002", "Debe indicar un motivo", "")
+    }
+
+    now := time.Now()
+    status, action := StatusDismissed, ActionDismiss
+    if exclude {
+        // La calificación puede estar excluida ya por otra regla
+        if !ratings.IsExcluded(f.Ref, f.UserID) {
+            if err := ratings.Exclude(f.Ref, f.UserID); err != nil {
+                return Flag{}, err
+            }
+        }
+        status, action = StatusExcluded, ActionExclude
+    }
+
+    // Excluir una calificación resuelve también las demás alertas sobre ella
+    for _, other := range flags {
+        if other.Status != StatusPending || other.Ref != f.Ref || other.UserID != f.UserID {
+            continue
+        }
+        if other.ID != f.ID && !exclude {
+            continue
+        }
+        other.Status = status
+        other.ReviewedBy = adminID
+        other.ReviewedAt = now
+        other.Reason = reason
+    }
+
+    audit = append(audit, AuditEntry{
+        Ref:     f.Ref,
+        UserID:  f.UserID,
+        Rating:  f.Rating,
+        Action:  action,
+        Rule:    f.Rule,
+        Reason:  reason,
+        AdminID: adminID,
+        At:      now,
+    })
+    return *f, nil
+}
+
+// Vuelvo a contar en el promedio una calificación que había sido excluida
+func Restore(ref categories.ContentRef, userID, adminID int, reason string) error {
+    reason = strings.TrimSpace(reason)
+    if reason == "" {
+        return errors.NewAppError("ANOMALY_002", "Debe indicar un motivo", "")
+    }
+    if err := ratings.Include(ref, userID); err != nil {
+        return err
+    }
+
+    var rating float64
+    if r, exists := ratings.GetUserRating(ref, userID); exists {
+        rating = r.Rating
+    }
+    audit = append(audit, AuditEntry{
+        Ref:     ref,
+        UserID:  userID,
+        Rating:  rating,
+        Action:  ActionRestore,
+        Reason:  reason,
+        AdminID: adminID,
+        At:      time.Now(),
+    })
+    return nil
+}
+
+// Obtengo la auditoría de exclusiones, restauraciones y descartes en orden cronológico
+func Audit() []AuditEntry {
+    return append([]AuditEntry(nil), audit...)
+}
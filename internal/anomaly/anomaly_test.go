@@ -0,0 +1,61 @@
+package anomaly
+
+import (
+    "testing"
+    "time"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/ratings"
+)
+
+// Verifico que una alerta descartada no se repita mientras la calificación no
+// cambie, y que vuelva a aparecer si el usuario la modifica
+func TestDismissedFlagRecursAfterChange(t *testing.T) {
+    cfg := Config{IdenticalMin: 2}
+    user, err := profiles.AddUser("Usuario Prueba", 30, "anomalia@prueba.com", "clave123", "Free", "Adulto", nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    profiles.MarkEmailVerified(user.ID)
+    refs := []categories.ContentRef{
+        {Kind: categories.KindAudiovisual, ID: 1},
+        {Kind: categories.KindAudiovisual, ID: 2},
+    }
+    for _, ref := range refs {
+        if _, err := ratings.RateContent(ref, user.ID, 10); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    ownFlags := func(list []Flag) []Flag {
+        var own []Flag
+        for _, f := range list {
+            if f.UserID == user.ID && f.Rule == RuleIdentical {
+                own = append(own, f)
+            }
+        }
+        return own
+    }
+    first := ownFlags(Scan(cfg, time.Now()))
+    if len(first) != 2 {
+        t.Fatalf("primer análisis: %d alertas, quiero 2", len(first))
+    }
+    for _, f := range first {
+        if _, err := Resolve(f.ID, 1, false, "falsa alarma"); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if again := ownFlags(Scan(cfg, time.Now())); len(again) != 0 {
+        t.Errorf("sin cambios se repitieron las alertas descartadas: %v", again)
+    }
+
+    // El usuario vuelve a calificar igual: es una calificación nueva
+    time.Sleep(time.Millisecond)
+    if _, err := ratings.RateContent(refs[0], user.ID, 10); err != nil {
+        t.Fatal(err)
+    }
+    recurred := ownFlags(Scan(cfg, time.Now()))
+    if len(recurred) != 1 || recurred[0].Ref != refs[0] {
+        t.Errorf("después de cambiar la calificación: %v, quiero una alerta para %v", recurred, refs[0])
+    }
+}
@@ -1,6 +1,7 @@
 package audio
 
 import (
+    "sort"
     "SDGEStreaming/internal/categories"
     "SDGEStreaming/internal/contentclass"
     "SDGEStreaming/internal/errors"
@@ -13,7 +14,9 @@ type AudioContent struct {
     ID            int
     Title         string
     Type          string // "Música", "Podcast", "Audiolibro"
-    Genre         string
+    Genre         string   // género principal
+    Genres        []string // todos los géneros, el principal primero
+    Tags          []string // etiquetas libres como "relajante" o "para entrenar"
     Duration      int    // en minutos
     AgeRating     string // "Infantil", "Adolescente", "Adulto"
     Artist        string
@@ -23,10 +26,27 @@ type AudioContent struct {
     IsAvailable   bool
 }
 
+// Índices secundarios que se pueden consultar con IDsWhere
+const (
+    IndexType      = "type"
+    IndexGenre     = "genre" // cualquiera de los géneros
+    IndexTag       = "tag"
+    IndexAgeRating = "age"
+)
+
 // Variables globales para almacenamiento en memoria
 var (
     contents []AudioContent
     nextID   = 1
+    revision = 0 // aumenta con cada cambio del catálogo
+
+    // Índices secundarios, mantenidos en cada alta, modificación y baja
+    byID        = make(map[int]int)      // ID -> posición en contents
+    byGenre     = make(map[string][]int) // género principal -> IDs en orden de alta
+    byAnyGenre  = make(map[string][]int) // cualquiera de los géneros -> IDs en orden de alta
+    byTag       = make(map[string][]int) // etiqueta -> IDs en orden de alta
+    byType      = make(map[string][]int) // tipo -> IDs en orden de alta
+    byAgeRating = make(map[string][]int) // clasificación -> IDs en orden de alta
 )
 
 // Inicializo contenido de audio de ejemplo
@@ -38,6 +58,37 @@ func init() {
 
 // Agrego nuevo contenido de audio
 func AddContent(title, contentType, genre string, duration int, ageRating string, artist string, album string, trackNumber int) error {
+    if err := validateContent(contentType, genre, duration, ageRating); err != nil {
+        return err
+    }
+    genre, _ = genres.CanonicalFor(categories.KindAudio, genre)
+    
+    // Creo el nuevo contenido
+    newContent := AudioContent{
+        ID:            nextID,
+        Title:         title,
+        Type:          contentType,
+        Genre:         genre,
+        Genres:        []string{genre},
+        Duration:      duration,
+        AgeRating:     ageRating,
+        Artist:        artist,
+        Album:         album,
+        TrackNumber:   trackNumber,
+        AverageRating: 0.0,
+        IsAvailable:   true,
+    }
+    
+    contents = append(contents, newContent)
+    byID[newContent.ID] = len(contents) - 1
+    indexContent(newContent)
+    nextID++
+    revision++
+    return nil
+}
+
+// Valido los datos de un contenido antes de guardarlo
+func validateContent(contentType, genre string, duration int, ageRating string) error {
     // Valido el tipo de contenido
     validTypes := map[string]bool{"Música": true, "Podcast": true, "Audiolibro": true}
     if !validTypes[contentType] {
@@ -45,8 +96,8 @@ func AddContent(title, contentType, genre string, duration int, ageRating string
     }
     
     // Valido género
-    if !genres.IsSupportedGenre(genre) {
-        return errors.NewAppError("CONTENT_005", "Género inválido", genre)
+    if _, err := genres.CanonicalFor(categories.KindAudio, genre); err != nil {
+        return err
     }
     
     // Valido duración
@@ -59,26 +110,245 @@ func AddContent(title, contentType, genre string, duration int, ageRating string
         return err
     }
     
-    // Creo el nuevo contenido
-    newContent := AudioContent{
-        ID:            nextID,
-        Title:         title,
-        Type:          contentType,
-        Genre:         genre,
-        Duration:      duration,
-        AgeRating:     ageRating,
-        Artist:        artist,
-        Album:         album,
-        TrackNumber:   trackNumber,
-        AverageRating: 0.0,
-        IsAvailable:   true,
+    return nil
+}
+
+// Agrego un contenido a los índices secundarios
+func indexContent(c AudioContent) {
+    byGenre[c.Genre] = insertID(byGenre[c.Genre], c.ID)
+    byType[c.Type] = insertID(byType[c.Type], c.ID)
+    byAgeRating[c.AgeRating] = insertID(byAgeRating[c.AgeRating], c.ID)
+    for _, g := range c.Genres {
+        byAnyGenre[g] = insertID(byAnyGenre[g], c.ID)
+    }
+    for _, t := range c.Tags {
+        byTag[t] = insertID(byTag[t], c.ID)
+    }
+}
+
+// Quito un contenido de los índices secundarios
+func unindexContent(c AudioContent) {
+    byGenre[c.Genre] = removeID(byGenre[c.Genre], c.ID)
+    byType[c.Type] = removeID(byType[c.Type], c.ID)
+    byAgeRating[c.AgeRating] = removeID(byAgeRating[c.AgeRating], c.ID)
+    for _, g := range c.Genres {
+        byAnyGenre[g] = removeID(byAnyGenre[g], c.ID)
+    }
+    for _, t := range c.Tags {
+        byTag[t] = removeID(byTag[t], c.ID)
+    }
+}
+
+// Inserto un ID manteniendo la lista ordenada; al reindexar una modificación
+// el ID puede no ser el mayor
+func insertID(ids []int, id int) []int {
+    i := sort.SearchInts(ids, id)
+    if i < len(ids) && ids[i] == id {
+        return ids
+    }
+    ids = append(ids, 0)
+    copy(ids[i+1:], ids[i:])
+    ids[i] = id
+    return ids
+}
+
+// Quito un ID de una lista ordenada de IDs
+func removeID(ids []int, id int) []int {
+    i := sort.SearchInts(ids, id)
+    if i < len(ids) && ids[i] == id {
+        return append(ids[:i], ids[i+1:]...)
+    }
+    return ids
+}
+
+// Actualizo los datos de un contenido existente
+func UpdateContent(id int, title, contentType, genre string, duration int, ageRating string, artist string, album string, trackNumber int) error {
+    content, err := GetByID(id)
+    if err != nil {
+        return err
     }
     
-    contents = append(contents, newContent)
-    nextID++
+    if err := validateContent(contentType, genre, duration, ageRating); err != nil {
+        return err
+    }
+    genre, _ = genres.CanonicalFor(categories.KindAudio, genre)
+    
+    unindexContent(*content)
+    // El nuevo género principal reemplaza al anterior y conserva los secundarios
+    secondary := []string{genre}
+    for _, g := range content.Genres {
+        if g != content.Genre && g != genre {
+            secondary = append(secondary, g)
+        }
+    }
+    content.Genres = secondary
+    content.Title = title
+    content.Type = contentType
+    content.Genre = genre
+    content.Duration = duration
+    content.AgeRating = ageRating
+    content.Artist = artist
+    content.Album = album
+    content.TrackNumber = trackNumber
+    indexContent(*content)
+    revision++
+    return nil
+}
+
+// Asigno los géneros de un contenido: uno principal y los secundarios
+func SetGenres(id int, primary string, secondary []string) error {
+    content, err := GetByID(id)
+    if err != nil {
+        return err
+    }
+    
+    primary, err = genres.CanonicalFor(categories.KindAudio, primary)
+    if err != nil {
+        return err
+    }
+    all := []string{primary}
+    seen := map[string]bool{primary: true}
+    for _, g := range secondary {
+        name, err := genres.CanonicalFor(categories.KindAudio, g)
+        if err != nil {
+            return err
+        }
+        if !seen[name] {
+            seen[name] = true
+            all = append(all, name)
+        }
+    }
+    
+    unindexContent(*content)
+    content.Genre = primary
+    content.Genres = all
+    indexContent(*content)
+    revision++
+    return nil
+}
+
+// Agrego una etiqueta libre a un contenido
+func AddTag(id int, tag string) error {
+    content, err := GetByID(id)
+    if err != nil {
+        return err
+    }
+    tag, err = genres.NormalizeTag(tag)
+    if err != nil {
+        return err
+    }
+    for _, t := range content.Tags {
+        if t == tag {
+            return errors.NewAppError("CONTENT_009", "El contenido ya tiene esa etiqueta", tag)
+        }
+    }
+    
+    unindexContent(*content)
+    content.Tags = append(append([]string(nil), content.Tags...), tag)
+    indexContent(*content)
+    revision++
+    return nil
+}
+
+// Quito una etiqueta de un contenido
+func RemoveTag(id int, tag string) error {
+    content, err := GetByID(id)
+    if err != nil {
+        return err
+    }
+    tag, _ = genres.NormalizeTag(tag)
+    for i, t := range content.Tags {
+        if t != tag {
+            continue
+        }
+        unindexContent(*content)
+        tags := append([]string(nil), content.Tags[:i]...)
+        content.Tags = append(tags, content.Tags[i+1:]...)
+        indexContent(*content)
+        revision++
+        return nil
+    }
+    return errors.NewAppError("CONTENT_010", "Etiqueta no encontrada", tag)
+}
+
+// Reemplazo un género por otro en todos los contenidos que lo usan, después de
+// renombrarlo o fusionarlo; devuelvo cuántos contenidos cambiaron
+func ReplaceGenre(oldName, newName string) int {
+    ids := append([]int(nil), byAnyGenre[oldName]...)
+    for _, id := range ids {
+        content := &contents[byID[id]]
+        unindexContent(*content)
+        if content.Genre == oldName {
+            content.Genre = newName
+        }
+        all := []string{content.Genre}
+        for _, g := range content.Genres {
+            if g == oldName {
+                g = newName
+            }
+            if !containsString(all, g) {
+                all = append(all, g)
+            }
+        }
+        content.Genres = all
+        indexContent(*content)
+    }
+    if len(ids) > 0 {
+        revision++
+    }
+    return len(ids)
+}
+
+// Cuento los contenidos, disponibles o no, que usan un género
+func CountByGenre(genre string) int {
+    return len(byAnyGenre[genre])
+}
+
+// Verifico si una lista contiene un texto
+func containsString(list []string, s string) bool {
+    for _, v := range list {
+        if v == s {
+            return true
+        }
+    }
+    return false
+}
+
+// Cambio la disponibilidad de un contenido sin borrarlo
+func SetAvailability(id int, available bool) error {
+    content, err := GetByID(id)
+    if err != nil {
+        return err
+    }
+    
+    content.IsAvailable = available
+    revision++
+    return nil
+}
+
+// Elimino un contenido del catálogo
+func DeleteContent(id int) error {
+    pos, exists := byID[id]
+    if !exists {
+        return errors.ErrContentNotFound
+    }
+    
+    unindexContent(contents[pos])
+    contents = append(contents[:pos], contents[pos+1:]...)
+    delete(byID, id)
+    // Corrijo las posiciones de los contenidos que se desplazaron
+    for i := pos; i < len(contents); i++ {
+        byID[contents[i].ID] = i
+    }
+    revision++
     return nil
 }
 
+// Obtengo la revisión actual del catálogo para detectar cambios
+func Revision() int {
+    return revision
+}
+
 // Listo todo el contenido de audio disponible
 func ListAll() []AudioContent {
     var availableContents []AudioContent
@@ -90,14 +360,18 @@ func ListAll() []AudioContent {
     return availableContents
 }
 
+// Listo todo el contenido, incluido el que no está disponible
+func ListAllIncludingUnavailable() []AudioContent {
+    return append([]AudioContent(nil), contents...)
+}
+
 // Obtengo contenido por ID
 func GetByID(id int) (*AudioContent, error) {
-    for i, c := range contents {
-        if c.ID == id {
-            return &contents[i], nil
-        }
+    pos, exists := byID[id]
+    if !exists {
+        return nil, errors.ErrContentNotFound
     }
-    return nil, errors.ErrContentNotFound
+    return &contents[pos], nil
 }
 
 // Califico un contenido de audio
@@ -112,50 +386,120 @@ func RateContent(contentID, userID int, rating float64) (string, error) {
     }
     
     // Uso el módulo de ratings para manejar la calificación
-    message, err := ratings.RateContent(contentID, userID, rating)
+    ref := categories.ContentRef{Kind: categories.KindAudio, ID: contentID}
+    message, err := ratings.RateContent(ref, userID, rating)
     if err != nil {
         return "", err
     }
     
     // Recalculo el promedio
-    avg, _ := ratings.GetAverage(contentID)
+    avg, _ := ratings.GetAverage(ref)
     content.AverageRating = avg
     
     return message, nil
 }
 
+// Retiro la calificación de un usuario y recalculo el promedio
+func WithdrawRating(contentID, userID int) error {
+    content, err := GetByID(contentID)
+    if err != nil {
+        return err
+    }
+    
+    ref := categories.ContentRef{Kind: categories.KindAudio, ID: contentID}
+    if err := ratings.RemoveRating(ref, userID); err != nil {
+        return err
+    }
+    
+    // Sin calificaciones el promedio vuelve a 0
+    avg, _ := ratings.GetAverage(ref)
+    content.AverageRating = avg
+    return nil
+}
+
+// Recalculo el promedio de un contenido después de excluir o restaurar calificaciones
+func RefreshAverage(contentID int) error {
+    content, err := GetByID(contentID)
+    if err != nil {
+        return err
+    }
+    
+    avg, _ := ratings.GetAverage(categories.ContentRef{Kind: categories.KindAudio, ID: contentID})
+    content.AverageRating = avg
+    return nil
+}
+
 // Obtengo las calificaciones individuales de un contenido
 func GetIndividualRatings(contentID int) ([]categories.UserRating, error) {
-    return ratings.GetRatings(contentID)
+    return ratings.GetRatings(categories.ContentRef{Kind: categories.KindAudio, ID: contentID})
 }
 
 // Filtro contenido por tipo
 func FilterByType(contentType string) []AudioContent {
-    var filtered []AudioContent
-    for _, c := range contents {
-        if c.Type == contentType && c.IsAvailable {
-            filtered = append(filtered, c)
-        }
-    }
-    return filtered
+    return availableByIDs(byType[contentType])
 }
 
-// Filtro contenido por género
+// Filtro contenido por género principal
 func FilterByGenre(genre string) []AudioContent {
-    var filtered []AudioContent
-    for _, c := range contents {
-        if c.Genre == genre && c.IsAvailable {
-            filtered = append(filtered, c)
-        }
-    }
-    return filtered
+    return availableByIDs(byGenre[genre])
+}
+
+// Filtro contenido que tenga el género entre cualquiera de los suyos
+func FilterByAnyGenre(genre string) []AudioContent {
+    return availableByIDs(byAnyGenre[genre])
+}
+
+// Filtro contenido por etiqueta
+func FilterByTag(tag string) []AudioContent {
+    tag, _ = genres.NormalizeTag(tag)
+    return availableByIDs(byTag[tag])
 }
 
 // Filtro contenido por clasificación de edad
 func FilterByAgeRating(ageRating string) []AudioContent {
-    var filtered []AudioContent
-    for _, c := range contents {
-        if c.AgeRating == ageRating && c.IsAvailable {
+    return availableByIDs(byAgeRating[ageRating])
+}
+
+// Obtengo los IDs, disponibles o no y en orden de alta, de los contenidos
+// cuya clave en un índice secundario cumple match. Sirve para acotar una
+// consulta antes de revisar cada contenido
+func IDsWhere(index string, match func(key string) bool) []int {
+    var byKey map[string][]int
+    switch index {
+    case IndexType:
+        byKey = byType
+    case IndexGenre:
+        byKey = byAnyGenre
+    case IndexTag:
+        byKey = byTag
+    case IndexAgeRating:
+        byKey = byAgeRating
+    default:
+        return nil
+    }
+
+    var ids []int
+    seen := make(map[int]bool)
+    for key, list := range byKey {
+        if !match(key) {
+            continue
+        }
+        for _, id := range list {
+            if !seen[id] {
+                seen[id] = true
+                ids = append(ids, id)
+            }
+        }
+    }
+    sort.Ints(ids)
+    return ids
+}
+
+// Obtengo los contenidos disponibles de una lista de IDs
+func availableByIDs(ids []int) []AudioContent {
+    filtered := make([]AudioContent, 0, len(ids))
+    for _, id := range ids {
+        if c := contents[byID[id]]; c.IsAvailable {
             filtered = append(filtered, c)
         }
     }
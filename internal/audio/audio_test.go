@@ -0,0 +1,101 @@
+package audio
+
+import (
+    "fmt"
+    "sort"
+    "testing"
+    "SDGEStreaming/internal/errors"
+)
+
+// Obtengo el código de un error de la aplicación, o vacío si no es uno
+func errorCode(err error) string {
+    if appErr, ok := err.(*errors.AppError); ok {
+        return appErr.Code
+    }
+    return ""
+}
+
+// Verifico que cada índice secundario tenga exactamente los contenidos que le
+// corresponden y que byID apunte a la posición correcta
+func checkIndexes(t *testing.T) {
+    t.Helper()
+    want := map[string]map[string][]int{"genre": {}, "anygenre": {}, "tag": {}, "type": {}, "age": {}}
+    for pos, c := range contents {
+        if byID[c.ID] != pos {
+            t.Errorf("byID[%d] = %d, quiero %d", c.ID, byID[c.ID], pos)
+        }
+        want["genre"][c.Genre] = append(want["genre"][c.Genre], c.ID)
+        want["type"][c.Type] = append(want["type"][c.Type], c.ID)
+        want["age"][c.AgeRating] = append(want["age"][c.AgeRating], c.ID)
+        for _, g := range c.Genres {
+            want["anygenre"][g] = append(want["anygenre"][g], c.ID)
+        }
+        for _, tag := range c.Tags {
+            want["tag"][tag] = append(want["tag"][tag], c.ID)
+        }
+    }
+    if len(byID) != len(contents) {
+        t.Errorf("byID tiene %d contenidos, quiero %d", len(byID), len(contents))
+    }
+    indexes := map[string]map[string][]int{"genre": byGenre, "anygenre": byAnyGenre, "tag": byTag, "type": byType, "age": byAgeRating}
+    for name, index := range indexes {
+        for key, ids := range index {
+            expected := want[name][key]
+            sort.Ints(expected)
+            if fmt.Sprint(ids) != fmt.Sprint(expected) && !(len(ids) == 0 && len(expected) == 0) {
+                t.Errorf("índice %s[%q] = %v, quiero %v", name, key, ids, expected)
+            }
+        }
+        for key, expected := range want[name] {
+            if _, exists := index[key]; !exists {
+                t.Errorf("índice %s sin la clave %q de %v", name, key, expected)
+            }
+        }
+    }
+}
+
+// Verifico que modificar, ocultar y borrar contenidos mantenga los índices
+func TestIndexesFollowChanges(t *testing.T) {
+    if err := AddContent("Índice Audio", "Podcast", "Tecnología", 40, "Adolescente", "Autor", "Serie", 1); err != nil {
+        t.Fatal(err)
+    }
+    id := contents[len(contents)-1].ID
+    if err := AddTag(id, "para entrenar"); err != nil {
+        t.Fatal(err)
+    }
+    if err := AddContent("Índice Audio Siguiente", "Música", "Clásica", 5, "Infantil", "Autor", "Disco", 2); err != nil {
+        t.Fatal(err)
+    }
+    next := contents[len(contents)-1].ID
+
+    if err := UpdateContent(id, "Índice Audio", "Música", "Clásica", 4, "Infantil", "Autor", "Disco", 3); err != nil {
+        t.Fatal(err)
+    }
+    checkIndexes(t)
+    if err := UpdateContent(id, "Índice Audio", "Música", "Clásica", 4, "Inexistente", "Autor", "Disco", 3); err == nil {
+        t.Error("se aceptó una clasificación inexistente")
+    }
+
+    if err := SetAvailability(id, false); err != nil {
+        t.Fatal(err)
+    }
+    for _, c := range FilterByType("Música") {
+        if c.ID == id {
+            t.Error("FilterByType incluye un contenido no disponible")
+        }
+    }
+
+    if err := DeleteContent(id); err != nil {
+        t.Fatal(err)
+    }
+    checkIndexes(t)
+    if _, err := GetByID(id); errorCode(err) != "CONTENT_001" {
+        t.Errorf("GetByID del contenido borrado: error %v, quiero CONTENT_001", err)
+    }
+    if c, err := GetByID(next); err != nil || c.Title != "Índice Audio Siguiente" {
+        t.Errorf("GetByID(%d) después del borrado = %v, %v", next, c, err)
+    }
+    if got := IDsWhere(IndexTag, func(key string) bool { return key == "para entrenar" }); len(got) != 0 {
+        t.Errorf("IDsWhere(etiqueta) después del borrado = %v, quiero vacío", got)
+    }
+}
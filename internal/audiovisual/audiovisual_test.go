@@ -0,0 +1,177 @@
+package audiovisual
+
+import (
+    "fmt"
+    "sort"
+    "testing"
+    "SDGEStreaming/internal/errors"
+)
+
+// Obtengo el código de un error de la aplicación, o vacío si no es uno
+func errorCode(err error) string {
+    if appErr, ok := err.(*errors.AppError); ok {
+        return appErr.Code
+    }
+    return ""
+}
+
+// Verifico que cada índice secundario tenga exactamente los contenidos que le
+// corresponden y que byID apunte a la posición correcta
+func checkIndexes(t *testing.T) {
+    t.Helper()
+    want := map[string]map[string][]int{"genre": {}, "anygenre": {}, "tag": {}, "type": {}, "age": {}}
+    for pos, c := range contents {
+        if byID[c.ID] != pos {
+            t.Errorf("byID[%d] = %d, quiero %d", c.ID, byID[c.ID], pos)
+        }
+        want["genre"][c.Genre] = append(want["genre"][c.Genre], c.ID)
+        want["type"][c.Type] = append(want["type"][c.Type], c.ID)
+        want["age"][c.AgeRating] = append(want["age"][c.AgeRating], c.ID)
+        for _, g := range c.Genres {
+            want["anygenre"][g] = append(want["anygenre"][g], c.ID)
+        }
+        for _, tag := range c.Tags {
+            want["tag"][tag] = append(want["tag"][tag], c.ID)
+        }
+    }
+    if len(byID) != len(contents) {
+        t.Errorf("byID tiene %d contenidos, quiero %d", len(byID), len(contents))
+    }
+    indexes := map[string]map[string][]int{"genre": byGenre, "anygenre": byAnyGenre, "tag": byTag, "type": byType, "age": byAgeRating}
+    for name, index := range indexes {
+        for key, ids := range index {
+            expected := want[name][key]
+            sort.Ints(expected)
+            if fmt.Sprint(ids) != fmt.Sprint(expected) && !(len(ids) == 0 && len(expected) == 0) {
+                t.Errorf("índice %s[%q] = %v, quiero %v", name, key, ids, expected)
+            }
+        }
+        for key, expected := range want[name] {
+            if _, exists := index[key]; !exists {
+                t.Errorf("índice %s sin la clave %q de %v", name, key, expected)
+            }
+        }
+    }
+}
+
+// Agrego un contenido de prueba y devuelvo su ID
+func addTestContent(t *testing.T, title, contentType, genre, ageRating string) int {
+    t.Helper()
+    if err := AddContent(title, contentType, genre, 100, ageRating, "", 2020, ""); err != nil {
+        t.Fatal(err)
+    }
+    return contents[len(contents)-1].ID
+}
+
+// Verifico que modificar un contenido lo mueva de clave en cada índice y
+// conserve sus géneros secundarios y etiquetas
+func TestUpdateContentReindexes(t *testing.T) {
+    id := addTestContent(t, "Índice Modificado", "Película", "Drama", "Adulto")
+    if err := SetGenres(id, "Drama", []string{"Romance"}); err != nil {
+        t.Fatal(err)
+    }
+    if err := AddTag(id, "clásico"); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := UpdateContent(id, "Índice Modificado", "Serie", "Comedia", 45, "Infantil", "", 2021, ""); err != nil {
+        t.Fatal(err)
+    }
+    checkIndexes(t)
+    c, _ := GetByID(id)
+    if c.Type != "Serie" || c.Genre != "Comedia" || c.AgeRating != "Infantil" {
+        t.Errorf("contenido modificado = %+v", *c)
+    }
+    if fmt.Sprint(c.Genres) != "[Comedia Romance]" {
+        t.Errorf("Genres = %v, quiero [Comedia Romance]", c.Genres)
+    }
+    for _, old := range FilterByType("Película") {
+        if old.ID == id {
+            t.Error("FilterByType(Película) todavía incluye el contenido modificado")
+        }
+    }
+    found := false
+    for _, c := range FilterByAgeRating("Infantil") {
+        found = found || c.ID == id
+    }
+    if !found {
+        t.Error("FilterByAgeRating(Infantil) no incluye el contenido modificado")
+    }
+
+    tests := []struct {
+        name        string
+        contentType string
+        genre       string
+        duration    int
+        ageRating   string
+        code        string
+    }{
+        {"género inexistente", "Serie", "Inexistente", 45, "Infantil", "CONTENT_005"},
+        {"duración inválida", "Serie", "Comedia", 0, "Infantil", "CONTENT_003"},
+    }
+    for _, tt := range tests {
+        err := UpdateContent(id, "Índice Modificado", tt.contentType, tt.genre, tt.duration, tt.ageRating, "", 2021, "")
+        if errorCode(err) != tt.code {
+            t.Errorf("%s: error %v, quiero %s", tt.name, err, tt.code)
+        }
+    }
+    if err := UpdateContent(-1, "x", "Serie", "Comedia", 45, "Infantil", "", 2021, ""); errorCode(err) != "CONTENT_001" {
+        t.Errorf("contenido inexistente: error %v, quiero CONTENT_001", err)
+    }
+    checkIndexes(t)
+}
+
+// Verifico que borrar un contenido lo quite de todos los índices y que los
+// contenidos siguientes se sigan encontrando por ID
+func TestDeleteContentUnindexes(t *testing.T) {
+    first := addTestContent(t, "Índice Borrado", "Documental", "Documental", "Adolescente")
+    if err := AddTag(first, "borrable"); err != nil {
+        t.Fatal(err)
+    }
+    second := addTestContent(t, "Índice Siguiente", "Documental", "Documental", "Adolescente")
+
+    if err := DeleteContent(first); err != nil {
+        t.Fatal(err)
+    }
+    checkIndexes(t)
+    if _, err := GetByID(first); errorCode(err) != "CONTENT_001" {
+        t.Errorf("GetByID del contenido borrado: error %v, quiero CONTENT_001", err)
+    }
+    if c, err := GetByID(second); err != nil || c.Title != "Índice Siguiente" {
+        t.Errorf("GetByID(%d) después del borrado = %v, %v", second, c, err)
+    }
+    if got := FilterByTag("borrable"); len(got) != 0 {
+        t.Errorf("FilterByTag(borrable) = %v, quiero vacío", got)
+    }
+    if err := DeleteContent(first); errorCode(err) != "CONTENT_001" {
+        t.Errorf("borrar dos veces: error %v, quiero CONTENT_001", err)
+    }
+}
+
+// Verifico que un contenido no disponible siga indexado pero no se liste
+func TestSetAvailability(t *testing.T) {
+    id := addTestContent(t, "Índice Oculto", "Película", "Terror", "Adulto")
+    if err := SetAvailability(id, false); err != nil {
+        t.Fatal(err)
+    }
+    checkIndexes(t)
+    for _, c := range FilterByGenre("Terror") {
+        if c.ID == id {
+            t.Error("FilterByGenre incluye un contenido no disponible")
+        }
+    }
+    ids := IDsWhere(IndexGenre, func(key string) bool { return key == "Terror" })
+    if i := sort.SearchInts(ids, id); i == len(ids) || ids[i] != id {
+        t.Errorf("IDsWhere(género Terror) = %v, quiero que incluya %d", ids, id)
+    }
+    if err := SetAvailability(id, true); err != nil {
+        t.Fatal(err)
+    }
+    found := false
+    for _, c := range FilterByGenre("Terror") {
+        found = found || c.ID == id
+    }
+    if !found {
+        t.Error("FilterByGenre no incluye el contenido disponible de nuevo")
+    }
+}
@@ -0,0 +1,103 @@
+package audiovisual
+
+import (
+    "fmt"
+    "math/rand"
+    "sync"
+    "testing"
+)
+
+// Tamaño del catálogo sintético de los benchmarks
+const benchItems = 100000
+
+var (
+    benchTypes      = []string{"Película", "Serie", "Documental"}
+    benchGenres     = []string{"Acción", "Comedia", "Drama", "Ciencia Ficción", "Romance", "Terror", "Documental"}
+    benchAgeRatings = []string{"Infantil", "Adolescente", "Adulto"}
+
+    populateOnce sync.Once
+    snapshot     []AudiovisualContent
+)
+
+// Lleno una sola vez el catálogo con contenido sintético
+func populate(b *testing.B) {
+    populateOnce.Do(func() {
+        rng := rand.New(rand.NewSource(1))
+        for i := 0; i < benchItems; i++ {
+            genre := benchGenres[rng.Intn(len(benchGenres))]
+            if rng.Intn(100) == 0 {
+                // Un género poco común para medir filtros selectivos
+                genre = "Deportes"
+            }
+            AddContent(fmt.Sprintf("Contenido %d", i), benchTypes[rng.Intn(len(benchTypes))], genre,
+                30+rng.Intn(150), benchAgeRatings[rng.Intn(len(benchAgeRatings))], "Sinopsis generada", 1980+rng.Intn(45), "Director")
+        }
+        snapshot = ListAllIncludingUnavailable()
+    })
+    b.ResetTimer()
+}
+
+// Recorrido lineal equivalente a GetByID sin índices
+func scanByID(items []AudiovisualContent, id int) *AudiovisualContent {
+    for i, c := range items {
+        if c.ID == id {
+            return &items[i]
+        }
+    }
+    return nil
+}
+
+// Recorrido lineal equivalente a FilterByGenre sin índices
+func scanByGenre(items []AudiovisualContent, genre string) []AudiovisualContent {
+    var filtered []AudiovisualContent
+    for _, c := range items {
+        if c.Genre == genre && c.IsAvailable {
+            filtered = append(filtered, c)
+        }
+    }
+    return filtered
+}
+
+func BenchmarkGetByIDScan(b *testing.B) {
+    populate(b)
+    lastID := snapshot[len(snapshot)-1].ID
+    for i := 0; i < b.N; i++ {
+        scanByID(snapshot, lastID)
+    }
+}
+
+func BenchmarkGetByID(b *testing.B) {
+    populate(b)
+    lastID := snapshot[len(snapshot)-1].ID
+    for i := 0; i < b.N; i++ {
+        GetByID(lastID)
+    }
+}
+
+func BenchmarkFilterByGenreScan(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        scanByGenre(snapshot, "Terror")
+    }
+}
+
+func BenchmarkFilterByGenre(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        FilterByGenre("Terror")
+    }
+}
+
+func BenchmarkFilterByRareGenreScan(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        scanByGenre(snapshot, "Deportes")
+    }
+}
+
+func BenchmarkFilterByRareGenre(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        FilterByGenre("Deportes")
+    }
+}
@@ -0,0 +1,156 @@
+package audit
+
+import (
+    "crypto/sha256"
+    "encoding/csv"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/profiles"
+)
+
+// Resultados de una operación registrada, además del texto del error
+const (
+    ResultOK     = "ok"
+    ResultDenied = "denegado"
+)
+
+// Operación administrativa registrada. Cada entrada guarda el hash de la
+// anterior, así que modificar o borrar una rompe la cadena desde ese punto
+type Entry struct {
+    Seq      int
+    At       time.Time
+    ActorID  int
+    Actor    string // email del actor al momento de la operación
+    Action   string
+    Target   string
+    Before   string
+    After    string
+    Result   string
+    PrevHash string
+    Hash     string
+}
+
+// Criterios para consultar la auditoría; los campos vacíos no filtran
+type Filter struct {
+    ActorID int
+    Action  string    // acción exacta o prefijo, por ejemplo "usuarios."
+    From    time.Time // inclusive
+    To      time.Time // exclusive
+}
+
+// Hash anterior de la primera entrada
+var genesis = strings.Repeat("0", 64)
+
+// Variables globales para almacenamiento en memoria
+var (
+    entries []Entry // solo se agregan entradas al final
+)
+
+// Calculo el hash de una entrada a partir de todos sus campos y el hash anterior.
+// Cada campo va entre comillas para que no se pueda pasar texto de un campo a otro
+func (e Entry) digest() string {
+    fields := []string{
+        strconv.Itoa(e.Seq), e.At.UTC().Format(time.RFC3339Nano), strconv.Itoa(e.ActorID), e.Actor,
+        e.Action, e.Target, e.Before, e.After, e.Result, e.PrevHash,
+    }
+    h := sha256.New()
+    for _, f := range fields {
+        h.Write([]byte(strconv.Quote(f)))
+        h.Write([]byte{'\n'})
+    }
+    return hex.EncodeToString(h.Sum(nil))
+}
+
+// Registro una operación. err es el error con que terminó o nil si salió bien
+func Record(actorID int, action, target, before, after string, err error) Entry {
+    actor := ""
+    if user, findErr := profiles.FindByID(actorID); findErr == nil {
+        actor = user.Email
+    }
+    result := ResultOK
+    if err == errors.ErrPermissionDenied {
+        result = ResultDenied
+    } else if err != nil {
+        result = err.Error()
+    }
+
+    prev := genesis
+    if len(entries) > 0 {
+        prev = entries[len(entries)-1].Hash
+    }
+    e := Entry{
+        Seq:      len(entries) + 1,
+        At:       time.Now(),
+        ActorID:  actorID,
+        Actor:    actor,
+        Action:   action,
+        Target:   target,
+        Before:   before,
+        After:    after,
+        Result:   result,
+        PrevHash: prev,
+    }
+    e.Hash = e.digest()
+    entries = append(entries, e)
+    return e
+}
+
+// Verifico la cadena completa. Devuelvo la cantidad de entradas verificadas
+// o el número de la primera entrada alterada
+func Verify() (int, error) {
+    prev := genesis
+    for i, e := range entries {
+        if e.Seq != i+1 || e.PrevHash != prev || e.digest() != e.Hash {
+            return i, errors.NewAppError("AUDIT_001", "La auditoría fue alterada", fmt.Sprintf("Desde la entrada %d", i+1))
+        }
+        prev = e.Hash
+    }
+    return len(entries), nil
+}
+
+// Verifico si una entrada cumple un filtro
+func (f Filter) matches(e Entry) bool {
+    if f.ActorID != 0 && e.ActorID != f.ActorID {
+        return false
+    }
+    if f.Action != "" && !strings.HasPrefix(e.Action, f.Action) {
+        return false
+    }
+    if !f.From.IsZero() && e.At.Before(f.From) {
+        return false
+    }
+    if !f.To.IsZero() && !e.At.Before(f.To) {
+        return false
+    }
+    return true
+}
+
+// Obtengo las entradas que cumplen un filtro, en orden cronológico
+func Query(f Filter) []Entry {
+    var list []Entry
+    for _, e := range entries {
+        if f.matches(e) {
+            list = append(list, e)
+        }
+    }
+    return list
+}
+
+// Exporto entradas en CSV con sus hashes, para poder verificar la cadena fuera del sistema
+func Export(w io.Writer, list []Entry) error {
+    cw := csv.NewWriter(w)
+    cw.Write([]string{"seq", "fecha", "actor_id", "actor", "accion", "objetivo", "antes", "despues", "resultado", "hash_anterior", "hash"})
+    for _, e := range list {
+        cw.Write([]string{
+            strconv.Itoa(e.Seq), e.At.UTC().Format(time.RFC3339Nano), strconv.Itoa(e.ActorID), e.Actor,
+            e.Action, e.Target, e.Before, e.After, e.Result, e.PrevHash, e.Hash,
+        })
+    }
+    cw.Flush()
+    return cw.Error()
+}
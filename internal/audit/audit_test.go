@@ -0,0 +1,120 @@
+package audit
+
+import (
+    "bytes"
+    "encoding/csv"
+    "testing"
+    "time"
+    "SDGEStreaming/internal/errors"
+)
+
+// Obtengo el código de un error de la aplicación, o vacío si no es uno
+func errorCode(err error) string {
+    if appErr, ok := err.(*errors.AppError); ok {
+        return appErr.Code
+    }
+    return ""
+}
+
+// Dejo una auditoría con cinco entradas y la restauro al terminar la prueba
+func withEntries(t *testing.T) {
+    saved := entries
+    t.Cleanup(func() { entries = saved })
+    entries = nil
+    Record(1, "usuarios.editar", "usuario 2", "plan=Free", "plan=Premium", nil)
+    Record(1, "usuarios.roles", "usuario 3", "", "roles=editor", nil)
+    Record(2, "usuarios.eliminar", "usuario 1", "", "", errors.ErrPermissionDenied)
+    Record(1, "generos.renombrar", "género 4", "nombre=Cine", "nombre=Film", nil)
+    Record(1, "usuarios.plan", "usuario 5", "plan=Premium", "plan=Free", nil)
+}
+
+// Verifico que cualquier modificación, borrado o reordenamiento se detecte en la
+// primera entrada afectada
+func TestVerifyDetectsTampering(t *testing.T) {
+    tests := []struct {
+        name   string
+        tamper func()
+        first  int // entradas válidas antes de la alterada; -1 si la cadena queda intacta
+    }{
+        {"sin cambios", func() {}, -1},
+        {"cambio el valor posterior", func() { entries[1].After = "roles=superadmin" }, 1},
+        {"cambio el resultado", func() { entries[2].Result = ResultOK }, 2},
+        {"cambio el actor", func() { entries[0].ActorID = 9 }, 0},
+        {"cambio la fecha", func() { entries[3].At = entries[3].At.Add(-time.Hour) }, 3},
+        {"muevo texto entre campos", func() {
+            entries[0].Before, entries[0].After = "plan=Free\"\n\"plan=Premium", ""
+        }, 0},
+        {"recalculo el hash de la entrada cambiada", func() {
+            entries[1].After = "roles=superadmin"
+            entries[1].Hash = entries[1].digest()
+        }, 2},
+        {"borro una entrada", func() { entries = append(entries[:2], entries[3:]...) }, 2},
+        {"borro la primera entrada", func() { entries = entries[1:] }, 0},
+        {"intercambio dos entradas", func() { entries[3], entries[4] = entries[4], entries[3] }, 3},
+        // Cortar el final deja una cadena válida; para eso está el hash exportado
+        {"borro la última entrada", func() { entries = entries[:4] }, -1},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            withEntries(t)
+            total := len(entries)
+            tt.tamper()
+            verified, err := Verify()
+            if tt.first < 0 {
+                if err != nil || verified != len(entries) {
+                    t.Errorf("Verify() = %d, %v; quiero %d sin error", verified, err, len(entries))
+                }
+                return
+            }
+            if errorCode(err) != "AUDIT_001" || verified != tt.first {
+                t.Errorf("Verify() = %d, %v; quiero %d con AUDIT_001 (de %d entradas)", verified, err, tt.first, total)
+            }
+        })
+    }
+}
+
+// Verifico los filtros de la consulta
+func TestQuery(t *testing.T) {
+    withEntries(t)
+    tests := []struct {
+        filter Filter
+        want   int
+    }{
+        {Filter{}, 5},
+        {Filter{ActorID: 1}, 4},
+        {Filter{Action: "usuarios."}, 4},
+        {Filter{Action: "usuarios.plan"}, 1},
+        {Filter{ActorID: 2, Action: "generos."}, 0},
+        {Filter{From: time.Now().Add(time.Hour)}, 0},
+        {Filter{To: time.Now().Add(time.Hour)}, 5},
+    }
+    for _, tt := range tests {
+        if got := Query(tt.filter); len(got) != tt.want {
+            t.Errorf("Query(%+v) = %d entradas, quiero %d", tt.filter, len(got), tt.want)
+        }
+    }
+    if denied := Query(Filter{ActorID: 2}); len(denied) != 1 || denied[0].Result != ResultDenied {
+        t.Errorf("Query(actor 2) = %+v, quiero una entrada denegada", denied)
+    }
+}
+
+// Verifico que la exportación incluya los hashes encadenados
+func TestExport(t *testing.T) {
+    withEntries(t)
+    var buf bytes.Buffer
+    if err := Export(&buf, Query(Filter{})); err != nil {
+        t.Fatal(err)
+    }
+    rows, err := csv.NewReader(&buf).ReadAll()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(rows) != 6 {
+        t.Fatalf("%d filas, quiero encabezado y 5 entradas", len(rows))
+    }
+    for i, row := range rows[1:] {
+        if row[10] != entries[i].Hash || (i > 0 && row[9] != rows[i][10]) {
+            t.Errorf("fila %d: hashes %s -> %s no encadenan", i+1, row[9], row[10])
+        }
+    }
+}
@@ -0,0 +1,302 @@
+package auth
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "time"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/textnorm"
+    "SDGEStreaming/internal/twofactor"
+)
+
+// Resultados de un intento de inicio de sesión
+const (
+    ResultSuccess   = "correcto"
+    ResultInvalid   = "credenciales inválidas"
+    ResultThrottled = "demorado"
+    ResultLocked    = "cuenta bloqueada"
+    ResultBadCode   = "segundo paso inválido"
+    ResultDisabled  = "cuenta deshabilitada"
+    ResultMustReset = "debe restablecer contraseña"
+)
+
+// Umbrales de la protección contra intentos repetidos
+var (
+    // Fallos permitidos antes de empezar a demorar, por cuenta y por origen
+    FreeAttempts       = 3
+    SourceFreeAttempts = 10
+    // La espera arranca en BaseDelay y se duplica con cada fallo hasta MaxDelay
+    BaseDelay = 2 * time.Second
+    MaxDelay  = 5 * time.Minute
+    // Fallos seguidos que bloquean la cuenta y por cuánto tiempo
+    LockoutThreshold = 10
+    LockoutDuration  = 30 * time.Minute
+    // Tiempo para ingresar el código después de la contraseña
+    SecondFactorWindow = 5 * time.Minute
+    // Un contador sin fallos nuevos en FailureTTL se olvida, y nunca se guardan
+    // más de MaxTracked por cuenta o por origen, aunque los emails no existan
+    FailureTTL = 24 * time.Hour
+    MaxTracked = 10000
+)
+
+// Intento de inicio de sesión registrado
+type Attempt struct {
+    At     time.Time
+    Email  string
+    UserID int // 0 si el email no corresponde a ninguna cuenta
+    Source string
+    Result string
+}
+
+// Cuenta bloqueada y hasta cuándo
+type Lockout struct {
+    Email       string
+    Failures    int
+    LockedUntil time.Time
+}
+
+// Fallos seguidos de una cuenta o de un origen
+type counter struct {
+    failures    int
+    lastFailure time.Time
+    lockedUntil time.Time
+}
+
+// Variables globales para almacenamiento en memoria
+var (
+    byAccount     = make(map[string]*counter) // clave del email -> fallos; también para emails inexistentes
+    bySource      = make(map[string]*counter) // origen -> fallos
+    pendingSecond = make(map[int]time.Time)   // usuario -> vencimiento del segundo paso
+    attempts      []Attempt
+)
+
+// Calculo cuánto falta para poder intentar de nuevo según los fallos acumulados
+func wait(c *counter, free int, now time.Time) time.Duration {
+    if c == nil || c.failures < free {
+        return 0
+    }
+    delay := time.Duration(float64(BaseDelay) * math.Pow(2, float64(c.failures-free)))
+    if delay > MaxDelay || delay <= 0 {
+        delay = MaxDelay
+    }
+    return c.lastFailure.Add(delay).Sub(now)
+}
+
+// Verifico si un contador ya no sirve: sin bloqueo vigente y sin fallos recientes
+func expired(c *counter, now time.Time) bool {
+    return !now.Before(c.lockedUntil) && now.Sub(c.lastFailure) >= FailureTTL
+}
+
+// Hago lugar para un contador nuevo: primero olvido los vencidos y, si no
+// alcanza, el de fallo más viejo, prefiriendo los que no están bloqueados
+func makeRoom(counters map[string]*counter, now time.Time) {
+    for key, c := range counters {
+        if expired(c, now) {
+            delete(counters, key)
+        }
+    }
+    for len(counters) >= MaxTracked {
+        oldest := ""
+        for key, c := range counters {
+            if oldest == "" {
+                oldest = key
+                continue
+            }
+            o := counters[oldest]
+            locked, oldestLocked := now.Before(c.lockedUntil), now.Before(o.lockedUntil)
+            if (oldestLocked && !locked) || (locked == oldestLocked && c.lastFailure.Before(o.lastFailure)) {
+                oldest = key
+            }
+        }
+        delete(counters, oldest)
+    }
+}
+
+// Sumo un fallo a un contador
+func fail(counters map[string]*counter, key string, now time.Time) *counter {
+    c := counters[key]
+    if c != nil && expired(c, now) {
+        c = nil
+    }
+    if c == nil {
+        if _, exists := counters[key]; !exists && len(counters) >= MaxTracked {
+            makeRoom(counters, now)
+        }
+        c = &counter{}
+        counters[key] = c
+    }
+    c.failures++
+    c.lastFailure = now
+    return c
+}
+
+// Registro un intento en el historial
+func record(email string, userID int, source, result string, now time.Time) {
+    attempts = append(attempts, Attempt{At: now, Email: email, UserID: userID, Source: source, Result: result})
+}
+
+// Inicio sesión con email y contraseña desde un origen (terminal, dirección
+// remota), vacío si no se conoce. Los fallos de cada cuenta y de cada origen
+// demoran los intentos siguientes y demasiados fallos seguidos bloquean la
+// cuenta por un tiempo. Si la cuenta tiene verificación en dos pasos devuelvo
+// ErrTwoFactorPending junto con el usuario: la sesión no queda iniciada hasta
+// confirmar el código con VerifySecondFactor
+func Login(email, password, source string) (*categories.User, error) {
+    now := time.Now()
+    key := textnorm.Key(email)
+    account := byAccount[key]
+
+    // Un bloqueo vencido deja la cuenta como nueva
+    if account != nil && !account.lockedUntil.IsZero() && !now.Before(account.lockedUntil) {
+        delete(byAccount, key)
+        account = nil
+    }
+    if err := checkThrottle(email, account, source, now); err != nil {
+        return nil, err
+    }
+
+    user, err := profiles.FindByEmail(email)
+    if err != nil || user.Password != password {
+        userID := 0
+        if err == nil {
+            userID = user.ID
+        }
+        record(email, userID, source, ResultInvalid, now)
+        failBoth(key, source, now)
+        // El mismo error para email inexistente o contraseña incorrecta
+        return nil, errors.ErrInvalidCredentials
+    }
+
+    // Recién con la contraseña correcta informo el estado de la cuenta, para no
+    // revelarlo a quien prueba contraseñas
+    if user.Disabled {
+        record(email, user.ID, source, ResultDisabled, now)
+        return nil, errors.ErrAccountDisabled
+    }
+    if user.MustResetPassword {
+        record(email, user.ID, source, ResultMustReset, now)
+        return nil, errors.ErrMustResetPassword
+    }
+
+    // Con verificación en dos pasos el inicio termina en VerifySecondFactor
+    if twofactor.IsEnabled(user.ID) {
+        pendingSecond[user.ID] = now.Add(SecondFactorWindow)
+        return user, errors.ErrTwoFactorPending
+    }
+    succeed(email, user.ID, source, now)
+    return user, nil
+}
+
+// Completo el inicio de sesión de una cuenta con verificación en dos pasos,
+// después de que Login aceptó su contraseña. Los códigos incorrectos cuentan
+// como fallos igual que una contraseña incorrecta
+func VerifySecondFactor(user *categories.User, code, source string) error {
+    now := time.Now()
+    if expires, exists := pendingSecond[user.ID]; !exists || !now.Before(expires) {
+        delete(pendingSecond, user.ID)
+        return errors.NewAppError("AUTH_013", "No hay un inicio de sesión pendiente", "Ingrese de nuevo su email y contraseña")
+    }
+    key := textnorm.Key(user.Email)
+    if err := checkThrottle(user.Email, byAccount[key], source, now); err != nil {
+        return err
+    }
+    if err := twofactor.Verify(user.ID, code); err != nil {
+        record(user.Email, user.ID, source, ResultBadCode, now)
+        failBoth(key, source, now)
+        return err
+    }
+    delete(pendingSecond, user.ID)
+    succeed(user.Email, user.ID, source, now)
+    return nil
+}
+
+// Rechazo el intento si la cuenta está bloqueada o si todavía no pasó la espera
+func checkThrottle(email string, account *counter, source string, now time.Time) error {
+    if account != nil && now.Before(account.lockedUntil) {
+        record(email, 0, source, ResultLocked, now)
+        return errors.NewAppError("AUTH_008", "Cuenta bloqueada temporalmente",
+            fmt.Sprintf("Intente de nuevo a las %s o contacte a un administrador", account.lockedUntil.Format("15:04")))
+    }
+    remaining := max(wait(account, FreeAttempts, now), wait(bySource[source], SourceFreeAttempts, now))
+    if remaining > 0 {
+        record(email, 0, source, ResultThrottled, now)
+        return errors.NewAppError("AUTH_007", "Demasiados intentos", fmt.Sprintf("Espere %d segundos", int(math.Ceil(remaining.Seconds()))))
+    }
+    return nil
+}
+
+// Sumo un fallo a la cuenta y al origen, y bloqueo la cuenta si llegó al límite.
+// Sin un origen conocido solo cuenta el fallo de la cuenta
+func failBoth(key, source string, now time.Time) {
+    if source != "" {
+        fail(bySource, source, now)
+    }
+    if c := fail(byAccount, key, now); c.failures >= LockoutThreshold {
+        c.lockedUntil = now.Add(LockoutDuration)
+    }
+}
+
+// Registro un inicio exitoso y borro los fallos acumulados
+func succeed(email string, userID int, source string, now time.Time) {
+    delete(byAccount, textnorm.Key(email))
+    delete(bySource, source)
+    record(email, userID, source, ResultSuccess, now)
+}
+
+// Borro los fallos de una cuenta, por ejemplo después de restablecer su contraseña
+func ClearFailures(email string) {
+    delete(byAccount, textnorm.Key(email))
+}
+
+// Olvido los fallos, el inicio pendiente y los intentos registrados de una
+// cuenta eliminada, para que otra con el mismo email no los herede
+func Forget(userID int, email string) {
+    key := textnorm.Key(email)
+    delete(byAccount, key)
+    delete(pendingSecond, userID)
+    kept := attempts[:0]
+    for _, a := range attempts {
+        if a.UserID != userID && textnorm.Key(a.Email) != key {
+            kept = append(kept, a)
+        }
+    }
+    attempts = kept
+}
+
+// Desbloqueo una cuenta antes de que venza su bloqueo
+func Unlock(email string) error {
+    key := textnorm.Key(email)
+    c, exists := byAccount[key]
+    if !exists || !time.Now().Before(c.lockedUntil) {
+        return errors.NewAppError("AUTH_009", "La cuenta no está bloqueada", email)
+    }
+    delete(byAccount, key)
+    return nil
+}
+
+// Obtengo las cuentas bloqueadas en este momento
+func Lockouts() []Lockout {
+    now := time.Now()
+    var list []Lockout
+    for key, c := range byAccount {
+        if now.Before(c.lockedUntil) {
+            list = append(list, Lockout{Email: key, Failures: c.failures, LockedUntil: c.lockedUntil})
+        }
+    }
+    sort.Slice(list, func(i, j int) bool {
+        return list[i].LockedUntil.Before(list[j].LockedUntil)
+    })
+    return list
+}
+
+// Obtengo el historial de intentos, lo más reciente primero
+func Attempts() []Attempt {
+    list := make([]Attempt, len(attempts))
+    for i, a := range attempts {
+        list[len(attempts)-1-i] = a
+    }
+    return list
+}
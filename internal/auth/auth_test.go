@@ -0,0 +1,259 @@
+package auth
+
+import (
+    "fmt"
+    "testing"
+    "time"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/textnorm"
+    "SDGEStreaming/internal/totp"
+    "SDGEStreaming/internal/twofactor"
+)
+
+// Obtengo el código de un error de la aplicación, o vacío si no es uno
+func errorCode(err error) string {
+    if appErr, ok := err.(*errors.AppError); ok {
+        return appErr.Code
+    }
+    return ""
+}
+
+// Cuentas registradas por las pruebas, para no repetir emails
+var testUsers = 0
+
+// Registro una cuenta nueva y devuelvo su email
+func newAccount(t *testing.T) string {
+    t.Helper()
+    testUsers++
+    email := fmt.Sprintf("acceso%d@prueba.com", testUsers)
+    if _, err := profiles.AddUser("Usuario Prueba", 30, email, "clave123", "Free", "Adulto", nil); err != nil {
+        t.Fatal(err)
+    }
+    return email
+}
+
+// Cambio los umbrales durante una prueba y los restauro al terminar
+func withLimits(t *testing.T, free, sourceFree int, base, maxDelay time.Duration, threshold int, lockout time.Duration) {
+    free0, sourceFree0, base0, max0, threshold0, lockout0 := FreeAttempts, SourceFreeAttempts, BaseDelay, MaxDelay, LockoutThreshold, LockoutDuration
+    t.Cleanup(func() {
+        FreeAttempts, SourceFreeAttempts, BaseDelay, MaxDelay = free0, sourceFree0, base0, max0
+        LockoutThreshold, LockoutDuration = threshold0, lockout0
+    })
+    FreeAttempts, SourceFreeAttempts = free, sourceFree
+    BaseDelay, MaxDelay = base, maxDelay
+    LockoutThreshold, LockoutDuration = threshold, lockout
+}
+
+// Verifico que la espera se duplique con cada fallo desde los intentos libres hasta el máximo
+func TestWaitBackoff(t *testing.T) {
+    withLimits(t, 3, 10, 2*time.Second, 5*time.Minute, 10, 30*time.Minute)
+    now := time.Now()
+    tests := []struct {
+        failures int
+        elapsed  time.Duration
+        want     time.Duration
+    }{
+        {0, 0, 0},
+        {2, 0, 0},
+        {3, 0, 2 * time.Second},
+        {4, 0, 4 * time.Second},
+        {5, 0, 8 * time.Second},
+        {5, 3 * time.Second, 5 * time.Second},
+        {9, 0, 128 * time.Second},
+        {10, 0, 256 * time.Second},
+        {11, 0, 5 * time.Minute},
+        {200, 0, 5 * time.Minute},
+        {4, 10 * time.Second, -6 * time.Second},
+    }
+    for _, tt := range tests {
+        c := &counter{failures: tt.failures, lastFailure: now.Add(-tt.elapsed)}
+        if got := wait(c, FreeAttempts, now); got != tt.want {
+            t.Errorf("wait(%d fallos, hace %s) = %s, quiero %s", tt.failures, tt.elapsed, got, tt.want)
+        }
+    }
+    if got := wait(nil, FreeAttempts, now); got != 0 {
+        t.Errorf("wait(sin fallos) = %s, quiero 0", got)
+    }
+}
+
+// Verifico que pasados los intentos libres se demore el siguiente, incluso con la contraseña correcta
+func TestLoginThrottle(t *testing.T) {
+    withLimits(t, 3, 100, time.Hour, time.Hour, 10, time.Hour)
+    email := newAccount(t)
+
+    steps := []struct {
+        password string
+        code     string
+    }{
+        {"mala", "AUTH_006"},
+        {"mala", "AUTH_006"},
+        {"mala", "AUTH_006"},
+        {"clave123", "AUTH_007"},
+        {"mala", "AUTH_007"},
+    }
+    for i, step := range steps {
+        if _, err := Login(email, step.password, "prueba-demora"); errorCode(err) != step.code {
+            t.Errorf("intento %d: error %v, quiero %s", i+1, err, step.code)
+        }
+    }
+}
+
+// Verifico el bloqueo al llegar al límite, el desbloqueo manual y el vencimiento
+func TestLoginLockout(t *testing.T) {
+    // Sin demora entre intentos para llegar al bloqueo de inmediato
+    withLimits(t, 0, 1000, time.Nanosecond, time.Nanosecond, 4, time.Hour)
+    email := newAccount(t)
+
+    for i := 1; i <= 4; i++ {
+        time.Sleep(time.Millisecond)
+        if _, err := Login(email, "mala", "prueba-bloqueo"); errorCode(err) != "AUTH_006" {
+            t.Fatalf("fallo %d: error %v, quiero AUTH_006", i, err)
+        }
+    }
+    time.Sleep(time.Millisecond)
+    if _, err := Login(email, "clave123", "prueba-bloqueo"); errorCode(err) != "AUTH_008" {
+        t.Fatalf("con la cuenta bloqueada: error %v, quiero AUTH_008", err)
+    }
+    locked := false
+    for _, l := range Lockouts() {
+        if l.Email == email && l.Failures == 4 {
+            locked = true
+        }
+    }
+    if !locked {
+        t.Errorf("Lockouts() = %v, no incluye %s con 4 fallos", Lockouts(), email)
+    }
+
+    if err := Unlock(email); err != nil {
+        t.Fatal(err)
+    }
+    if err := Unlock(email); errorCode(err) != "AUTH_009" {
+        t.Errorf("Unlock de una cuenta sin bloqueo: error %v, quiero AUTH_009", err)
+    }
+    if _, err := Login(email, "clave123", "prueba-bloqueo"); err != nil {
+        t.Fatalf("después de desbloquear: %v", err)
+    }
+
+    // Un bloqueo vencido deja la cuenta sin fallos
+    LockoutDuration = 5 * time.Millisecond
+    for i := 1; i <= 4; i++ {
+        time.Sleep(time.Millisecond)
+        Login(email, "mala", "prueba-bloqueo")
+    }
+    time.Sleep(10 * time.Millisecond)
+    if _, err := Login(email, "clave123", "prueba-bloqueo"); err != nil {
+        t.Errorf("después de vencer el bloqueo: %v", err)
+    }
+}
+
+// Verifico que los fallos de un mismo origen demoren a cualquier cuenta desde ese origen
+func TestLoginSourceThrottle(t *testing.T) {
+    withLimits(t, 100, 2, time.Hour, time.Hour, 100, time.Hour)
+    first, second := newAccount(t), newAccount(t)
+
+    Login(first, "mala", "prueba-origen")
+    Login(second, "mala", "prueba-origen")
+    if _, err := Login(second, "clave123", "prueba-origen"); errorCode(err) != "AUTH_007" {
+        t.Errorf("desde el origen con fallos: error %v, quiero AUTH_007", err)
+    }
+    if _, err := Login(second, "clave123", "otro-origen"); err != nil {
+        t.Errorf("desde otro origen: %v", err)
+    }
+}
+
+// Verifico que con verificación en dos pasos la contraseña sola no inicie la
+// sesión ni borre los fallos, y que el código sólo sirva después de la contraseña
+func TestLoginTwoFactorPending(t *testing.T) {
+    withLimits(t, 100, 100, time.Hour, time.Hour, 100, time.Hour)
+    email := newAccount(t)
+    user, _ := profiles.FindByEmail(email)
+    secret, _, err := twofactor.BeginEnrollment(user.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    now := time.Now()
+    code, _ := totp.Code(secret, now)
+    if _, err := twofactor.ConfirmEnrollment(user.ID, code); err != nil {
+        t.Fatal(err)
+    }
+    next, _ := totp.Code(secret, now.Add(totp.Period*time.Second))
+    old, _ := totp.Code(secret, now.Add(-time.Hour))
+
+    if err := VerifySecondFactor(user, next, "prueba-dos-pasos"); errorCode(err) != "AUTH_013" {
+        t.Errorf("código sin contraseña previa: error %v, quiero AUTH_013", err)
+    }
+    Login(email, "mala", "prueba-dos-pasos")
+    got, err := Login(email, "clave123", "prueba-dos-pasos")
+    if err != errors.ErrTwoFactorPending || got == nil || got.ID != user.ID {
+        t.Fatalf("Login con dos pasos = %v, %v; quiero el usuario y AUTH_012", got, err)
+    }
+    if c := byAccount[textnorm.Key(email)]; c == nil || c.failures != 1 {
+        t.Errorf("la contraseña sola borró los fallos de la cuenta: %+v", c)
+    }
+    for _, a := range Attempts() {
+        if a.Email == email && a.Result == ResultSuccess {
+            t.Errorf("la contraseña sola registró un inicio correcto: %+v", a)
+        }
+    }
+
+    if err := VerifySecondFactor(user, old, "prueba-dos-pasos"); err == nil {
+        t.Error("se aceptó un código vencido")
+    }
+    if err := VerifySecondFactor(user, next, "prueba-dos-pasos"); err != nil {
+        t.Fatalf("código correcto después de la contraseña: %v", err)
+    }
+    if c := byAccount[textnorm.Key(email)]; c != nil {
+        t.Errorf("el inicio completo no borró los fallos: %+v", c)
+    }
+    if err := VerifySecondFactor(user, next, "prueba-dos-pasos"); errorCode(err) != "AUTH_013" {
+        t.Errorf("segundo código para el mismo inicio: error %v, quiero AUTH_013", err)
+    }
+}
+
+// Verifico que sin un origen conocido los fallos no demoren a otras cuentas
+func TestLoginUnknownSource(t *testing.T) {
+    withLimits(t, 100, 1, time.Hour, time.Hour, 100, time.Hour)
+    first, second := newAccount(t), newAccount(t)
+
+    Login(first, "mala", "")
+    Login(first, "mala", "")
+    if _, err := Login(second, "clave123", ""); err != nil {
+        t.Errorf("otra cuenta sin origen conocido: %v", err)
+    }
+}
+
+// Verifico que los contadores de cuentas, existan o no, se olviden al vencer y
+// no pasen del máximo, sin soltar antes un bloqueo vigente
+func TestTrackedAccountsBounded(t *testing.T) {
+    withLimits(t, 100, 1000, time.Hour, time.Hour, 2, time.Hour)
+    maxTracked, ttl := MaxTracked, FailureTTL
+    t.Cleanup(func() { MaxTracked, FailureTTL = maxTracked, ttl })
+    for key := range byAccount {
+        delete(byAccount, key)
+    }
+    MaxTracked = 5
+
+    locked := newAccount(t)
+    Login(locked, "mala", "")
+    Login(locked, "mala", "")
+    for i := 0; i < 20; i++ {
+        Login(fmt.Sprintf("inexistente%d@prueba.com", i), "mala", "")
+        if len(byAccount) > MaxTracked {
+            t.Fatalf("después de %d emails hay %d contadores, máximo %d", i+1, len(byAccount), MaxTracked)
+        }
+    }
+    if c := byAccount[textnorm.Key(locked)]; c == nil || !time.Now().Before(c.lockedUntil) {
+        t.Errorf("se descartó el bloqueo vigente de %s", locked)
+    }
+
+    // Los contadores sin fallos recientes se olvidan al hacer lugar
+    FailureTTL = time.Millisecond
+    time.Sleep(2 * time.Millisecond)
+    Login("otro-inexistente@prueba.com", "mala", "")
+    for key, c := range byAccount {
+        if key != textnorm.Key(locked) && key != textnorm.Key("otro-inexistente@prueba.com") {
+            t.Errorf("quedó el contador vencido de %s: %+v", key, c)
+        }
+    }
+}
@@ -0,0 +1,451 @@
+package catalog
+
+import (
+    "cmp"
+    "encoding/base64"
+    "encoding/json"
+    "sort"
+    "strings"
+    "SDGEStreaming/internal/audio"
+    "SDGEStreaming/internal/audiovisual"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/contentclass"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/genres"
+    "SDGEStreaming/internal/search"
+)
+
+// Claves de ordenamiento soportadas
+const (
+    SortTitle    = "title"
+    SortYear     = "year"
+    SortRating   = "rating"
+    SortDuration = "duration"
+)
+
+// Filtros de disponibilidad
+const (
+    AvailableOnly   = ""          // por defecto, como ListAll
+    AvailabilityAll = "todos"
+    UnavailableOnly = "no disponible"
+)
+
+// Tamaño de página cuando no se indica uno
+const DefaultLimit = 10
+
+// Vista unificada de un contenido audiovisual o de audio
+type Item struct {
+    Ref           categories.ContentRef
+    Title         string
+    Type          string
+    Genre         string   // género principal
+    Genres        []string // todos los géneros, el principal primero
+    Tags          []string
+    Duration      int
+    AgeRating     string
+    ReleaseYear   int    // 0 para contenido de audio, que no registra año
+    Creator       string // director o artista
+    AverageRating float64
+    IsAvailable   bool
+}
+
+// Consulta combinable sobre el catálogo; los campos vacíos no filtran
+type Query struct {
+    Kind         string // categories.KindAudiovisual, categories.KindAudio o vacío para ambos
+    Type         string
+    Genre        string // coincide con cualquiera de los géneros del contenido
+    Tag          string
+    AgeRating    string
+    YearFrom     int
+    YearTo       int
+    MinRating    float64
+    MinDuration  int
+    MaxDuration  int
+    Availability string
+    ViewerAge    int // si es mayor a 0, oculto lo que no corresponde a esa edad
+    Text         string      // palabras que deben aparecer en el título
+    Conditions   []Condition // condiciones adicionales, por ejemplo desde Parse
+    SortBy       string
+    Descending   bool
+    Limit        int
+    Cursor       string
+}
+
+// Página de resultados de una consulta
+type Page struct {
+    Items      []Item
+    Total      int    // resultados que cumplen los filtros, en todas las páginas
+    NextCursor string // vacío si no hay más páginas
+}
+
+// Posición codificada en un cursor: el último elemento entregado y el orden usado
+type cursor struct {
+    SortBy     string  `json:"s"`
+    Descending bool    `json:"d"`
+    Kind       string  `json:"k"`
+    ID         int     `json:"i"`
+    Title      string  `json:"t"`
+    Year       int     `json:"y"`
+    Rating     float64 `json:"r"`
+    Duration   int     `json:"m"`
+}
+
+// Convierto un contenido audiovisual a la vista unificada
+func fromAudiovisual(c audiovisual.AudiovisualContent) Item {
+    return Item{
+        Ref:           categories.ContentRef{Kind: categories.KindAudiovisual, ID: c.ID},
+        Title:         c.Title,
+        Type:          c.Type,
+        Genre:         c.Genre,
+        Genres:        c.Genres,
+        Tags:          c.Tags,
+        Duration:      c.Duration,
+        AgeRating:     c.AgeRating,
+        ReleaseYear:   c.ReleaseYear,
+        Creator:       c.Director,
+        AverageRating: c.AverageRating,
+        IsAvailable:   c.IsAvailable,
+    }
+}
+
+// Convierto un contenido de audio a la vista unificada
+func fromAudio(c audio.AudioContent) Item {
+    return Item{
+        Ref:           categories.ContentRef{Kind: categories.KindAudio, ID: c.ID},
+        Title:         c.Title,
+        Type:          c.Type,
+        Genre:         c.Genre,
+        Genres:        c.Genres,
+        Tags:          c.Tags,
+        Duration:      c.Duration,
+        AgeRating:     c.AgeRating,
+        Creator:       c.Artist,
+        AverageRating: c.AverageRating,
+        IsAvailable:   c.IsAvailable,
+    }
+}
+
+// Verifico si un contenido tiene un género, principal o secundario, o uno de
+// sus subgéneros; el género buscado puede ser un alias
+func (item Item) HasGenre(genre string) bool {
+    for _, g := range item.Genres {
+        if search.Fold(g) == search.Fold(genre) || genres.IsWithin(g, genre) {
+            return true
+        }
+    }
+    return false
+}
+
+// Verifico si un contenido tiene una etiqueta
+func (item Item) HasTag(tag string) bool {
+    for _, t := range item.Tags {
+        if search.Fold(t) == search.Fold(tag) {
+            return true
+        }
+    }
+    return false
+}
+
+// Reúno todo el catálogo en la vista unificada
+func All() []Item {
+    var items []Item
+    for _, c := range audiovisual.ListAllIncludingUnavailable() {
+        items = append(items, fromAudiovisual(c))
+    }
+    for _, c := range audio.ListAllIncludingUnavailable() {
+        items = append(items, fromAudio(c))
+    }
+    return items
+}
+
+// Obtengo un contenido de cualquier tipo por su referencia
+func Get(ref categories.ContentRef) (Item, error) {
+    switch ref.Kind {
+    case categories.KindAudiovisual:
+        c, err := audiovisual.GetByID(ref.ID)
+        if err != nil {
+            return Item{}, err
+        }
+        return fromAudiovisual(*c), nil
+    case categories.KindAudio:
+        c, err := audio.GetByID(ref.ID)
+        if err != nil {
+            return Item{}, err
+        }
+        return fromAudio(*c), nil
+    }
+    return Item{}, errors.ErrContentNotFound
+}
+
+// Filtro de una consulta que se puede resolver con un índice secundario; los
+// dos catálogos nombran igual sus índices
+type indexFilter struct {
+    index string
+    match func(key string) bool
+}
+
+// Reúno los filtros de la consulta que tienen índice: los campos y las
+// condiciones de igualdad sobre tipo, género, etiqueta y clasificación
+func (q Query) indexFilters() []indexFilter {
+    exactly := func(value string) func(string) bool {
+        return func(key string) bool { return key == value }
+    }
+    folded := func(value string) func(string) bool {
+        return func(key string) bool { return search.Fold(key) == search.Fold(value) }
+    }
+    // Igual que HasGenre: el género, sus alias o cualquiera de sus subgéneros
+    genre := func(value string) func(string) bool {
+        return func(key string) bool { return search.Fold(key) == search.Fold(value) || genres.IsWithin(key, value) }
+    }
+
+    var filters []indexFilter
+    if q.Type != "" {
+        filters = append(filters, indexFilter{audiovisual.IndexType, exactly(q.Type)})
+    }
+    if q.Genre != "" {
+        filters = append(filters, indexFilter{audiovisual.IndexGenre, genre(q.Genre)})
+    }
+    if q.Tag != "" {
+        filters = append(filters, indexFilter{audiovisual.IndexTag, folded(q.Tag)})
+    }
+    if q.AgeRating != "" {
+        filters = append(filters, indexFilter{audiovisual.IndexAgeRating, exactly(q.AgeRating)})
+    }
+    for _, c := range q.Conditions {
+        if c.Op != OpEqual {
+            continue
+        }
+        switch c.Field {
+        case "type":
+            filters = append(filters, indexFilter{audiovisual.IndexType, folded(c.Text)})
+        case "genre":
+            filters = append(filters, indexFilter{audiovisual.IndexGenre, genre(c.Text)})
+        case "tag":
+            filters = append(filters, indexFilter{audiovisual.IndexTag, folded(c.Text)})
+        case "age":
+            filters = append(filters, indexFilter{audiovisual.IndexAgeRating, folded(c.Text)})
+        }
+    }
+    return filters
+}
+
+// Elijo el filtro que deja menos IDs; sin filtros con índice hay que recorrer todo
+func narrowest(filters []indexFilter, lookup func(index string, match func(string) bool) []int) ([]int, bool) {
+    var best []int
+    found := false
+    for _, f := range filters {
+        if ids := lookup(f.index, f.match); !found || len(ids) < len(best) {
+            best, found = ids, true
+        }
+    }
+    return best, found
+}
+
+// Obtengo los contenidos que pueden cumplir la consulta, acotados por el índice
+// más selectivo de cada catálogo. El resto de los filtros los revisa Matches
+func candidates(q Query) []Item {
+    filters := q.indexFilters()
+    var items []Item
+    if q.Kind == "" || q.Kind == categories.KindAudiovisual {
+        if ids, narrowed := narrowest(filters, audiovisual.IDsWhere); narrowed {
+            for _, id := range ids {
+                if c, err := audiovisual.GetByID(id); err == nil {
+                    items = append(items, fromAudiovisual(*c))
+                }
+            }
+        } else {
+            for _, c := range audiovisual.ListAllIncludingUnavailable() {
+                items = append(items, fromAudiovisual(c))
+            }
+        }
+    }
+    if q.Kind == "" || q.Kind == categories.KindAudio {
+        if ids, narrowed := narrowest(filters, audio.IDsWhere); narrowed {
+            for _, id := range ids {
+                if c, err := audio.GetByID(id); err == nil {
+                    items = append(items, fromAudio(*c))
+                }
+            }
+        } else {
+            for _, c := range audio.ListAllIncludingUnavailable() {
+                items = append(items, fromAudio(c))
+            }
+        }
+    }
+    return items
+}
+
+// Verifico si un contenido cumple todos los filtros de la consulta
+func (q Query) Matches(item Item) bool {
+    switch q.Availability {
+    case AvailableOnly:
+        if !item.IsAvailable {
+            return false
+        }
+    case UnavailableOnly:
+        if item.IsAvailable {
+            return false
+        }
+    }
+
+    if q.Kind != "" && item.Ref.Kind != q.Kind {
+        return false
+    }
+    if q.Type != "" && item.Type != q.Type {
+        return false
+    }
+    if q.Genre != "" && !item.HasGenre(q.Genre) {
+        return false
+    }
+    if q.Tag != "" && !item.HasTag(q.Tag) {
+        return false
+    }
+    if q.AgeRating != "" && item.AgeRating != q.AgeRating {
+        return false
+    }
+    if q.YearFrom > 0 && item.ReleaseYear < q.YearFrom {
+        return false
+    }
+    if q.YearTo > 0 && (item.ReleaseYear == 0 || item.ReleaseYear > q.YearTo) {
+        return false
+    }
+    if q.MinRating > 0 && item.AverageRating < q.MinRating {
+        return false
+    }
+    if q.MinDuration > 0 && item.Duration < q.MinDuration {
+        return false
+    }
+    if q.MaxDuration > 0 && item.Duration > q.MaxDuration {
+        return false
+    }
+    if q.ViewerAge > 0 && !contentclass.CanAccessContent(q.ViewerAge, item.AgeRating) {
+        return false
+    }
+    for _, c := range q.Conditions {
+        if !c.Matches(item) {
+            return false
+        }
+    }
+    if q.Text != "" {
+        titleTerms := make(map[string]bool)
+        for _, term := range search.Tokenize(item.Title) {
+            titleTerms[term] = true
+        }
+        for _, term := range search.Tokenize(q.Text) {
+            if !titleTerms[term] {
+                return false
+            }
+        }
+    }
+    return true
+}
+
+// Comparo dos contenidos según la clave de orden; el ID desempata para que el orden sea total
+func (q Query) less(a, b Item) bool {
+    var order int
+    switch q.SortBy {
+    case SortYear:
+        order = cmp.Compare(a.ReleaseYear, b.ReleaseYear)
+    case SortRating:
+        order = cmp.Compare(a.AverageRating, b.AverageRating)
+    case SortDuration:
+        order = cmp.Compare(a.Duration, b.Duration)
+    default:
+        order = strings.Compare(search.Fold(a.Title), search.Fold(b.Title))
+    }
+    if q.Descending {
+        order = -order
+    }
+    if order != 0 {
+        return order < 0
+    }
+    if a.Ref.Kind != b.Ref.Kind {
+        return a.Ref.Kind < b.Ref.Kind
+    }
+    return a.Ref.ID < b.Ref.ID
+}
+
+// Valido la clave de orden de la consulta
+func validSort(sortBy string) bool {
+    switch sortBy {
+    case "", SortTitle, SortYear, SortRating, SortDuration:
+        return true
+    }
+    return false
+}
+
+// Ejecuto una consulta y devuelvo la página pedida
+func Find(q Query) (Page, error) {
+    if !validSort(q.SortBy) {
+        return Page{}, errors.NewAppError("QUERY_002", "Clave de orden inválida", q.SortBy)
+    }
+    if q.Limit <= 0 {
+        q.Limit = DefaultLimit
+    }
+
+    var matched []Item
+    for _, item := range candidates(q) {
+        if q.Matches(item) {
+            matched = append(matched, item)
+        }
+    }
+    sort.Slice(matched, func(i, j int) bool {
+        return q.less(matched[i], matched[j])
+    })
+
+    start := 0
+    if q.Cursor != "" {
+        last, err := decodeCursor(q)
+        if err != nil {
+            return Page{}, err
+        }
+        // La página sigue después del último elemento entregado, aunque ya no exista
+        start = sort.Search(len(matched), func(i int) bool {
+            return q.less(last, matched[i])
+        })
+    }
+
+    end := min(start+q.Limit, len(matched))
+    page := Page{Items: matched[start:end], Total: len(matched)}
+    if end < len(matched) {
+        page.NextCursor = encodeCursor(q, matched[end-1])
+    }
+    return page, nil
+}
+
+// Codifico la posición del último elemento de una página
+func encodeCursor(q Query, item Item) string {
+    data, _ := json.Marshal(cursor{
+        SortBy:     q.SortBy,
+        Descending: q.Descending,
+        Kind:       item.Ref.Kind,
+        ID:         item.Ref.ID,
+        Title:      item.Title,
+        Year:       item.ReleaseYear,
+        Rating:     item.AverageRating,
+        Duration:   item.Duration,
+    })
+    return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decodifico un cursor y verifico que corresponda al mismo orden de la consulta
+func decodeCursor(q Query) (Item, error) {
+    data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+    if err != nil {
+        return Item{}, errors.ErrInvalidCursor
+    }
+    var c cursor
+    if err := json.Unmarshal(data, &c); err != nil {
+        return Item{}, errors.ErrInvalidCursor
+    }
+    if c.SortBy != q.SortBy || c.Descending != q.Descending {
+        return Item{}, errors.NewAppError("QUERY_001", "Cursor de paginación inválido", "el orden de la consulta cambió")
+    }
+    return Item{
+        Ref:           categories.ContentRef{Kind: c.Kind, ID: c.ID},
+        Title:         c.Title,
+        ReleaseYear:   c.Year,
+        AverageRating: c.Rating,
+        Duration:      c.Duration,
+    }, nil
+}
@@ -0,0 +1,206 @@
+package catalog
+
+import (
+    "sort"
+    "strings"
+    "testing"
+    "SDGEStreaming/internal/audiovisual"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+)
+
+// Obtengo el código de un error de la aplicación, o vacío si no es uno
+func errorCode(err error) string {
+    if appErr, ok := err.(*errors.AppError); ok {
+        return appErr.Code
+    }
+    return ""
+}
+
+// Recorro todas las páginas de una consulta siguiendo los cursores
+func walk(t *testing.T, q Query) []Item {
+    t.Helper()
+    var items []Item
+    for pages := 0; ; pages++ {
+        if pages > 100 {
+            t.Fatalf("la paginación de %+v no termina", q)
+        }
+        page, err := Find(q)
+        if err != nil {
+            t.Fatalf("Find(%+v): %v", q, err)
+        }
+        items = append(items, page.Items...)
+        if page.NextCursor == "" {
+            return items
+        }
+        q.Cursor = page.NextCursor
+    }
+}
+
+// Verifico que recorrer por páginas entregue lo mismo que una sola página, en cada orden
+func TestCursorPagination(t *testing.T) {
+    tests := []struct {
+        sortBy     string
+        descending bool
+        limit      int
+    }{
+        {SortTitle, false, 1},
+        {SortTitle, true, 2},
+        {SortYear, false, 2},
+        {SortYear, true, 4},
+        {SortRating, false, 3},
+        {SortDuration, true, 2},
+        {"", false, 5},
+    }
+    for _, tt := range tests {
+        q := Query{SortBy: tt.sortBy, Descending: tt.descending, Availability: AvailabilityAll}
+        full, err := Find(Query{SortBy: tt.sortBy, Descending: tt.descending, Availability: AvailabilityAll, Limit: 1000})
+        if err != nil {
+            t.Fatal(err)
+        }
+        q.Limit = tt.limit
+        paged := walk(t, q)
+        if len(paged) != len(full.Items) {
+            t.Errorf("orden %q desc=%v límite %d: %d contenidos por páginas, quiero %d", tt.sortBy, tt.descending, tt.limit, len(paged), len(full.Items))
+            continue
+        }
+        for i := range paged {
+            if paged[i].Ref != full.Items[i].Ref {
+                t.Errorf("orden %q desc=%v límite %d: posición %d es %v, quiero %v", tt.sortBy, tt.descending, tt.limit, i, paged[i].Ref, full.Items[i].Ref)
+                break
+            }
+        }
+    }
+}
+
+// Verifico que un cursor siga siendo válido cuando se agrega contenido entre páginas:
+// lo nuevo antes del cursor no se repite ni corre la página, lo nuevo después aparece
+func TestCursorStableAcrossInserts(t *testing.T) {
+    q := Query{Kind: categories.KindAudiovisual, SortBy: SortTitle, Limit: 2}
+    first, err := Find(q)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if first.NextCursor == "" {
+        t.Fatalf("la primera página %v no tiene cursor", first.Items)
+    }
+    last := first.Items[len(first.Items)-1]
+
+    audiovisual.AddContent("Aaa Antes del Cursor", "Película", "Drama", 90, "Adulto", "", 2020, "")
+    audiovisual.AddContent("Zzz Después del Cursor", "Película", "Drama", 90, "Adulto", "", 2020, "")
+
+    q.Cursor = first.NextCursor
+    rest := walk(t, q)
+    seen := make(map[string]bool)
+    for _, item := range first.Items {
+        seen[item.Title] = true
+    }
+    for _, item := range rest {
+        if seen[item.Title] {
+            t.Errorf("%q se repite después del cursor", item.Title)
+        }
+        if q.less(item, last) {
+            t.Errorf("%q quedó antes del último contenido entregado %q", item.Title, last.Title)
+        }
+        seen[item.Title] = true
+    }
+    if containsTitle(rest, "Aaa Antes del Cursor") {
+        t.Errorf("el contenido agregado antes del cursor apareció en las páginas siguientes")
+    }
+    if !containsTitle(rest, "Zzz Después del Cursor") {
+        t.Errorf("el contenido agregado después del cursor no apareció: %v", rest)
+    }
+}
+
+// Verifico si una lista de contenidos incluye un título
+func containsTitle(items []Item, title string) bool {
+    for _, item := range items {
+        if item.Title == title {
+            return true
+        }
+    }
+    return false
+}
+
+// Verifico los errores de cursores y órdenes inválidos
+func TestFindErrors(t *testing.T) {
+    page, err := Find(Query{SortBy: SortYear, Limit: 1})
+    if err != nil || page.NextCursor == "" {
+        t.Fatalf("Find: %v, cursor %q", err, page.NextCursor)
+    }
+    tests := []struct {
+        name string
+        q    Query
+        code string
+    }{
+        {"orden desconocido", Query{SortBy: "popularidad"}, "QUERY_002"},
+        {"cursor que no es base64", Query{SortBy: SortYear, Cursor: "%%%"}, "QUERY_001"},
+        {"cursor que no es JSON", Query{SortBy: SortYear, Cursor: "bm8tanNvbg"}, "QUERY_001"},
+        {"cursor de otro orden", Query{SortBy: SortTitle, Cursor: page.NextCursor}, "QUERY_001"},
+        {"cursor de otra dirección", Query{SortBy: SortYear, Descending: true, Cursor: page.NextCursor}, "QUERY_001"},
+    }
+    for _, tt := range tests {
+        if _, err := Find(tt.q); errorCode(err) != tt.code {
+            t.Errorf("%s: error %v, quiero %s", tt.name, err, tt.code)
+        }
+    }
+}
+
+// Verifico que acotar por índices dé lo mismo que revisar todo el catálogo,
+// también después de modificar y borrar contenidos
+func TestFindMatchesFullScan(t *testing.T) {
+    audiovisual.AddContent("Acotado Uno", "Serie", "Comedia", 30, "Infantil", "", 2019, "")
+    audiovisual.AddContent("Acotado Dos", "Película", "Drama", 110, "Adulto", "", 2021, "")
+    list := audiovisual.ListAllIncludingUnavailable()
+    one, two := list[len(list)-2].ID, list[len(list)-1].ID
+    audiovisual.AddTag(one, "Para Reír")
+    audiovisual.SetAvailability(two, false)
+
+    queries := []Query{
+        {},
+        {Type: "Serie"},
+        {Genre: "comedia"},
+        {Genre: "Música"}, // incluye subgéneros como Clásica
+        {Tag: "para reir", Availability: AvailabilityAll},
+        {AgeRating: "Adulto", Availability: UnavailableOnly},
+        {Kind: categories.KindAudio, Type: "Podcast"},
+        {Type: "Serie", AgeRating: "Infantil", Genre: "Comedia"},
+        {Conditions: []Condition{{Field: "type", Op: OpEqual, Text: "película"}}, Availability: AvailabilityAll},
+        {Conditions: []Condition{{Field: "genre", Op: OpNotEqual, Text: "Drama"}}},
+        {Type: "Inexistente"},
+    }
+    check := func(stage string) {
+        for _, q := range queries {
+            var want []string
+            for _, item := range All() {
+                if q.Matches(item) {
+                    want = append(want, item.Title)
+                }
+            }
+            q.Limit = 1000
+            page, err := Find(q)
+            if err != nil {
+                t.Fatalf("%s: Find(%+v): %v", stage, q, err)
+            }
+            var got []string
+            for _, item := range page.Items {
+                got = append(got, item.Title)
+            }
+            sort.Strings(want)
+            sort.Strings(got)
+            if strings.Join(got, "|") != strings.Join(want, "|") {
+                t.Errorf("%s: Find(%+v) = %v, quiero %v", stage, q, got, want)
+            }
+        }
+    }
+
+    check("al agregar")
+    if err := audiovisual.UpdateContent(one, "Acotado Uno", "Película", "Drama", 30, "Adulto", "", 2019, ""); err != nil {
+        t.Fatal(err)
+    }
+    check("al modificar")
+    if err := audiovisual.DeleteContent(two); err != nil {
+        t.Fatal(err)
+    }
+    check("al borrar")
+}
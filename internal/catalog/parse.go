@@ -0,0 +1,256 @@
+package catalog
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "unicode"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/search"
+)
+
+// Operadores de comparación del lenguaje de consultas
+const (
+    OpEqual        = "="
+    OpNotEqual     = "!="
+    OpGreater      = ">"
+    OpGreaterEqual = ">="
+    OpLess         = "<"
+    OpLessEqual    = "<="
+)
+
+// Condición sobre un campo del contenido, producida por el lenguaje de consultas
+type Condition struct {
+    Field  string
+    Op     string
+    Text   string  // valor para campos de texto
+    Number float64 // valor para campos numéricos
+}
+
+// Campos que acepta el lenguaje, con sus sinónimos en español
+var fieldAliases = map[string]string{
+    "title": "title", "titulo": "title",
+    "type": "type", "tipo": "type",
+    "genre": "genre", "genero": "genre",
+    "tag": "tag", "etiqueta": "tag",
+    "age": "age", "clasificacion": "age",
+    "year": "year", "anio": "year", "ano": "year",
+    "rating": "rating",
+    "duration": "duration", "duracion": "duration",
+    "kind": "kind", "catalogo": "kind",
+    "available": "available", "disponible": "available",
+    "sort": "sort", "orden": "sort",
+    "limit": "limit", "limite": "limit",
+}
+
+// Campos numéricos que admiten comparaciones de orden
+var numericFields = map[string]bool{"year": true, "rating": true, "duration": true}
+
+// Error de sintaxis con la posición donde se detectó
+func syntaxError(pos int, format string, args ...interface{}) error {
+    return errors.NewAppError("QUERY_003", "Error de sintaxis en la consulta",
+        fmt.Sprintf("posición %d: %s", pos+1, fmt.Sprintf(format, args...)))
+}
+
+// Fragmento de la consulta con su posición de inicio
+type token struct {
+    text string
+    pos  int
+}
+
+// Divido la consulta en fragmentos separados por espacios, respetando comillas
+func tokenize(expr string) ([]token, error) {
+    var tokens []token
+    runes := []rune(expr)
+    for i := 0; i < len(runes); {
+        if unicode.IsSpace(runes[i]) {
+            i++
+            continue
+        }
+        start := i
+        var b strings.Builder
+        for i < len(runes) && !unicode.IsSpace(runes[i]) {
+            if runes[i] == '"' {
+                quote := i
+                i++
+                for i < len(runes) && runes[i] != '"' {
+                    b.WriteRune(runes[i])
+                    i++
+                }
+                if i == len(runes) {
+                    return nil, syntaxError(quote, "comillas sin cerrar")
+                }
+                i++
+                continue
+            }
+            b.WriteRune(runes[i])
+            i++
+        }
+        tokens = append(tokens, token{text: b.String(), pos: start})
+    }
+    return tokens, nil
+}
+
+// Separo un fragmento en campo, operador y valor; ok es falso si es texto libre
+func splitCondition(text string) (field, op, value string, ok bool) {
+    for i, r := range text {
+        switch r {
+        case ':', '=':
+            return text[:i], OpEqual, text[i+1:], true
+        case '!':
+            if strings.HasPrefix(text[i:], "!=") {
+                return text[:i], OpNotEqual, text[i+2:], true
+            }
+        case '>', '<':
+            op := string(r)
+            value := text[i+1:]
+            if strings.HasPrefix(value, "=") {
+                op += "="
+                value = value[1:]
+            }
+            return text[:i], op, value, true
+        }
+    }
+    return "", "", "", false
+}
+
+// Convierto una expresión como `genre:Comedia year>=2020 sort:-rating` en una consulta
+func Parse(expr string) (Query, error) {
+    var q Query
+    tokens, err := tokenize(expr)
+    if err != nil {
+        return q, err
+    }
+
+    var text []string
+    for _, tok := range tokens {
+        rawField, op, value, isCondition := splitCondition(tok.text)
+        if !isCondition {
+            text = append(text, tok.text)
+            continue
+        }
+        if rawField == "" {
+            return q, syntaxError(tok.pos, "falta el campo en %q", tok.text)
+        }
+        field, known := fieldAliases[search.Fold(rawField)]
+        if !known {
+            return q, syntaxError(tok.pos, "campo desconocido %q", rawField)
+        }
+        if value == "" {
+            return q, syntaxError(tok.pos, "falta el valor de %q", rawField)
+        }
+        if !numericFields[field] && op != OpEqual && op != OpNotEqual {
+            return q, syntaxError(tok.pos, "el campo %q no admite %q", rawField, op)
+        }
+
+        switch field {
+        case "kind":
+            if op != OpEqual {
+                return q, syntaxError(tok.pos, "el campo %q solo admite ':'", rawField)
+            }
+            switch search.Fold(value) {
+            case categories.KindAudiovisual:
+                q.Kind = categories.KindAudiovisual
+            case categories.KindAudio:
+                q.Kind = categories.KindAudio
+            default:
+                return q, syntaxError(tok.pos, "catálogo %q inválido (use audiovisual o audio)", value)
+            }
+        case "available":
+            if op != OpEqual {
+                return q, syntaxError(tok.pos, "el campo %q solo admite ':'", rawField)
+            }
+            switch search.Fold(value) {
+            case "si", "true":
+                q.Availability = AvailableOnly
+            case "no", "false":
+                q.Availability = UnavailableOnly
+            case "todos", "all":
+                q.Availability = AvailabilityAll
+            default:
+                return q, syntaxError(tok.pos, "disponibilidad %q inválida (use si, no o todos)", value)
+            }
+        case "sort":
+            if op != OpEqual {
+                return q, syntaxError(tok.pos, "el campo %q solo admite ':'", rawField)
+            }
+            q.Descending = strings.HasPrefix(value, "-")
+            key := strings.TrimPrefix(value, "-")
+            if alias, ok := fieldAliases[search.Fold(key)]; ok {
+                key = alias
+            }
+            if !validSort(key) {
+                return q, syntaxError(tok.pos, "no se puede ordenar por %q", value)
+            }
+            q.SortBy = key
+        case "limit":
+            limit, err := strconv.Atoi(value)
+            if op != OpEqual || err != nil || limit <= 0 {
+                return q, syntaxError(tok.pos, "límite %q inválido", value)
+            }
+            q.Limit = limit
+        default:
+            condition := Condition{Field: field, Op: op, Text: value}
+            if numericFields[field] {
+                number, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+                if err != nil {
+                    return q, syntaxError(tok.pos, "%q no es un número", value)
+                }
+                condition.Number = number
+            }
+            q.Conditions = append(q.Conditions, condition)
+        }
+    }
+
+    q.Text = strings.Join(text, " ")
+    return q, nil
+}
+
+// Evalúo una condición contra un contenido
+func (c Condition) Matches(item Item) bool {
+    if numericFields[c.Field] {
+        var value float64
+        switch c.Field {
+        case "year":
+            value = float64(item.ReleaseYear)
+        case "rating":
+            value = item.AverageRating
+        case "duration":
+            value = float64(item.Duration)
+        }
+        switch c.Op {
+        case OpEqual:
+            return value == c.Number
+        case OpNotEqual:
+            return value != c.Number
+        case OpGreater:
+            return value > c.Number
+        case OpGreaterEqual:
+            return value >= c.Number
+        case OpLess:
+            return value < c.Number
+        case OpLessEqual:
+            return value <= c.Number
+        }
+        return false
+    }
+
+    var value string
+    switch c.Field {
+    case "title":
+        // En el título basta con que aparezca el texto buscado
+        contains := strings.Contains(search.Fold(item.Title), search.Fold(c.Text))
+        return contains == (c.Op == OpEqual)
+    case "type":
+        value = item.Type
+    case "genre":
+        return item.HasGenre(c.Text) == (c.Op == OpEqual)
+    case "tag":
+        return item.HasTag(c.Text) == (c.Op == OpEqual)
+    case "age":
+        value = item.AgeRating
+    }
+    equal := search.Fold(value) == search.Fold(c.Text)
+    return equal == (c.Op == OpEqual)
+}
@@ -0,0 +1,89 @@
+package catalog
+
+import (
+    "reflect"
+    "testing"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+)
+
+// Verifico que las expresiones válidas produzcan la consulta esperada
+func TestParse(t *testing.T) {
+    tests := []struct {
+        expr string
+        want Query
+    }{
+        {"", Query{}},
+        {"viaje infinito", Query{Text: "viaje infinito"}},
+        {"genre:Comedia", Query{Conditions: []Condition{{Field: "genre", Op: OpEqual, Text: "Comedia"}}}},
+        {"género=Drama", Query{Conditions: []Condition{{Field: "genre", Op: OpEqual, Text: "Drama"}}}},
+        {`titulo:"del mar"`, Query{Conditions: []Condition{{Field: "title", Op: OpEqual, Text: "del mar"}}}},
+        {"year>=2020 rating<7,5", Query{Conditions: []Condition{
+            {Field: "year", Op: OpGreaterEqual, Text: "2020", Number: 2020},
+            {Field: "rating", Op: OpLess, Text: "7,5", Number: 7.5},
+        }}},
+        {"tag!=épica", Query{Conditions: []Condition{{Field: "tag", Op: OpNotEqual, Text: "épica"}}}},
+        {"kind:Audio disponible:todos", Query{Kind: categories.KindAudio, Availability: AvailabilityAll}},
+        {"sort:-rating limit:5 noche", Query{Text: "noche", SortBy: SortRating, Descending: true, Limit: 5}},
+        {"orden:duracion", Query{SortBy: SortDuration}},
+    }
+    for _, tt := range tests {
+        got, err := Parse(tt.expr)
+        if err != nil {
+            t.Errorf("Parse(%q): %v", tt.expr, err)
+            continue
+        }
+        if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("Parse(%q) = %+v, quiero %+v", tt.expr, got, tt.want)
+        }
+    }
+}
+
+// Verifico que cada error de sintaxis indique la posición del fragmento inválido
+func TestParseDiagnostics(t *testing.T) {
+    tests := []struct {
+        expr    string
+        details string
+    }{
+        {`titulo:"sin cerrar`, `posición 8: comillas sin cerrar`},
+        {"viaje :Drama", `posición 7: falta el campo en ":Drama"`},
+        {"color:rojo", `posición 1: campo desconocido "color"`},
+        {"genre:", `posición 1: falta el valor de "genre"`},
+        {"genre>Drama", `posición 1: el campo "genre" no admite ">"`},
+        {"kind!=audio", `posición 1: el campo "kind" solo admite ':'`},
+        {"kind:libros", `posición 1: catálogo "libros" inválido (use audiovisual o audio)`},
+        {"available:quizas", `posición 1: disponibilidad "quizas" inválida (use si, no o todos)`},
+        {"sort!=year", `posición 1: el campo "sort" solo admite ':'`},
+        {"sort:-color", `posición 1: no se puede ordenar por "-color"`},
+        {"limit:0", `posición 1: límite "0" inválido`},
+        {"limit!=5", `posición 1: límite "5" inválido`},
+        {"año:2020 rating>=alto", `posición 10: "alto" no es un número`},
+    }
+    for _, tt := range tests {
+        _, err := Parse(tt.expr)
+        appErr, ok := err.(*errors.AppError)
+        if !ok || appErr.Code != "QUERY_003" || appErr.Details != tt.details {
+            t.Errorf("Parse(%q) = %v, quiero QUERY_003 (%s)", tt.expr, err, tt.details)
+        }
+    }
+}
+
+// Verifico que una consulta leída se pueda ejecutar y filtre como se espera
+func TestParseThenFind(t *testing.T) {
+    q, err := Parse("kind:audiovisual genero:documental sort:title")
+    if err != nil {
+        t.Fatal(err)
+    }
+    page, err := Find(q)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(page.Items) == 0 {
+        t.Fatalf("Find(%+v) sin resultados", q)
+    }
+    for _, item := range page.Items {
+        if item.Ref.Kind != categories.KindAudiovisual || !item.HasGenre("Documental") {
+            t.Errorf("%q no cumple la consulta", item.Title)
+        }
+    }
+}
@@ -2,10 +2,40 @@ package categories
 
 import "time"
 
+// Tipos de contenido del catálogo
+const (
+    KindAudiovisual = "audiovisual"
+    KindAudio       = "audio"
+)
+
+// Roles administrativos que se asignan a los usuarios
+const (
+    RoleSuperAdmin    = "superadmin"
+    RoleContentEditor = "editor"
+    RoleModerator     = "moderador"
+    RoleSupport       = "soporte"
+    RoleBilling       = "facturacion"
+)
+
+// Referencia a un contenido del catálogo, única entre ambos tipos
+type ContentRef struct {
+    Kind string
+    ID   int
+}
+
 // Estructuras comunes para todo el sistema
 type UserRating struct {
-    UserID int
-    Rating float64
+    UserID    int
+    Rating    float64
+    CreatedAt time.Time
+    UpdatedAt time.Time
+    History   []RatingChange // valores anteriores, del más antiguo al más reciente
+}
+
+// Valor anterior de una calificación y el momento en que se reemplazó
+type RatingChange struct {
+    Rating    float64
+    ChangedAt time.Time
 }
 
 type ContentRating struct {
@@ -16,20 +46,26 @@ type ContentRating struct {
 }
 
 type Genre struct {
-    ID   int
-    Name string
+    ID       int
+    Name     string
+    Kinds    []string // tipos de contenido que pueden usarlo: KindAudiovisual, KindAudio
+    ParentID int      // 0 si es un género raíz
+    Aliases  []string // sinónimos que también lo identifican
 }
 
 type User struct {
-    ID          int
-    Name        string
-    Age         int
-    Email       string
-    Password    string
-    Plan        string
-    AgeRating   string
-    IsAdmin     bool
-    CreatedAt   time.Time    
-    LastLogin   time.Time    
-    Preferences map[string]string
+    ID                int
+    Name              string
+    Age               int
+    Email             string
+    Password          string
+    Plan              string
+    AgeRating         string
+    Roles             []string // roles administrativos; vacío para un usuario común
+    EmailVerified     bool // confirmó su email con un código de verificación
+    Disabled          bool // deshabilitado por un administrador; no puede iniciar sesión
+    MustResetPassword bool // debe restablecer su contraseña antes de volver a entrar
+    CreatedAt         time.Time
+    LastLogin         time.Time
+    Preferences       map[string]string
 }
\ No newline at end of file
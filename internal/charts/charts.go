@@ -0,0 +1,161 @@
+package charts
+
+import (
+    "math"
+    "sort"
+    "time"
+    "SDGEStreaming/internal/catalog"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/ratings"
+)
+
+// Configuración de los rankings
+type Config struct {
+    // Votos "imaginarios" con el promedio general que se suman a cada contenido:
+    // un contenido necesita al menos esta cantidad de votos reales para que su
+    // propio promedio pese más que el general
+    MinVotes int
+    // Tiempo en que la actividad de una calificación pierde la mitad de su peso
+    HalfLife time.Duration
+    // Antigüedad máxima de la actividad que cuenta para tendencias
+    Window time.Duration
+}
+
+// Configuración por defecto de los rankings
+var DefaultConfig = Config{
+    MinVotes: 5,
+    HalfLife: 72 * time.Hour,
+    Window:   30 * 24 * time.Hour,
+}
+
+// Posición de un contenido en un ranking
+type Entry struct {
+    Item  catalog.Item
+    Score float64 // promedio ponderado o puntaje de tendencia
+    Votes int
+}
+
+// Filtro de contenidos que pueden aparecer en un ranking
+type Filter struct {
+    Kind  string // vacío para ambos tipos
+    Genre string // vacío para todos los géneros; coincide también con géneros secundarios
+    Allow func(item catalog.Item) bool
+}
+
+// Verifico si un contenido entra en el ranking
+func (f Filter) accepts(item catalog.Item) bool {
+    if !item.IsAvailable {
+        return false
+    }
+    if f.Kind != "" && item.Ref.Kind != f.Kind {
+        return false
+    }
+    if f.Genre != "" && !item.HasGenre(f.Genre) {
+        return false
+    }
+    return f.Allow == nil || f.Allow(item)
+}
+
+// Calculo el promedio bayesiano: el promedio propio mezclado con el general
+// en proporción a la cantidad de votos
+func BayesianScore(mean float64, votes int, globalMean float64, minVotes int) float64 {
+    v, m := float64(votes), float64(minVotes)
+    if v+m == 0 {
+        return 0
+    }
+    return (v*mean + m*globalMean) / (v + m)
+}
+
+// Armo el ranking de mejor calificados con promedio bayesiano
+func TopRated(f Filter, n int, cfg Config) []Entry {
+    type stats struct {
+        sum   float64
+        votes int
+    }
+    perItem := make(map[categories.ContentRef]stats)
+    var totalSum float64
+    var totalVotes int
+    for _, item := range catalog.All() {
+        if !f.accepts(item) {
+            continue
+        }
+        sum, votes := ratings.Aggregate(item.Ref)
+        if votes == 0 {
+            continue
+        }
+        s := stats{sum: sum, votes: votes}
+        perItem[item.Ref] = s
+        totalSum += s.sum
+        totalVotes += s.votes
+    }
+    if totalVotes == 0 {
+        return nil
+    }
+
+    // El promedio general se calcula sobre el mismo universo del ranking
+    globalMean := totalSum / float64(totalVotes)
+    var entries []Entry
+    for ref, s := range perItem {
+        item, _ := catalog.Get(ref)
+        entries = append(entries, Entry{
+            Item:  item,
+            Score: BayesianScore(s.sum/float64(s.votes), s.votes, globalMean, cfg.MinVotes),
+            Votes: s.votes,
+        })
+    }
+    return top(entries, n)
+}
+
+// Armo el ranking de tendencias: actividad reciente con peso que decae con el tiempo
+func Trending(f Filter, n int, cfg Config, now time.Time) []Entry {
+    // Cuento solo la última calificación de cada usuario por contenido
+    type key struct {
+        ref    categories.ContentRef
+        userID int
+    }
+    latest := make(map[key]ratings.Activity)
+    for _, a := range ratings.ActivitySince(now.Add(-cfg.Window)) {
+        latest[key{a.Ref, a.UserID}] = a
+    }
+
+    scores := make(map[categories.ContentRef]float64)
+    votes := make(map[categories.ContentRef]int)
+    for k, a := range latest {
+        // Las calificaciones excluidas por abuso tampoco empujan tendencias
+        if a.Withdrawn || ratings.IsExcluded(k.ref, k.userID) {
+            continue
+        }
+        age := now.Sub(a.At)
+        decay := math.Pow(0.5, float64(age)/float64(cfg.HalfLife))
+        // Una calificación alta empuja más la tendencia que una baja
+        scores[k.ref] += decay * a.Rating / 10
+        votes[k.ref]++
+    }
+
+    var entries []Entry
+    for ref, score := range scores {
+        item, err := catalog.Get(ref)
+        if err != nil || !f.accepts(item) {
+            continue
+        }
+        entries = append(entries, Entry{Item: item, Score: score, Votes: votes[ref]})
+    }
+    return top(entries, n)
+}
+
+// Ordeno un ranking por puntaje y me quedo con los primeros n
+func top(entries []Entry, n int) []Entry {
+    sort.Slice(entries, func(i, j int) bool {
+        if entries[i].Score != entries[j].Score {
+            return entries[i].Score > entries[j].Score
+        }
+        if entries[i].Votes != entries[j].Votes {
+            return entries[i].Votes > entries[j].Votes
+        }
+        return entries[i].Item.Title < entries[j].Item.Title
+    })
+    if n > 0 && len(entries) > n {
+        entries = entries[:n]
+    }
+    return entries
+}
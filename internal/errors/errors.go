@@ -22,6 +22,11 @@ var (
     ErrInvalidPassword    = &AppError{Code: "AUTH_002", Message: "Contraseña inválida"}
     ErrUserNotFound       = &AppError{Code: "AUTH_003", Message: "Usuario no encontrado"}
     ErrEmailExists        = &AppError{Code: "AUTH_004", Message: "Email ya registrado"}
+    ErrEmailNotVerified   = &AppError{Code: "AUTH_005", Message: "Debe verificar su email"}
+    ErrInvalidCredentials = &AppError{Code: "AUTH_006", Message: "Credenciales inválidas"}
+    ErrAccountDisabled    = &AppError{Code: "AUTH_010", Message: "Cuenta deshabilitada", Details: "Contacte a un administrador"}
+    ErrMustResetPassword  = &AppError{Code: "AUTH_011", Message: "Debe restablecer su contraseña", Details: "Revise su email o use ¿Olvidaste tu contraseña?"}
+    ErrTwoFactorPending   = &AppError{Code: "AUTH_012", Message: "Falta el código de verificación", Details: "Ingrese el código de su app autenticadora"}
     ErrInvalidAge         = &AppError{Code: "USER_001", Message: "Edad inválida"}
     ErrInvalidName        = &AppError{Code: "USER_002", Message: "Nombre inválido"}
     ErrContentNotFound    = &AppError{Code: "CONTENT_001", Message: "Contenido no encontrado"}
@@ -34,6 +39,7 @@ var (
     ErrInvalidDuration    = &AppError{Code: "CONTENT_003", Message: "Duración inválida"}
     ErrInvalidAgeRating   = &AppError{Code: "CONTENT_004", Message: "Clasificación por edad inválida"}
     ErrInvalidGenre       = &AppError{Code: "CONTENT_005", Message: "Género inválido"}
+    ErrInvalidCursor      = &AppError{Code: "QUERY_001", Message: "Cursor de paginación inválido"}
 )
 
 // Creo un nuevo error de aplicación con detalles específicos
@@ -1,77 +1,417 @@
 package genres
 
 import (
+    "sort"
     "strings"
     "SDGEStreaming/internal/categories"
     "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/textnorm"
 )
 
-// Géneros soportados para contenido
-var SupportedGenres = []string{
-    "Acción", "Comedia", "Drama", "Ciencia Ficción", 
-    "Romance", "Terror", "Documental", "Música", 
-    "Educación", "Infantil", "Deportes", "Noticias",
+// Género inicial con los tipos de contenido que lo usan y su género padre
+type seed struct {
+    name    string
+    kinds   []string
+    parent  string
+    aliases []string
+}
+
+var (
+    both            = []string{categories.KindAudiovisual, categories.KindAudio}
+    audiovisualOnly = []string{categories.KindAudiovisual}
+    audioOnly       = []string{categories.KindAudio}
+)
+
+// Géneros con los que arranca el sistema; los administradores pueden cambiarlos
+var defaultGenres = []seed{
+    {name: "Acción", kinds: audiovisualOnly},
+    {name: "Comedia", kinds: both},
+    {name: "Stand-Up", kinds: both, parent: "Comedia"},
+    {name: "Drama", kinds: audiovisualOnly},
+    {name: "Ciencia Ficción", kinds: both, aliases: []string{"Sci-Fi", "Ciencia-Ficción"}},
+    {name: "Romance", kinds: audiovisualOnly},
+    {name: "Terror", kinds: both, aliases: []string{"Horror"}},
+    {name: "Documental", kinds: audiovisualOnly, aliases: []string{"Docu"}},
+    {name: "Música", kinds: both},
+    {name: "Clásica", kinds: audioOnly, parent: "Música"},
+    {name: "Ópera", kinds: audioOnly, parent: "Clásica"},
+    {name: "Rock", kinds: audioOnly, parent: "Música"},
+    {name: "Pop", kinds: audioOnly, parent: "Música"},
+    {name: "Jazz", kinds: audioOnly, parent: "Música"},
+    {name: "Educación", kinds: both},
+    {name: "Tecnología", kinds: audioOnly, parent: "Educación"},
+    {name: "Infantil", kinds: both},
+    {name: "Deportes", kinds: both},
+    {name: "Noticias", kinds: both},
 }
 
 // Variables globales para géneros
 var (
-    genres    = make(map[string]categories.Genre)
-    nextID    = 1
+    genres = make(map[int]*categories.Genre)
+    byKey  = make(map[string]int) // clave del nombre o alias -> ID
+    nextID = 1
 )
 
 // Inicializo los géneros predeterminados
 func init() {
-    for _, genreName := range SupportedGenres {
-        AddGenre(genreName)
+    for _, s := range defaultGenres {
+        parentID := 0
+        if s.parent != "" {
+            if parent, err := GetGenreByName(s.parent); err == nil {
+                parentID = parent.ID
+            }
+        }
+        g, err := AddGenre(s.name, s.kinds, parentID)
+        if err != nil {
+            continue
+        }
+        for _, alias := range s.aliases {
+            AddAlias(g.ID, alias)
+        }
+    }
+}
+
+// Normalizo un nombre de género para mostrarlo: "ciencia  FICCIÓN" -> "Ciencia Ficción"
+func normalize(name string) string {
+    return textnorm.Title(name)
+}
+
+// Obtengo la clave con la que busco un nombre o alias
+func key(name string) string {
+    return textnorm.Key(name)
+}
+
+// Copio un género para que quien lo recibe no modifique el almacenamiento
+func copyGenre(g *categories.Genre) categories.Genre {
+    c := *g
+    c.Kinds = append([]string(nil), g.Kinds...)
+    c.Aliases = append([]string(nil), g.Aliases...)
+    return c
+}
+
+// Valido los tipos de contenido de un género
+func validKinds(kinds []string) bool {
+    if len(kinds) == 0 {
+        return false
+    }
+    for _, k := range kinds {
+        if k != categories.KindAudiovisual && k != categories.KindAudio {
+            return false
+        }
     }
+    return true
 }
 
-// Agrego un nuevo género
-func AddGenre(name string) *categories.Genre {
-    name = strings.Title(strings.ToLower(name))
-    
-    newGenre := categories.Genre{
-        ID:   nextID,
-        Name: name,
+// Verifico que un nombre o alias no esté tomado por otro género
+func checkAvailable(name string, exceptID int) error {
+    if textnorm.RuneCount(textnorm.Clean(name)) < 2 {
+        return errors.NewAppError("GENRE_006", "Nombre de género inválido", name)
+    }
+    if id, exists := byKey[key(name)]; exists && id != exceptID {
+        return errors.NewAppError("GENRE_001", "El género ya existe", genres[id].Name)
+    }
+    return nil
+}
+
+// Agrego un nuevo género para los tipos de contenido indicados, opcionalmente bajo un padre
+func AddGenre(name string, kinds []string, parentID int) (*categories.Genre, error) {
+    name = normalize(name)
+    if err := checkAvailable(name, 0); err != nil {
+        return nil, err
+    }
+    if !validKinds(kinds) {
+        return nil, errors.NewAppError("GENRE_002", "Tipo de contenido inválido para el género", strings.Join(kinds, ", "))
     }
-    
-    genres[name] = newGenre
+    if parentID != 0 {
+        if _, exists := genres[parentID]; !exists {
+            return nil, errors.NewAppError("GENRE_003", "Jerarquía de géneros inválida", "El género padre no existe")
+        }
+    }
+
+    newGenre := &categories.Genre{
+        ID:       nextID,
+        Name:     name,
+        Kinds:    append([]string(nil), kinds...),
+        ParentID: parentID,
+    }
+    genres[nextID] = newGenre
+    byKey[key(name)] = nextID
     nextID++
-    return &newGenre
+    g := copyGenre(newGenre)
+    return &g, nil
 }
 
-// Obtengo un género por nombre
+// Obtengo un género por nombre o por alias
 func GetGenreByName(name string) (*categories.Genre, error) {
-    name = strings.Title(strings.ToLower(name))
-    genre, exists := genres[name]
+    id, exists := byKey[key(name)]
     if !exists {
         return nil, errors.NewAppError("CONTENT_005", "Género inválido", name)
     }
-    return &genre, nil
+    g := copyGenre(genres[id])
+    return &g, nil
 }
 
-// Obtengo todos los géneros disponibles
+// Obtengo un género por ID
+func GetByID(id int) (*categories.Genre, error) {
+    g, exists := genres[id]
+    if !exists {
+        return nil, errors.NewAppError("CONTENT_005", "Género inválido", "")
+    }
+    c := copyGenre(g)
+    return &c, nil
+}
+
+// Obtengo todos los géneros ordenados por nombre
 func GetAllGenres() []categories.Genre {
-    var allGenres []categories.Genre
+    allGenres := make([]categories.Genre, 0, len(genres))
     for _, genre := range genres {
-        allGenres = append(allGenres, genre)
+        allGenres = append(allGenres, copyGenre(genre))
     }
+    sort.Slice(allGenres, func(i, j int) bool {
+        return allGenres[i].Name < allGenres[j].Name
+    })
     return allGenres
 }
 
-// Valido si un género es soportado
+// Valido si un género es soportado por algún tipo de contenido
 func IsSupportedGenre(genre string) bool {
     _, err := GetGenreByName(genre)
     return err == nil
 }
 
-// Filtro géneros por tipo de contenido
-func FilterByType(contentType string) []categories.Genre {
+// Verifico si un género admite un tipo de contenido
+func supports(g *categories.Genre, kind string) bool {
+    for _, k := range g.Kinds {
+        if k == kind {
+            return true
+        }
+    }
+    return false
+}
+
+// Valido si un género es soportado por un tipo de contenido
+func IsSupportedFor(kind, genre string) bool {
+    _, err := CanonicalFor(kind, genre)
+    return err == nil
+}
+
+// Obtengo el nombre canónico de un género, resolviendo alias
+func Canonical(name string) (string, error) {
+    genre, err := GetGenreByName(name)
+    if err != nil {
+        return "", err
+    }
+    return genre.Name, nil
+}
+
+// Obtengo el nombre canónico de un género que admite el tipo de contenido indicado
+func CanonicalFor(kind, name string) (string, error) {
+    genre, err := GetGenreByName(name)
+    if err != nil {
+        return "", err
+    }
+    if !supports(genre, kind) {
+        return "", errors.NewAppError("CONTENT_005", "Género inválido", genre.Name+" no aplica a este tipo de contenido")
+    }
+    return genre.Name, nil
+}
+
+// Obtengo los géneros de un tipo de contenido
+func FilterByKind(kind string) []categories.Genre {
     var filtered []categories.Genre
     for _, genre := range GetAllGenres() {
-        // En una implementación real, esto dependería del tipo de contenido
-        // Por ahora, devolvemos todos los géneros
-        filtered = append(filtered, genre)
+        if supports(&genre, kind) {
+            filtered = append(filtered, genre)
+        }
     }
     return filtered
-}
\ No newline at end of file
+}
+
+// Filtro géneros por tipo de contenido ("Película", "Música", ...)
+func FilterByType(contentType string) []categories.Genre {
+    switch contentType {
+    case "Película", "Serie", "Documental":
+        return FilterByKind(categories.KindAudiovisual)
+    case "Música", "Podcast", "Audiolibro":
+        return FilterByKind(categories.KindAudio)
+    }
+    return nil
+}
+
+// Obtengo los subgéneros directos de un género
+func Children(id int) []categories.Genre {
+    var children []categories.Genre
+    for _, genre := range GetAllGenres() {
+        if genre.ParentID == id {
+            children = append(children, genre)
+        }
+    }
+    return children
+}
+
+// Verifico si un género es el indicado o uno de sus subgéneros, a cualquier profundidad
+func IsWithin(genre, ancestor string) bool {
+    target, exists := byKey[key(ancestor)]
+    if !exists {
+        return false
+    }
+    id, exists := byKey[key(genre)]
+    for exists && id != 0 {
+        if id == target {
+            return true
+        }
+        id = genres[id].ParentID
+    }
+    return false
+}
+
+// Cambio el género padre verificando que no se formen ciclos
+func SetParent(id, parentID int) error {
+    g, exists := genres[id]
+    if !exists {
+        return errors.NewAppError("CONTENT_005", "Género inválido", "")
+    }
+    for p := parentID; p != 0; p = genres[p].ParentID {
+        if _, exists := genres[p]; !exists {
+            return errors.NewAppError("GENRE_003", "Jerarquía de géneros inválida", "El género padre no existe")
+        }
+        if p == id {
+            return errors.NewAppError("GENRE_003", "Jerarquía de géneros inválida", "Un género no puede estar dentro de sí mismo")
+        }
+    }
+    g.ParentID = parentID
+    return nil
+}
+
+// Cambio los tipos de contenido que pueden usar un género
+func SetKinds(id int, kinds []string) error {
+    g, exists := genres[id]
+    if !exists {
+        return errors.NewAppError("CONTENT_005", "Género inválido", "")
+    }
+    if !validKinds(kinds) {
+        return errors.NewAppError("GENRE_002", "Tipo de contenido inválido para el género", strings.Join(kinds, ", "))
+    }
+    g.Kinds = append([]string(nil), kinds...)
+    return nil
+}
+
+// Agrego un sinónimo a un género
+func AddAlias(id int, alias string) error {
+    g, exists := genres[id]
+    if !exists {
+        return errors.NewAppError("CONTENT_005", "Género inválido", "")
+    }
+    alias = normalize(alias)
+    if err := checkAvailable(alias, 0); err != nil {
+        return err
+    }
+    g.Aliases = append(g.Aliases, alias)
+    byKey[key(alias)] = id
+    return nil
+}
+
+// Quito un sinónimo de un género
+func RemoveAlias(id int, alias string) error {
+    g, exists := genres[id]
+    if !exists {
+        return errors.NewAppError("CONTENT_005", "Género inválido", "")
+    }
+    alias = normalize(alias)
+    for i, a := range g.Aliases {
+        if key(a) == key(alias) {
+            g.Aliases = append(g.Aliases[:i], g.Aliases[i+1:]...)
+            delete(byKey, key(alias))
+            return nil
+        }
+    }
+    return errors.NewAppError("GENRE_007", "Alias no encontrado", alias)
+}
+
+// Renombro un género; el nombre anterior queda como alias para no romper búsquedas.
+// Devuelvo el nombre anterior para actualizar el contenido que lo usa
+func Rename(id int, newName string) (string, error) {
+    g, exists := genres[id]
+    if !exists {
+        return "", errors.NewAppError("CONTENT_005", "Género inválido", "")
+    }
+    newName = normalize(newName)
+    if err := checkAvailable(newName, id); err != nil {
+        return "", err
+    }
+    oldName := g.Name
+    if newName == oldName {
+        return oldName, nil
+    }
+
+    // Si el nombre nuevo era un alias del mismo género deja de serlo
+    for i, a := range g.Aliases {
+        if key(a) == key(newName) {
+            g.Aliases = append(g.Aliases[:i], g.Aliases[i+1:]...)
+            break
+        }
+    }
+    g.Name = newName
+    // Si solo cambian mayúsculas o tildes el nombre anterior ya se encuentra con la misma clave
+    if key(oldName) != key(newName) {
+        g.Aliases = append(g.Aliases, oldName)
+    }
+    byKey[key(newName)] = id
+    return oldName, nil
+}
+
+// Fusiono un género en otro: su nombre y sus alias pasan a ser alias del destino,
+// sus subgéneros se mueven y el destino suma sus tipos de contenido.
+// Devuelvo los nombres de ambos para actualizar el contenido
+func Merge(sourceID, targetID int) (string, string, error) {
+    source, exists := genres[sourceID]
+    target, exists2 := genres[targetID]
+    if !exists || !exists2 {
+        return "", "", errors.NewAppError("CONTENT_005", "Género inválido", "")
+    }
+    if sourceID == targetID || IsWithin(target.Name, source.Name) {
+        return "", "", errors.NewAppError("GENRE_003", "Jerarquía de géneros inválida", "No se puede fusionar un género con uno de sus subgéneros")
+    }
+
+    for _, k := range source.Kinds {
+        if !supports(target, k) {
+            target.Kinds = append(target.Kinds, k)
+        }
+    }
+    for _, g := range genres {
+        if g.ParentID == sourceID {
+            g.ParentID = targetID
+        }
+    }
+    for _, name := range append([]string{source.Name}, source.Aliases...) {
+        target.Aliases = append(target.Aliases, name)
+        byKey[key(name)] = targetID
+    }
+    delete(genres, sourceID)
+    return source.Name, target.Name, nil
+}
+
+// Elimino un género sin subgéneros; quien llama verifica que ningún contenido lo use
+func Delete(id int) error {
+    g, exists := genres[id]
+    if !exists {
+        return errors.NewAppError("CONTENT_005", "Género inválido", "")
+    }
+    if len(Children(id)) > 0 {
+        return errors.NewAppError("GENRE_005", "El género tiene subgéneros", g.Name)
+    }
+    for _, name := range append([]string{g.Name}, g.Aliases...) {
+        delete(byKey, key(name))
+    }
+    delete(genres, id)
+    return nil
+}
+
+// Normalizo una etiqueta libre: minúsculas, sin espacios de más, entre 2 y 30 letras
+func NormalizeTag(tag string) (string, error) {
+    tag = textnorm.Key(tag)
+    if n := textnorm.RuneCount(tag); n < 2 || n > 30 {
+        return "", errors.NewAppError("CONTENT_008", "Etiqueta inválida", "Debe tener entre 2 y 30 caracteres")
+    }
+    return tag, nil
+}
@@ -0,0 +1,56 @@
+package genres
+
+import (
+    "testing"
+    "SDGEStreaming/internal/categories"
+)
+
+// Verifico que renombrar deje el nombre anterior como alias solo si tiene otra clave
+func TestRename(t *testing.T) {
+    tests := []struct {
+        name      string
+        original  string // nombre guardado, que puede venir sin normalizar
+        newName   string
+        wantName  string
+        wantAlias bool
+    }{
+        {"nombre distinto", "Cine Negro", "Noir", "Noir", true},
+        {"tildes", "Animacion Clasica", "Animación Clásica", "Animación Clásica", true},
+        {"mismo nombre normalizado", "Cine Mudo", "cine   MUDO", "Cine Mudo", false},
+        {"solo mayúsculas", "CINE EXPERIMENTAL", "Cine Experimental", "Cine Experimental", false},
+    }
+    for _, tt := range tests {
+        g, err := AddGenre(tt.original, []string{categories.KindAudiovisual}, 0)
+        if err != nil {
+            t.Fatalf("%s: %v", tt.name, err)
+        }
+        genres[g.ID].Name = tt.original
+        old, err := Rename(g.ID, tt.newName)
+        if err != nil {
+            t.Fatalf("%s: %v", tt.name, err)
+        }
+        if old != tt.original {
+            t.Errorf("%s: Rename devolvió %q, quiero %q", tt.name, old, tt.original)
+        }
+        renamed, _ := GetByID(g.ID)
+        if renamed.Name != tt.wantName {
+            t.Errorf("%s: nombre %q, quiero %q", tt.name, renamed.Name, tt.wantName)
+        }
+        hasAlias := false
+        for _, a := range renamed.Aliases {
+            if a == tt.original {
+                hasAlias = true
+            }
+            if key(a) == key(renamed.Name) {
+                t.Errorf("%s: el alias %q tiene la misma clave que el nombre", tt.name, a)
+            }
+        }
+        if hasAlias != tt.wantAlias {
+            t.Errorf("%s: alias %v, quiero alias del nombre anterior = %v", tt.name, renamed.Aliases, tt.wantAlias)
+        }
+        // El nombre anterior sigue encontrando el género
+        if found, err := Canonical(tt.original); err != nil || found != tt.wantName {
+            t.Errorf("%s: Canonical(%q) = %q, %v; quiero %q", tt.name, tt.original, found, err, tt.wantName)
+        }
+    }
+}
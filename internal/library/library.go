@@ -0,0 +1,99 @@
+package library
+
+import (
+    "sort"
+    "time"
+    "SDGEStreaming/internal/catalog"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+)
+
+// Contenido guardado en la lista de un usuario
+type Entry struct {
+    Ref     categories.ContentRef
+    AddedAt time.Time
+}
+
+// Reproducción de un contenido
+type Play struct {
+    Ref      categories.ContentRef
+    PlayedAt time.Time
+}
+
+// Variables globales para almacenamiento en memoria
+var (
+    watchlists = make(map[int][]Entry) // usuario -> lista en orden de agregado
+    history    = make(map[int][]Play)  // usuario -> reproducciones en orden cronológico
+)
+
+// Verifico si un contenido está en la lista de un usuario
+func InList(userID int, ref categories.ContentRef) bool {
+    for _, e := range watchlists[userID] {
+        if e.Ref == ref {
+            return true
+        }
+    }
+    return false
+}
+
+// Agrego un contenido a la lista de un usuario
+func AddToList(userID int, ref categories.ContentRef) error {
+    if _, err := catalog.Get(ref); err != nil {
+        return err
+    }
+    if InList(userID, ref) {
+        return errors.NewAppError("LIBRARY_001", "El contenido ya está en su lista", "")
+    }
+    watchlists[userID] = append(watchlists[userID], Entry{Ref: ref, AddedAt: time.Now()})
+    return nil
+}
+
+// Quito un contenido de la lista de un usuario
+func RemoveFromList(userID int, ref categories.ContentRef) error {
+    list := watchlists[userID]
+    for i, e := range list {
+        if e.Ref == ref {
+            watchlists[userID] = append(list[:i], list[i+1:]...)
+            return nil
+        }
+    }
+    return errors.NewAppError("LIBRARY_002", "El contenido no está en su lista", "")
+}
+
+// Obtengo la lista de un usuario, lo último agregado primero
+func List(userID int) []Entry {
+    list := append([]Entry(nil), watchlists[userID]...)
+    sort.SliceStable(list, func(i, j int) bool {
+        return list[i].AddedAt.After(list[j].AddedAt)
+    })
+    return list
+}
+
+// Registro que un usuario reprodujo un contenido disponible
+func RecordPlay(userID int, ref categories.ContentRef) error {
+    item, err := catalog.Get(ref)
+    if err != nil {
+        return err
+    }
+    if !item.IsAvailable {
+        return errors.NewAppError("LIBRARY_003", "Contenido no disponible", item.Title)
+    }
+    history[userID] = append(history[userID], Play{Ref: ref, PlayedAt: time.Now()})
+    return nil
+}
+
+// Obtengo el historial de reproducción de un usuario, lo más reciente primero
+func History(userID int) []Play {
+    plays := history[userID]
+    list := make([]Play, len(plays))
+    for i, p := range plays {
+        list[len(plays)-1-i] = p
+    }
+    return list
+}
+
+// Borro la lista y el historial de un usuario, por ejemplo al eliminar su cuenta
+func RemoveAllByUser(userID int) {
+    delete(watchlists, userID)
+    delete(history, userID)
+}
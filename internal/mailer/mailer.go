@@ -0,0 +1,61 @@
+package mailer
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+)
+
+// Mensaje de correo saliente
+type Message struct {
+    To      string
+    Subject string
+    Body    string
+    SentAt  time.Time
+}
+
+// Medio de envío de correos; se puede reemplazar por un servidor SMTP real
+type Mailer interface {
+    Send(msg Message) error
+}
+
+// Bandeja de salida en disco para pruebas locales: cada mensaje queda en un archivo
+type FileOutbox struct {
+    Dir string
+}
+
+// Guardo el mensaje como un archivo de texto con encabezados de correo
+func (o FileOutbox) Send(msg Message) error {
+    if err := os.MkdirAll(o.Dir, 0o755); err != nil {
+        return err
+    }
+    name := fmt.Sprintf("%s_%s.eml", msg.SentAt.Format("20060102-150405.000000000"), safeName(msg.To))
+    content := fmt.Sprintf("To: %s\nSubject: %s\nDate: %s\n\n%s\n",
+        msg.To, msg.Subject, msg.SentAt.Format(time.RFC1123Z), msg.Body)
+    return os.WriteFile(filepath.Join(o.Dir, name), []byte(content), 0o600)
+}
+
+// Reemplazo los caracteres que no sirven en un nombre de archivo
+func safeName(s string) string {
+    return strings.Map(func(r rune) rune {
+        if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '@' || r == '.' || r == '-' {
+            return r
+        }
+        return '_'
+    }, s)
+}
+
+// Medio de envío actual; por defecto una bandeja en el directorio temporal
+var current Mailer = FileOutbox{Dir: filepath.Join(os.TempDir(), "sdge-outbox")}
+
+// Cambio el medio de envío
+func SetMailer(m Mailer) {
+    current = m
+}
+
+// Envío un correo con el medio actual
+func Send(to, subject, body string) error {
+    return current.Send(Message{To: to, Subject: subject, Body: body, SentAt: time.Now()})
+}
@@ -0,0 +1,286 @@
+package people
+
+import (
+    "sort"
+    "strings"
+    "time"
+    "SDGEStreaming/internal/audio"
+    "SDGEStreaming/internal/audiovisual"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/textnorm"
+)
+
+// Roles que una persona puede tener en un contenido
+const (
+    RoleDirector = "director"
+    RoleActor    = "actor"
+    RoleWriter   = "guionista"
+    RoleArtist   = "artista"
+    RoleNarrator = "narrador"
+    RoleHost     = "conductor"
+)
+
+// Roles en el orden en que se muestran
+var Roles = []string{RoleDirector, RoleWriter, RoleActor, RoleArtist, RoleNarrator, RoleHost}
+
+// Título de cada rol en la página de una persona o de un contenido
+var RoleLabels = map[string]string{
+    RoleDirector: "Dirección",
+    RoleWriter:   "Guion",
+    RoleActor:    "Reparto",
+    RoleArtist:   "Artista",
+    RoleNarrator: "Narración",
+    RoleHost:     "Conducción",
+}
+
+// Persona que participa en contenidos del catálogo
+type Person struct {
+    ID        int
+    Name      string
+    CreatedAt time.Time
+}
+
+// Participación de una persona en un contenido con un rol
+type Credit struct {
+    PersonID int
+    Ref      categories.ContentRef
+    Role     string
+}
+
+// Variables globales para almacenamiento en memoria
+var (
+    persons   = make(map[int]*Person)
+    byName    = make(map[string]int) // nombre normalizado -> persona
+    credits   []Credit
+    byPerson  = make(map[int][]int)                   // persona -> posiciones en credits
+    byContent = make(map[categories.ContentRef][]int) // contenido -> posiciones en credits
+    imported  = make(map[categories.ContentRef]bool) // contenidos cuyos campos de texto ya se importaron
+    nextID    = 1
+    revision  = 0
+)
+
+func init() {
+    // Paso a personas los nombres cargados como texto en el catálogo inicial
+    ImportExisting()
+}
+
+// Normalizo un nombre para comparar sin mayúsculas ni espacios de más
+func nameKey(name string) string {
+    return textnorm.Key(name)
+}
+
+// Verifico que un rol sea válido
+func IsValidRole(role string) bool {
+    for _, r := range Roles {
+        if r == role {
+            return true
+        }
+    }
+    return false
+}
+
+// Obtengo la revisión actual; cambia con cada persona o crédito nuevo o quitado
+func Revision() int {
+    return revision
+}
+
+// Agrego una persona; el nombre no puede repetirse
+func AddPerson(name string) (*Person, error) {
+    name = textnorm.Clean(name)
+    if textnorm.RuneCount(name) < 2 {
+        return nil, errors.ErrInvalidName
+    }
+    if _, exists := byName[nameKey(name)]; exists {
+        return nil, errors.NewAppError("PEOPLE_001", "La persona ya existe", name)
+    }
+
+    p := &Person{ID: nextID, Name: name, CreatedAt: time.Now()}
+    persons[nextID] = p
+    byName[nameKey(name)] = nextID
+    nextID++
+    revision++
+    return p, nil
+}
+
+// Busco una persona por nombre o la creo si no existe
+func FindOrCreate(name string) (*Person, error) {
+    if p, err := FindByName(name); err == nil {
+        return p, nil
+    }
+    return AddPerson(name)
+}
+
+// Obtengo una persona por ID
+func GetByID(id int) (*Person, error) {
+    p, exists := persons[id]
+    if !exists {
+        return nil, errors.NewAppError("PEOPLE_002", "Persona no encontrada", "")
+    }
+    return p, nil
+}
+
+// Busco una persona por nombre, sin distinguir mayúsculas
+func FindByName(name string) (*Person, error) {
+    id, exists := byName[nameKey(name)]
+    if !exists {
+        return nil, errors.NewAppError("PEOPLE_002", "Persona no encontrada", name)
+    }
+    return persons[id], nil
+}
+
+// Obtengo todas las personas ordenadas por nombre
+func All() []Person {
+    list := make([]Person, 0, len(persons))
+    for _, p := range persons {
+        list = append(list, *p)
+    }
+    sort.Slice(list, func(i, j int) bool {
+        return nameKey(list[i].Name) < nameKey(list[j].Name)
+    })
+    return list
+}
+
+// Verifico que un contenido exista en su catálogo
+func contentExists(ref categories.ContentRef) bool {
+    switch ref.Kind {
+    case categories.KindAudiovisual:
+        _, err := audiovisual.GetByID(ref.ID)
+        return err == nil
+    case categories.KindAudio:
+        _, err := audio.GetByID(ref.ID)
+        return err == nil
+    }
+    return false
+}
+
+// Acredito a una persona en un contenido con un rol
+func AddCredit(personID int, ref categories.ContentRef, role string) error {
+    if _, err := GetByID(personID); err != nil {
+        return err
+    }
+    if !contentExists(ref) {
+        return errors.ErrContentNotFound
+    }
+    if !IsValidRole(role) {
+        return errors.NewAppError("PEOPLE_003", "Rol inválido", role)
+    }
+    for _, i := range byContent[ref] {
+        if credits[i].PersonID == personID && credits[i].Role == role {
+            return errors.NewAppError("PEOPLE_004", "El crédito ya existe", role)
+        }
+    }
+
+    credits = append(credits, Credit{PersonID: personID, Ref: ref, Role: role})
+    pos := len(credits) - 1
+    byPerson[personID] = append(byPerson[personID], pos)
+    byContent[ref] = append(byContent[ref], pos)
+    revision++
+    return nil
+}
+
+// Quito el crédito de una persona en un contenido
+func RemoveCredit(personID int, ref categories.ContentRef, role string) error {
+    for pos, c := range credits {
+        if c.PersonID != personID || c.Ref != ref || c.Role != role {
+            continue
+        }
+        credits = append(credits[:pos], credits[pos+1:]...)
+        reindex()
+        revision++
+        return nil
+    }
+    return errors.NewAppError("PEOPLE_005", "Crédito no encontrado", role)
+}
+
+// Reconstruyo los índices de créditos después de quitar uno
+func reindex() {
+    byPerson = make(map[int][]int)
+    byContent = make(map[categories.ContentRef][]int)
+    for pos, c := range credits {
+        byPerson[c.PersonID] = append(byPerson[c.PersonID], pos)
+        byContent[c.Ref] = append(byContent[c.Ref], pos)
+    }
+}
+
+// Ordeno créditos por rol según Roles
+func roleIndex(role string) int {
+    for i, r := range Roles {
+        if r == role {
+            return i
+        }
+    }
+    return len(Roles)
+}
+
+// Obtengo los créditos de un contenido, agrupados por rol en orden de carga
+func CreditsFor(ref categories.ContentRef) []Credit {
+    var list []Credit
+    for _, i := range byContent[ref] {
+        list = append(list, credits[i])
+    }
+    sort.SliceStable(list, func(i, j int) bool {
+        return roleIndex(list[i].Role) < roleIndex(list[j].Role)
+    })
+    return list
+}
+
+// Obtengo los créditos de una persona: su filmografía y discografía
+func CreditsOf(personID int) []Credit {
+    var list []Credit
+    for _, i := range byPerson[personID] {
+        list = append(list, credits[i])
+    }
+    sort.SliceStable(list, func(i, j int) bool {
+        return roleIndex(list[i].Role) < roleIndex(list[j].Role)
+    })
+    return list
+}
+
+// Obtengo los nombres acreditados en un contenido
+func NamesFor(ref categories.ContentRef) []string {
+    var names []string
+    for _, c := range CreditsFor(ref) {
+        if p, err := GetByID(c.PersonID); err == nil {
+            names = append(names, p.Name)
+        }
+    }
+    return names
+}
+
+// Deduzco el rol del campo de texto de un contenido de audio según su tipo
+func audioRole(contentType string) string {
+    switch contentType {
+    case "Podcast":
+        return RoleHost
+    case "Audiolibro":
+        return RoleNarrator
+    }
+    return RoleArtist
+}
+
+// Acredito el nombre cargado como texto en un contenido, una sola vez por
+// contenido para no deshacer créditos quitados después
+func importName(name string, ref categories.ContentRef, role string) {
+    if imported[ref] {
+        return
+    }
+    imported[ref] = true
+    if strings.TrimSpace(name) == "" {
+        return
+    }
+    if p, err := FindOrCreate(name); err == nil {
+        AddCredit(p.ID, ref, role)
+    }
+}
+
+// Importo como personas los campos Director y Artist del catálogo; se puede
+// repetir sin duplicar créditos
+func ImportExisting() {
+    for _, c := range audiovisual.ListAllIncludingUnavailable() {
+        importName(c.Director, categories.ContentRef{Kind: categories.KindAudiovisual, ID: c.ID}, RoleDirector)
+    }
+    for _, c := range audio.ListAllIncludingUnavailable() {
+        importName(c.Artist, categories.ContentRef{Kind: categories.KindAudio, ID: c.ID}, audioRole(c.Type))
+    }
+}
@@ -0,0 +1,52 @@
+package profiles
+
+import (
+    "fmt"
+    "sync"
+    "testing"
+    "SDGEStreaming/internal/categories"
+)
+
+// Cantidad de usuarios sintéticos de los benchmarks
+const benchUsers = 100000
+
+var (
+    populateOnce sync.Once
+    snapshot     []categories.User
+    lastEmail    = fmt.Sprintf("usuario%d@bench.com", benchUsers-1)
+)
+
+// Registro una sola vez usuarios sintéticos
+func populate(b *testing.B) {
+    populateOnce.Do(func() {
+        for i := 0; i < benchUsers; i++ {
+            AddUser("Usuario Sintético", 30, fmt.Sprintf("usuario%d@bench.com", i), "clave123", "Free", "Adulto", nil)
+        }
+        snapshot = GetAllUsers()
+    })
+    b.ResetTimer()
+}
+
+// Recorrido lineal equivalente a FindByEmail sin índices
+func scanByEmail(users []categories.User, email string) *categories.User {
+    for i, u := range users {
+        if u.Email == email {
+            return &users[i]
+        }
+    }
+    return nil
+}
+
+func BenchmarkFindByEmailScan(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        scanByEmail(snapshot, lastEmail)
+    }
+}
+
+func BenchmarkFindByEmail(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        FindByEmail(lastEmail)
+    }
+}
@@ -1,28 +1,43 @@
 package profiles
 
 import (
+    "strings"
     "time"
     "SDGEStreaming/internal/categories"
     "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/textnorm"
     "SDGEStreaming/internal/utils"
 )
 
 // Variables globales para almacenamiento en memoria
 var (
-    users  = make(map[int]categories.User)
-    nextID = 1
+    users   = make(map[int]categories.User)
+    nextID  = 1
+    byEmail = make(map[string]int) // clave del email -> ID, índice para búsquedas y unicidad
 )
 
 // Inicializo usuarios predeterminados para pruebas
 func init() {
     // Usuario administrador
-    AddUser("Administrador", 35, "admin@sdge.com", "admin123", "Premium", "Adulto", true)
+    if admin, err := AddUser("Administrador", 35, "admin@sdge.com", "admin123", "Premium", "Adulto", []string{categories.RoleSuperAdmin}); err == nil {
+        MarkEmailVerified(admin.ID)
+    }
     // Usuario de ejemplo
-    AddUser("Usuario Demo", 28, "user@demo.com", "demo123", "Free", "Adulto", false)
+    if demo, err := AddUser("Usuario Demo", 28, "user@demo.com", "demo123", "Free", "Adulto", nil); err == nil {
+        MarkEmailVerified(demo.ID)
+    }
+}
+
+// Obtengo la clave de identidad de un email: sin espacios y sin distinguir mayúsculas
+func emailKey(email string) string {
+    return textnorm.Key(email)
 }
 
 // Agrego un nuevo usuario al sistema
-func AddUser(name string, age int, email string, password string, plan string, ageRating string, isAdmin bool) (*categories.User, error) {
+func AddUser(name string, age int, email string, password string, plan string, ageRating string, roles []string) (*categories.User, error) {
+    name = textnorm.Clean(name)
+    email = strings.TrimSpace(email)
+
     // Valido datos de entrada
     if !utils.IsValidName(name) {
         return nil, errors.ErrInvalidName
@@ -41,10 +56,8 @@ func AddUser(name string, age int, email string, password string, plan string, a
     }
     
     // Verifico que el email no exista
-    for _, u := range users {
-        if u.Email == email {
-            return nil, errors.ErrEmailExists
-        }
+    if _, exists := byEmail[emailKey(email)]; exists {
+        return nil, errors.ErrEmailExists
     }
     
     // Creo el nuevo usuario
@@ -59,22 +72,23 @@ func AddUser(name string, age int, email string, password string, plan string, a
         CreatedAt:   time.Now(),
         LastLogin:   time.Now(),
         Preferences: make(map[string]string),
-        IsAdmin:     isAdmin,
+        Roles:       append([]string(nil), roles...),
     }
     
     users[nextID] = newUser
+    byEmail[emailKey(email)] = nextID
     nextID++
     return &newUser, nil
 }
 
 // Busco un usuario por email
 func FindByEmail(email string) (*categories.User, error) {
-    for _, u := range users {
-        if u.Email == email {
-            return &u, nil
-        }
+    id, exists := byEmail[emailKey(email)]
+    if !exists {
+        return nil, errors.ErrUserNotFound
     }
-    return nil, errors.ErrUserNotFound
+    user := users[id]
+    return &user, nil
 }
 
 // Busco un usuario por ID
@@ -107,6 +121,126 @@ func UpdatePreferences(userID int, key, value string) error {
     return nil
 }
 
+// Marco el email de un usuario como verificado
+func MarkEmailVerified(userID int) error {
+    user, err := FindByID(userID)
+    if err != nil {
+        return err
+    }
+    
+    user.EmailVerified = true
+    users[userID] = *user
+    return nil
+}
+
+// Cambio el email de un usuario por uno ya verificado
+func ChangeEmail(userID int, newEmail string) error {
+    user, err := FindByID(userID)
+    if err != nil {
+        return err
+    }
+    
+    newEmail = strings.TrimSpace(newEmail)
+    if !utils.IsValidEmail(newEmail) {
+        return errors.ErrInvalidEmail
+    }
+    if id, exists := byEmail[emailKey(newEmail)]; exists && id != userID {
+        return errors.ErrEmailExists
+    }
+    
+    delete(byEmail, emailKey(user.Email))
+    byEmail[emailKey(newEmail)] = userID
+    user.Email = newEmail
+    user.EmailVerified = true
+    users[userID] = *user
+    return nil
+}
+
+// Cambio la contraseña de un usuario
+func SetPassword(userID int, password string) error {
+    user, err := FindByID(userID)
+    if err != nil {
+        return err
+    }
+    if !utils.IsValidPassword(password) {
+        return errors.ErrInvalidPassword
+    }
+    
+    user.Password = password
+    user.MustResetPassword = false
+    users[userID] = *user
+    return nil
+}
+
+// Reemplazo los roles de un usuario; la validación la hace rbac
+func SetRoles(userID int, roles []string) error {
+    user, err := FindByID(userID)
+    if err != nil {
+        return err
+    }
+    
+    user.Roles = append([]string(nil), roles...)
+    users[userID] = *user
+    return nil
+}
+
+// Cambio el plan de un usuario
+func SetPlan(userID int, plan string) error {
+    user, err := FindByID(userID)
+    if err != nil {
+        return err
+    }
+    if plan != "Free" && plan != "Premium" {
+        return errors.NewAppError("USER_004", "Plan inválido", plan)
+    }
+    
+    user.Plan = plan
+    users[userID] = *user
+    return nil
+}
+
+// Actualizo el nombre y la clasificación por edad de un usuario; la
+// clasificación la valida quien llama
+func UpdateUser(userID int, name, ageRating string) error {
+    user, err := FindByID(userID)
+    if err != nil {
+        return err
+    }
+    name = textnorm.Clean(name)
+    if !utils.IsValidName(name) {
+        return errors.ErrInvalidName
+    }
+    
+    user.Name = name
+    user.AgeRating = ageRating
+    users[userID] = *user
+    return nil
+}
+
+// Deshabilito o rehabilito una cuenta
+func SetDisabled(userID int, disabled bool) error {
+    user, err := FindByID(userID)
+    if err != nil {
+        return err
+    }
+    
+    user.Disabled = disabled
+    users[userID] = *user
+    return nil
+}
+
+// Obligo a un usuario a restablecer su contraseña; SetPassword lo levanta
+func RequirePasswordReset(userID int) error {
+    user, err := FindByID(userID)
+    if err != nil {
+        return err
+    }
+    
+    user.MustResetPassword = true
+    users[userID] = *user
+    return nil
+}
+
 // Actualizo el último inicio de sesión
 func UpdateLastLogin(userID int) error {
     user, err := FindByID(userID)
@@ -117,4 +251,16 @@ func UpdateLastLogin(userID int) error {
     user.LastLogin = time.Now()
     users[userID] = *user
     return nil
-}
\ No newline at end of file
+}
+
+// Elimino un usuario del sistema
+func DeleteUser(userID int) error {
+    user, err := FindByID(userID)
+    if err != nil {
+        return err
+    }
+    
+    delete(byEmail, emailKey(user.Email))
+    delete(users, userID)
+    return nil
+}
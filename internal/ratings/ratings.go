@@ -3,29 +3,95 @@ package ratings
 import (
     "fmt"
     "math"
+    "sort"
+    "time"
     "SDGEStreaming/internal/categories"
     "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/profiles"
     "SDGEStreaming/internal/utils"
 )
 
+// Calificación registrada en un momento dado, usada para medir tendencias
+type Activity struct {
+    Ref       categories.ContentRef
+    UserID    int
+    Rating    float64
+    Withdrawn bool // el usuario retiró su calificación
+    At        time.Time
+}
+
+// Suma y cantidad de calificaciones de un contenido, mantenidas en cada cambio
+type aggregate struct {
+    sum   float64
+    count int
+}
+
 // Variables para almacenar calificaciones
 var (
-    contentRatings = make(map[int][]categories.UserRating) // contentID -> []ratings
+    contentRatings = make(map[categories.ContentRef][]categories.UserRating) // contenido -> []ratings
+    aggregates     = make(map[categories.ContentRef]*aggregate)              // contenido -> suma y cantidad
+    activity       []Activity                                                // calificaciones en orden cronológico
+    excluded       = make(map[categories.ContentRef]map[int]bool)            // contenido -> usuarios excluidos del promedio
+    revision       = 0                                                       // aumenta con cada cambio de lo que cuenta en los promedios
 )
 
+// Sumo o resto una calificación del agregado de un contenido
+func adjustAggregate(ref categories.ContentRef, sum float64, count int) {
+    agg := aggregates[ref]
+    if agg == nil {
+        agg = &aggregate{}
+        aggregates[ref] = agg
+    }
+    agg.sum += sum
+    agg.count += count
+    if agg.count == 0 {
+        delete(aggregates, ref)
+    }
+    revision++
+}
+
+// Obtengo la revisión de las calificaciones que cuentan en los promedios, para
+// saber si cambiaron sin compararlas
+func Revision() int {
+    return revision
+}
+
+// Verifico si la calificación de un usuario está excluida del promedio de un contenido
+func IsExcluded(ref categories.ContentRef, userID int) bool {
+    return excluded[ref][userID]
+}
+
 // Califico contenido
-func RateContent(contentID, userID int, rating float64) (string, error) {
+func RateContent(ref categories.ContentRef, userID int, rating float64) (string, error) {
     // Valido rating
     if rating < 1.0 || rating > 10.0 {
         return "", errors.ErrInvalidRating
     }
     
-    // Agrego o actualizo la calificación
-    ratings := contentRatings[contentID]
+    // Solo califican las cuentas con el email verificado
+    user, err := profiles.FindByID(userID)
+    if err != nil {
+        return "", err
+    }
+    if !user.EmailVerified {
+        return "", errors.ErrEmailNotVerified
+    }
+    
+    now := time.Now()
+    activity = append(activity, Activity{Ref: ref, UserID: userID, Rating: rating, At: now})
+    
+    // Agrego o actualizo la calificación, guardando el valor anterior en el historial
+    ratings := contentRatings[ref]
     for i, r := range ratings {
         if r.UserID == userID {
             oldRating := r.Rating
-            contentRatings[contentID][i] = categories.UserRating{UserID: userID, Rating: rating}
+            updated := &contentRatings[ref][i]
+            updated.History = append(updated.History, categories.RatingChange{Rating: oldRating, ChangedAt: now})
+            updated.Rating = rating
+            updated.UpdatedAt = now
+            if !IsExcluded(ref, userID) {
+                adjustAggregate(ref, rating-oldRating, 0)
+            }
             
             oldStr := utils.FormatRating(oldRating)
             newStr := utils.FormatRating(rating)
@@ -34,36 +100,189 @@ func RateContent(contentID, userID int, rating float64) (string, error) {
     }
     
     // Nueva calificación
-    contentRatings[contentID] = append(contentRatings[contentID], categories.UserRating{UserID: userID, Rating: rating})
+    contentRatings[ref] = append(contentRatings[ref], categories.UserRating{
+        UserID:    userID,
+        Rating:    rating,
+        CreatedAt: now,
+        UpdatedAt: now,
+    })
+    // Una exclusión sigue vigente aunque el usuario retire y vuelva a calificar
+    if !IsExcluded(ref, userID) {
+        adjustAggregate(ref, rating, 1)
+    }
     
     return "Contenido calificado exitosamente", nil
 }
 
-// Obtengo calificaciones para un contenido
-func GetRatings(contentID int) ([]categories.UserRating, error) {
-    ratings, exists := contentRatings[contentID]
+// Retiro la calificación que un usuario dio a un contenido
+func RemoveRating(ref categories.ContentRef, userID int) error {
+    list := contentRatings[ref]
+    for i, r := range list {
+        if r.UserID != userID {
+            continue
+        }
+        
+        contentRatings[ref] = append(list[:i], list[i+1:]...)
+        if len(contentRatings[ref]) == 0 {
+            delete(contentRatings, ref)
+        }
+        if !IsExcluded(ref, userID) {
+            adjustAggregate(ref, -r.Rating, -1)
+        }
+        
+        activity = append(activity, Activity{Ref: ref, UserID: userID, Rating: r.Rating, Withdrawn: true, At: time.Now()})
+        return nil
+    }
+    return errors.NewAppError("RATING_002", "Calificación no encontrada", "No ha calificado este contenido")
+}
+
+// Retiro todas las calificaciones de un usuario, por ejemplo al eliminar su
+// cuenta. Devuelvo cuántas retiré
+func RemoveAllByUser(userID int) int {
+    removed := 0
+    for _, rc := range ByUser(userID) {
+        if RemoveRating(rc.Ref, userID) == nil {
+            removed++
+        }
+        if excluded[rc.Ref] != nil {
+            delete(excluded[rc.Ref], userID)
+        }
+    }
+    return removed
+}
+
+// Excluyo la calificación de un usuario del promedio de un contenido sin borrarla
+func Exclude(ref categories.ContentRef, userID int) error {
+    r, exists := GetUserRating(ref, userID)
+    if !exists {
+        return errors.NewAppError("RATING_002", "Calificación no encontrada", "El usuario no calificó este contenido")
+    }
+    if IsExcluded(ref, userID) {
+        return errors.NewAppError("RATING_003", "La calificación ya está excluida", "")
+    }
+    
+    if excluded[ref] == nil {
+        excluded[ref] = make(map[int]bool)
+    }
+    excluded[ref][userID] = true
+    adjustAggregate(ref, -r.Rating, -1)
+    return nil
+}
+
+// Vuelvo a contar en el promedio una calificación excluida
+func Include(ref categories.ContentRef, userID int) error {
+    if !IsExcluded(ref, userID) {
+        return errors.NewAppError("RATING_004", "La calificación no está excluida", "")
+    }
+    
+    delete(excluded[ref], userID)
+    if len(excluded[ref]) == 0 {
+        delete(excluded, ref)
+    }
+    // Si el usuario retiró la calificación mientras estaba excluida no hay nada que sumar
+    if r, exists := GetUserRating(ref, userID); exists {
+        adjustAggregate(ref, r.Rating, 1)
+    }
+    return nil
+}
+
+// Obtengo calificaciones para un contenido, incluidas las excluidas del promedio
+func GetRatings(ref categories.ContentRef) ([]categories.UserRating, error) {
+    ratings, exists := contentRatings[ref]
     if !exists {
         return nil, errors.ErrContentNotFound
     }
     return ratings, nil
 }
 
-// Obtengo el promedio de calificaciones
-func GetAverage(contentID int) (float64, error) {
-    ratings, err := GetRatings(contentID)
-    if err != nil {
-        return 0, err
+// Obtengo el promedio de calificaciones sin redondear; el redondeo es solo para mostrarlo
+func GetAverage(ref categories.ContentRef) (float64, error) {
+    agg, exists := aggregates[ref]
+    if !exists {
+        return 0, errors.ErrContentNotFound
     }
-    
-    if len(ratings) == 0 {
-        return 0.0, nil
+    return agg.sum / float64(agg.count), nil
+}
+
+// Obtengo la suma y la cantidad de calificaciones de un contenido
+func Aggregate(ref categories.ContentRef) (float64, int) {
+    agg, exists := aggregates[ref]
+    if !exists {
+        return 0, 0
     }
-    
-    var sum float64
-    for _, r := range ratings {
-        sum += r.Rating
+    return agg.sum, agg.count
+}
+
+// Obtengo una copia de todas las calificaciones que cuentan en los promedios
+func All() map[categories.ContentRef][]categories.UserRating {
+    all := make(map[categories.ContentRef][]categories.UserRating, len(contentRatings))
+    for ref, list := range contentRatings {
+        for _, r := range list {
+            if !IsExcluded(ref, r.UserID) {
+                all[ref] = append(all[ref], r)
+            }
+        }
     }
-    
-    avg := math.Round(sum/float64(len(ratings))*10) / 10
-    return avg, nil
-}
\ No newline at end of file
+    return all
+}
+
+// Calificación de un usuario junto al contenido calificado
+type RatedContent struct {
+    Ref    categories.ContentRef
+    Rating categories.UserRating
+}
+
+// Obtengo las calificaciones que dio un usuario, de la más reciente a la más antigua
+func ByUser(userID int) []RatedContent {
+    var rated []RatedContent
+    for ref, list := range contentRatings {
+        for _, r := range list {
+            if r.UserID == userID {
+                rated = append(rated, RatedContent{Ref: ref, Rating: r})
+            }
+        }
+    }
+    sort.Slice(rated, func(i, j int) bool {
+        return rated[i].Rating.UpdatedAt.After(rated[j].Rating.UpdatedAt)
+    })
+    return rated
+}
+
+// Obtengo la calificación que un usuario dio a un contenido
+func GetUserRating(ref categories.ContentRef, userID int) (*categories.UserRating, bool) {
+    for _, r := range contentRatings[ref] {
+        if r.UserID == userID {
+            return &r, true
+        }
+    }
+    return nil, false
+}
+
+// Cuento las calificaciones de un contenido
+func Count(ref categories.ContentRef) int {
+    _, count := Aggregate(ref)
+    return count
+}
+
+// Obtengo la distribución de calificaciones de un contenido: la posición i
+// cuenta las calificaciones que redondeadas valen i+1, sin las excluidas
+func Distribution(ref categories.ContentRef) [10]int {
+    var buckets [10]int
+    for _, r := range contentRatings[ref] {
+        if IsExcluded(ref, r.UserID) {
+            continue
+        }
+        bucket := int(math.Round(r.Rating)) - 1
+        buckets[max(0, min(9, bucket))]++
+    }
+    return buckets
+}
+
+// Obtengo las calificaciones registradas desde un momento dado
+func ActivitySince(since time.Time) []Activity {
+    // La actividad está en orden cronológico: busco el primer evento a partir de since
+    i := sort.Search(len(activity), func(i int) bool {
+        return !activity[i].At.Before(since)
+    })
+    return append([]Activity(nil), activity[i:]...)
+}
@@ -0,0 +1,161 @@
+package ratings
+
+import (
+    "fmt"
+    "math"
+    "testing"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/profiles"
+)
+
+// Usuarios registrados por las pruebas, para no repetir emails
+var testUsers = 0
+
+// Registro usuarios verificados para calificar
+func verifiedUsers(t *testing.T, n int) []int {
+    t.Helper()
+    var ids []int
+    for i := 0; i < n; i++ {
+        testUsers++
+        user, err := profiles.AddUser("Usuario Prueba", 30, fmt.Sprintf("calificador%d@prueba.com", testUsers), "clave123", "Free", "Adulto", nil)
+        if err != nil {
+            t.Fatal(err)
+        }
+        profiles.MarkEmailVerified(user.ID)
+        ids = append(ids, user.ID)
+    }
+    return ids
+}
+
+// Recalculo suma y cantidad desde cero para comparar con el agregado incremental
+func recompute(ref categories.ContentRef) (float64, int) {
+    var sum float64
+    var count int
+    for _, r := range contentRatings[ref] {
+        if !IsExcluded(ref, r.UserID) {
+            sum += r.Rating
+            count++
+        }
+    }
+    return sum, count
+}
+
+// Verifico el agregado después de cada alta, cambio, retiro, exclusión e inclusión
+func TestAggregateIncremental(t *testing.T) {
+    users := verifiedUsers(t, 3)
+    a, b, c := users[0], users[1], users[2]
+    ref := categories.ContentRef{Kind: categories.KindAudiovisual, ID: 9001}
+
+    steps := []struct {
+        name    string
+        apply   func() error
+        sum     float64
+        count   int
+        average float64
+    }{
+        {"primera calificación", func() error { _, err := RateContent(ref, a, 8); return err }, 8, 1, 8},
+        {"segunda calificación", func() error { _, err := RateContent(ref, b, 6); return err }, 14, 2, 7},
+        {"cambio de calificación", func() error { _, err := RateContent(ref, a, 10); return err }, 16, 2, 8},
+        {"tercera calificación", func() error { _, err := RateContent(ref, c, 1); return err }, 17, 3, 17.0 / 3},
+        {"excluyo una calificación", func() error { return Exclude(ref, c) }, 16, 2, 8},
+        {"cambio una calificación excluida", func() error { _, err := RateContent(ref, c, 2); return err }, 16, 2, 8},
+        {"incluyo la calificación cambiada", func() error { return Include(ref, c) }, 18, 3, 6},
+        {"retiro una calificación", func() error { return RemoveRating(ref, b) }, 12, 2, 6},
+        {"excluyo y retiro", func() error {
+            if err := Exclude(ref, a); err != nil {
+                return err
+            }
+            return RemoveRating(ref, a)
+        }, 2, 1, 2},
+        {"incluyo una calificación retirada", func() error { return Include(ref, a) }, 2, 1, 2},
+        {"retiro la última", func() error { return RemoveRating(ref, c) }, 0, 0, 0},
+    }
+    for _, step := range steps {
+        if err := step.apply(); err != nil {
+            t.Fatalf("%s: %v", step.name, err)
+        }
+        sum, count := Aggregate(ref)
+        if math.Abs(sum-step.sum) > 1e-9 || count != step.count {
+            t.Errorf("%s: agregado (%g, %d), quiero (%g, %d)", step.name, sum, count, step.sum, step.count)
+        }
+        if wantSum, wantCount := recompute(ref); math.Abs(sum-wantSum) > 1e-9 || count != wantCount {
+            t.Errorf("%s: agregado (%g, %d) distinto del recalculado (%g, %d)", step.name, sum, count, wantSum, wantCount)
+        }
+        avg, err := GetAverage(ref)
+        if step.count == 0 {
+            if err == nil {
+                t.Errorf("%s: GetAverage sin calificaciones = %g, quiero error", step.name, avg)
+            }
+        } else if math.Abs(avg-step.average) > 1e-9 {
+            t.Errorf("%s: promedio %g, quiero %g", step.name, avg, step.average)
+        }
+    }
+}
+
+// Verifico los errores de excluir e incluir en estados inválidos
+func TestExcludeIncludeErrors(t *testing.T) {
+    user := verifiedUsers(t, 1)[0]
+    ref := categories.ContentRef{Kind: categories.KindAudio, ID: 9002}
+
+    if err := Exclude(ref, user); err == nil {
+        t.Error("Exclude sin calificación no falló")
+    }
+    if _, err := RateContent(ref, user, 5); err != nil {
+        t.Fatal(err)
+    }
+    if err := Include(ref, user); err == nil {
+        t.Error("Include de una calificación no excluida no falló")
+    }
+    if err := Exclude(ref, user); err != nil {
+        t.Fatal(err)
+    }
+    if err := Exclude(ref, user); err == nil {
+        t.Error("Exclude dos veces no falló")
+    }
+}
+
+// Verifico que retirar una calificación inexistente falle sin tocar el agregado
+func TestRemoveRatingNotFound(t *testing.T) {
+    user := verifiedUsers(t, 1)[0]
+    ref := categories.ContentRef{Kind: categories.KindAudio, ID: 9002}
+
+    if err := RemoveRating(ref, user); err == nil {
+        t.Error("RemoveRating sin calificación no falló")
+    }
+    if _, err := RateContent(ref, user, 5); err != nil {
+        t.Fatal(err)
+    }
+    if err := RemoveRating(ref, user+1); err == nil {
+        t.Error("RemoveRating de otro usuario no falló")
+    }
+    if sum, count := Aggregate(ref); sum != 5 || count != 1 {
+        t.Errorf("agregado (%g, %d), quiero (5, 1)", sum, count)
+    }
+}
+
+
+// Verifico que retirar todas las calificaciones de un usuario corrija los agregados
+func TestRemoveAllByUser(t *testing.T) {
+    users := verifiedUsers(t, 2)
+    refs := []categories.ContentRef{
+        {Kind: categories.KindAudiovisual, ID: 9003},
+        {Kind: categories.KindAudio, ID: 9003},
+    }
+    for _, ref := range refs {
+        RateContent(ref, users[0], 9)
+        RateContent(ref, users[1], 3)
+    }
+    Exclude(refs[1], users[0])
+
+    if removed := RemoveAllByUser(users[0]); removed != 2 {
+        t.Errorf("RemoveAllByUser = %d, quiero 2", removed)
+    }
+    for _, ref := range refs {
+        if sum, count := Aggregate(ref); sum != 3 || count != 1 {
+            t.Errorf("%v: agregado (%g, %d), quiero (3, 1)", ref, sum, count)
+        }
+    }
+    if IsExcluded(refs[1], users[0]) {
+        t.Error("la exclusión del usuario eliminado sigue vigente")
+    }
+}
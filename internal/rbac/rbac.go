@@ -0,0 +1,167 @@
+package rbac
+
+import (
+    "sort"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/profiles"
+)
+
+// Permisos que se chequean en cada operación administrativa
+const (
+    PermUsersView       = "usuarios.ver"
+    PermUsersManage     = "usuarios.gestionar"
+    PermUsersDelete     = "usuarios.eliminar"
+    PermRolesAssign     = "roles.asignar"
+    PermPlansManage     = "planes.gestionar"
+    PermContentManage   = "contenido.gestionar"
+    PermGenresManage    = "generos.gestionar"
+    PermReviewsModerate = "resenas.moderar"
+    PermRatingsAudit    = "calificaciones.auditar"
+    PermAccessManage    = "accesos.gestionar"
+    PermAuditView       = "auditoria.ver"
+)
+
+// Roles en el orden en que se muestran
+var Roles = []string{
+    categories.RoleSuperAdmin,
+    categories.RoleContentEditor,
+    categories.RoleModerator,
+    categories.RoleSupport,
+    categories.RoleBilling,
+}
+
+// Nombre de cada rol para mostrar
+var RoleLabels = map[string]string{
+    categories.RoleSuperAdmin:    "Superadministrador",
+    categories.RoleContentEditor: "Editor de contenido",
+    categories.RoleModerator:     "Moderador",
+    categories.RoleSupport:       "Agente de soporte",
+    categories.RoleBilling:       "Facturación",
+}
+
+// Permisos que otorga cada rol; el superadministrador los tiene todos
+var rolePermissions = map[string][]string{
+    categories.RoleSuperAdmin: {
+        PermUsersView, PermUsersManage, PermUsersDelete, PermRolesAssign, PermPlansManage, PermContentManage,
+        PermGenresManage, PermReviewsModerate, PermRatingsAudit, PermAccessManage, PermAuditView,
+    },
+    categories.RoleContentEditor: {PermContentManage, PermGenresManage},
+    categories.RoleModerator:     {PermReviewsModerate, PermRatingsAudit},
+    categories.RoleSupport:       {PermUsersView, PermUsersManage, PermAccessManage},
+    categories.RoleBilling:       {PermUsersView, PermPlansManage},
+}
+
+// Verifico que un rol exista
+func IsValidRole(role string) bool {
+    _, exists := rolePermissions[role]
+    return exists
+}
+
+// Obtengo los permisos que otorgan los roles de un usuario
+func PermissionsOf(userID int) map[string]bool {
+    perms := make(map[string]bool)
+    user, err := profiles.FindByID(userID)
+    if err != nil {
+        return perms
+    }
+    for _, role := range user.Roles {
+        for _, p := range rolePermissions[role] {
+            perms[p] = true
+        }
+    }
+    return perms
+}
+
+// Verifico si un usuario tiene un permiso
+func Can(userID int, perm string) bool {
+    return PermissionsOf(userID)[perm]
+}
+
+// Devuelvo ErrPermissionDenied si el usuario no tiene el permiso
+func Require(userID int, perm string) error {
+    if !Can(userID, perm) {
+        return errors.ErrPermissionDenied
+    }
+    return nil
+}
+
+// Verifico si un usuario tiene algún rol administrativo
+func IsStaff(userID int) bool {
+    user, err := profiles.FindByID(userID)
+    return err == nil && len(user.Roles) > 0
+}
+
+// Verifico si un usuario tiene un rol
+func HasRole(user categories.User, role string) bool {
+    for _, r := range user.Roles {
+        if r == role {
+            return true
+        }
+    }
+    return false
+}
+
+// Asigno los roles de un usuario. No se puede dejar al sistema sin superadministradores
+func AssignRoles(userID int, roles []string) error {
+    if _, err := profiles.FindByID(userID); err != nil {
+        return err
+    }
+
+    seen := make(map[string]bool)
+    var clean []string
+    for _, r := range roles {
+        if !IsValidRole(r) {
+            return errors.NewAppError("RBAC_001", "Rol inválido", r)
+        }
+        if !seen[r] {
+            seen[r] = true
+            clean = append(clean, r)
+        }
+    }
+    // Mantengo el orden de Roles para mostrarlos siempre igual
+    sort.Slice(clean, func(i, j int) bool {
+        return roleIndex(clean[i]) < roleIndex(clean[j])
+    })
+
+    if !seen[categories.RoleSuperAdmin] {
+        if err := CheckRemoval(userID); err != nil {
+            return err
+        }
+    }
+    return profiles.SetRoles(userID, clean)
+}
+
+// Verifico que un usuario no sea el último superadministrador habilitado antes
+// de quitarle el rol, deshabilitarlo o eliminarlo
+func CheckRemoval(userID int) error {
+    user, err := profiles.FindByID(userID)
+    if err != nil {
+        return err
+    }
+    if HasRole(*user, categories.RoleSuperAdmin) && !user.Disabled && countSuperAdmins() == 1 {
+        return errors.NewAppError("RBAC_002", "Debe quedar al menos un superadministrador", user.Email)
+    }
+    return nil
+}
+
+// Cuento los superadministradores habilitados del sistema
+func countSuperAdmins() int {
+    count := 0
+    for _, u := range profiles.GetAllUsers() {
+        if HasRole(u, categories.RoleSuperAdmin) && !u.Disabled {
+            count++
+        }
+    }
+    return count
+}
+
+// Posición de un rol en Roles
+func roleIndex(role string) int {
+    for i, r := range Roles {
+        if r == role {
+            return i
+        }
+    }
+    return len(Roles)
+}
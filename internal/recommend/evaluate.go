@@ -0,0 +1,159 @@
+package recommend
+
+import (
+    "fmt"
+    "math/rand"
+    "sort"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+)
+
+// Resultado de una evaluación offline: qué parte de las calificaciones separadas
+// que le gustaron a cada usuario aparece en su top-K, promediado entre usuarios
+type Evaluation struct {
+    Users            int // usuarios con calificaciones relevantes separadas
+    K                int
+    Threshold        float64
+    Precision        float64 // filtrado colaborativo
+    Recall           float64
+    PopularPrecision float64 // referencia por popularidad
+    PopularRecall    float64
+}
+
+// Calificación separada para evaluar
+type heldOut struct {
+    ref    categories.ContentRef
+    rating float64
+}
+
+// Separo una fracción de las calificaciones de cada usuario para evaluar
+func split(data map[categories.ContentRef][]categories.UserRating, holdout float64, rng *rand.Rand) (map[categories.ContentRef][]categories.UserRating, map[int][]heldOut) {
+    // Recorro en orden fijo para que la misma semilla dé la misma separación
+    refs := make([]categories.ContentRef, 0, len(data))
+    for ref := range data {
+        refs = append(refs, ref)
+    }
+    sort.Slice(refs, func(i, j int) bool {
+        return refLess(refs[i], refs[j])
+    })
+
+    train := make(map[categories.ContentRef][]categories.UserRating)
+    test := make(map[int][]heldOut)
+    for _, ref := range refs {
+        for _, r := range data[ref] {
+            if rng.Float64() < holdout {
+                test[r.UserID] = append(test[r.UserID], heldOut{ref, r.Rating})
+                continue
+            }
+            train[ref] = append(train[ref], r)
+        }
+    }
+    return train, test
+}
+
+// Recomendador de referencia: los contenidos con mejor promedio, de mayor a menor
+func popular(train map[categories.ContentRef][]categories.UserRating) []categories.ContentRef {
+    type scored struct {
+        ref   categories.ContentRef
+        score float64
+    }
+    var all []scored
+    for ref, list := range train {
+        var sum float64
+        for _, r := range list {
+            sum += r.Rating
+        }
+        // Promedio suavizado para que pocos votos no dominen
+        all = append(all, scored{ref, (sum + 6*5) / float64(len(list)+5)})
+    }
+    sort.Slice(all, func(i, j int) bool {
+        if all[i].score != all[j].score {
+            return all[i].score > all[j].score
+        }
+        return refLess(all[i].ref, all[j].ref)
+    })
+    refs := make([]categories.ContentRef, len(all))
+    for i, s := range all {
+        refs[i] = s.ref
+    }
+    return refs
+}
+
+// Evalúo el recomendador separando una fracción de las calificaciones de cada
+// usuario, entrenando con el resto y midiendo precision@K y recall@K contra una
+// referencia por popularidad. Un contenido gustó si su calificación llega al umbral
+func Evaluate(data map[categories.ContentRef][]categories.UserRating, holdout float64, k int, threshold float64, rng *rand.Rand) (Evaluation, error) {
+    if holdout <= 0 || holdout >= 1 {
+        return Evaluation{}, errors.NewAppError("RECOMMEND_001", "Parámetros de evaluación inválidos", fmt.Sprintf("la fracción separada debe estar entre 0 y 1, no %g", holdout))
+    }
+    if k <= 0 {
+        return Evaluation{}, errors.NewAppError("RECOMMEND_001", "Parámetros de evaluación inválidos", fmt.Sprintf("K debe ser mayor a 0, no %d", k))
+    }
+    if threshold < 1 || threshold > 10 {
+        return Evaluation{}, errors.NewAppError("RECOMMEND_001", "Parámetros de evaluación inválidos", fmt.Sprintf("el umbral debe estar entre 1 y 10, no %g", threshold))
+    }
+
+    train, test := split(data, holdout, rng)
+    trained := make(map[int]map[categories.ContentRef]bool)
+    for ref, list := range train {
+        for _, r := range list {
+            if trained[r.UserID] == nil {
+                trained[r.UserID] = make(map[categories.ContentRef]bool)
+            }
+            trained[r.UserID][ref] = true
+        }
+    }
+
+    model := Train(train)
+    ranking := popular(train)
+
+    result := Evaluation{K: k, Threshold: threshold}
+    for userID, separated := range test {
+        relevant := make(map[categories.ContentRef]bool)
+        for _, s := range separated {
+            if s.rating >= threshold {
+                relevant[s.ref] = true
+            }
+        }
+        if len(relevant) == 0 {
+            continue
+        }
+        result.Users++
+
+        var hits int
+        for _, r := range model.Recommend(userID, k, nil) {
+            if relevant[r.Ref] {
+                hits++
+            }
+        }
+        result.Precision += float64(hits) / float64(k)
+        result.Recall += float64(hits) / float64(len(relevant))
+
+        // La referencia tampoco recomienda lo que el usuario ya calificó
+        hits = 0
+        shown := 0
+        for _, ref := range ranking {
+            if shown == k {
+                break
+            }
+            if trained[userID][ref] {
+                continue
+            }
+            shown++
+            if relevant[ref] {
+                hits++
+            }
+        }
+        result.PopularPrecision += float64(hits) / float64(k)
+        result.PopularRecall += float64(hits) / float64(len(relevant))
+    }
+
+    if result.Users > 0 {
+        n := float64(result.Users)
+        result.Precision /= n
+        result.Recall /= n
+        result.PopularPrecision /= n
+        result.PopularRecall /= n
+    }
+    return result, nil
+}
@@ -0,0 +1,65 @@
+package recommend
+
+import (
+    "math/rand"
+    "testing"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+)
+
+// Calificaciones de dos grupos de usuarios con gustos opuestos sobre dos grupos de contenidos
+func polarized() map[categories.ContentRef][]categories.UserRating {
+    data := make(map[categories.ContentRef][]categories.UserRating)
+    for u := 1; u <= 40; u++ {
+        for i := 1; i <= 20; i++ {
+            rating := 2.0
+            if (u%2 == 0) == (i%2 == 0) {
+                rating = 9
+            }
+            ref := categories.ContentRef{Kind: categories.KindAudiovisual, ID: i}
+            data[ref] = append(data[ref], categories.UserRating{UserID: u, Rating: rating})
+        }
+    }
+    return data
+}
+
+// Verifico que los parámetros fuera de rango se rechacen
+func TestEvaluateInvalidParameters(t *testing.T) {
+    tests := []struct {
+        holdout   float64
+        k         int
+        threshold float64
+    }{
+        {0, 10, 7},
+        {1, 10, 7},
+        {-0.5, 10, 7},
+        {0.2, 0, 7},
+        {0.2, 10, 0},
+        {0.2, 10, 11},
+    }
+    for _, tt := range tests {
+        _, err := Evaluate(polarized(), tt.holdout, tt.k, tt.threshold, rand.New(rand.NewSource(1)))
+        if appErr, ok := err.(*errors.AppError); !ok || appErr.Code != "RECOMMEND_001" {
+            t.Errorf("Evaluate(holdout=%g, k=%d, umbral=%g) = %v, quiero RECOMMEND_001", tt.holdout, tt.k, tt.threshold, err)
+        }
+    }
+}
+
+// Verifico que con gustos marcados el filtrado colaborativo supere a la popularidad
+// y que la misma semilla dé el mismo resultado
+func TestEvaluate(t *testing.T) {
+    first, err := Evaluate(polarized(), 0.3, 3, 7, rand.New(rand.NewSource(1)))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if first.Users == 0 {
+        t.Fatal("no se evaluó ningún usuario")
+    }
+    if first.Precision <= first.PopularPrecision {
+        t.Errorf("precision@3 = %.3f, quiero más que la referencia %.3f", first.Precision, first.PopularPrecision)
+    }
+    second, _ := Evaluate(polarized(), 0.3, 3, 7, rand.New(rand.NewSource(1)))
+    if second.Users != first.Users {
+        t.Errorf("la misma semilla evaluó %d y %d usuarios", first.Users, second.Users)
+    }
+}
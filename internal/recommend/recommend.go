@@ -0,0 +1,221 @@
+package recommend
+
+import (
+    "math"
+    "sort"
+    "SDGEStreaming/internal/catalog"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/contentclass"
+    "SDGEStreaming/internal/ratings"
+)
+
+// Parámetros del modelo de similitud entre contenidos
+const (
+    maxNeighbors = 30  // vecinos que guardo por contenido
+    shrinkage    = 5.0 // castigo a similitudes con pocos usuarios en común
+
+    predictionShrinkage = 1.0 // castigo a estimaciones basadas en pocos vecinos
+)
+
+// Contenido recomendado con la calificación que estimo para el usuario
+type Recommendation struct {
+    Ref     categories.ContentRef
+    Score   float64               // calificación estimada
+    Because categories.ContentRef // contenido calificado que más influyó
+}
+
+// Vecino de un contenido con su similitud
+type neighbor struct {
+    ref        categories.ContentRef
+    similarity float64
+}
+
+// Modelo de filtrado colaborativo ítem a ítem entrenado sobre calificaciones
+type Model struct {
+    neighbors   map[categories.ContentRef][]neighbor
+    userRatings map[int]map[categories.ContentRef]float64
+    userMeans   map[int]float64
+}
+
+// Variables globales para el modelo entrenado con las calificaciones actuales
+var (
+    trained         *Model
+    trainedRevision = -1 // revisión de las calificaciones usada para entrenar
+)
+
+// Par ordenado de contenidos para acumular similitudes
+type pair struct {
+    a, b categories.ContentRef
+}
+
+// Acumuladores de la similitud coseno ajustada de un par
+type pairStats struct {
+    dot, normA, normB float64
+    common            int
+}
+
+// Ordeno dos referencias para que cada par se cuente una sola vez
+func refLess(a, b categories.ContentRef) bool {
+    if a.Kind != b.Kind {
+        return a.Kind < b.Kind
+    }
+    return a.ID < b.ID
+}
+
+// Entreno el modelo calculando la similitud coseno ajustada entre contenidos
+func Train(data map[categories.ContentRef][]categories.UserRating) *Model {
+    m := &Model{
+        neighbors:   make(map[categories.ContentRef][]neighbor),
+        userRatings: make(map[int]map[categories.ContentRef]float64),
+        userMeans:   make(map[int]float64),
+    }
+
+    for ref, list := range data {
+        for _, r := range list {
+            if m.userRatings[r.UserID] == nil {
+                m.userRatings[r.UserID] = make(map[categories.ContentRef]float64)
+            }
+            m.userRatings[r.UserID][ref] = r.Rating
+        }
+    }
+
+    // Resto el promedio de cada usuario para compensar a quienes califican alto o bajo siempre
+    stats := make(map[pair]*pairStats)
+    for userID, rated := range m.userRatings {
+        var sum float64
+        for _, rating := range rated {
+            sum += rating
+        }
+        mean := sum / float64(len(rated))
+        m.userMeans[userID] = mean
+
+        refs := make([]categories.ContentRef, 0, len(rated))
+        for ref := range rated {
+            refs = append(refs, ref)
+        }
+        sort.Slice(refs, func(i, j int) bool { return refLess(refs[i], refs[j]) })
+
+        for i := 0; i < len(refs); i++ {
+            di := rated[refs[i]] - mean
+            for j := i + 1; j < len(refs); j++ {
+                dj := rated[refs[j]] - mean
+                key := pair{refs[i], refs[j]}
+                s := stats[key]
+                if s == nil {
+                    s = &pairStats{}
+                    stats[key] = s
+                }
+                s.dot += di * dj
+                s.normA += di * di
+                s.normB += dj * dj
+                s.common++
+            }
+        }
+    }
+
+    for key, s := range stats {
+        if s.normA == 0 || s.normB == 0 {
+            continue
+        }
+        similarity := s.dot / math.Sqrt(s.normA*s.normB)
+        similarity *= float64(s.common) / (float64(s.common) + shrinkage)
+        if similarity <= 0 {
+            continue
+        }
+        m.neighbors[key.a] = append(m.neighbors[key.a], neighbor{key.b, similarity})
+        m.neighbors[key.b] = append(m.neighbors[key.b], neighbor{key.a, similarity})
+    }
+
+    for ref, list := range m.neighbors {
+        sort.Slice(list, func(i, j int) bool {
+            if list[i].similarity != list[j].similarity {
+                return list[i].similarity > list[j].similarity
+            }
+            return refLess(list[i].ref, list[j].ref)
+        })
+        if len(list) > maxNeighbors {
+            list = list[:maxNeighbors]
+        }
+        m.neighbors[ref] = list
+    }
+    return m
+}
+
+// Recomiendo hasta n contenidos no calificados por el usuario; allow puede descartar candidatos
+func (m *Model) Recommend(userID, n int, allow func(ref categories.ContentRef) bool) []Recommendation {
+    rated := m.userRatings[userID]
+    if len(rated) == 0 || n <= 0 {
+        return nil
+    }
+    mean := m.userMeans[userID]
+
+    type accumulator struct {
+        weighted, weights, strongest float64
+        because                      categories.ContentRef
+    }
+    candidates := make(map[categories.ContentRef]*accumulator)
+    for ref, rating := range rated {
+        for _, nb := range m.neighbors[ref] {
+            if _, seen := rated[nb.ref]; seen {
+                continue
+            }
+            acc := candidates[nb.ref]
+            if acc == nil {
+                acc = &accumulator{}
+                candidates[nb.ref] = acc
+            }
+            acc.weighted += nb.similarity * (rating - mean)
+            acc.weights += nb.similarity
+            if contribution := nb.similarity * rating; contribution > acc.strongest {
+                acc.strongest = contribution
+                acc.because = ref
+            }
+        }
+    }
+
+    var recommendations []Recommendation
+    for ref, acc := range candidates {
+        if allow != nil && !allow(ref) {
+            continue
+        }
+        // Encojo hacia el promedio del usuario las estimaciones con poca evidencia
+        score := mean + acc.weighted/(acc.weights+predictionShrinkage)
+        recommendations = append(recommendations, Recommendation{
+            Ref:     ref,
+            Score:   math.Max(1.0, math.Min(10.0, score)),
+            Because: acc.because,
+        })
+    }
+
+    sort.Slice(recommendations, func(i, j int) bool {
+        if recommendations[i].Score != recommendations[j].Score {
+            return recommendations[i].Score > recommendations[j].Score
+        }
+        return refLess(recommendations[i].Ref, recommendations[j].Ref)
+    })
+    if len(recommendations) > n {
+        recommendations = recommendations[:n]
+    }
+    return recommendations
+}
+
+// Obtengo el modelo entrenado con las calificaciones actuales; solo vuelvo a
+// entrenar cuando alguna calificación cambió desde el último entrenamiento
+func currentModel() *Model {
+    if revision := ratings.Revision(); trained == nil || revision != trainedRevision {
+        trained = Train(ratings.All())
+        trainedRevision = revision
+    }
+    return trained
+}
+
+// Genero las recomendaciones "Recomendado para ti" de un usuario con las calificaciones actuales
+func ForUser(user categories.User, n int) []Recommendation {
+    return currentModel().Recommend(user.ID, n, func(ref categories.ContentRef) bool {
+        item, err := catalog.Get(ref)
+        if err != nil || !item.IsAvailable {
+            return false
+        }
+        return contentclass.CanAccessContent(user.Age, item.AgeRating)
+    })
+}
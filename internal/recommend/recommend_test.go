@@ -0,0 +1,61 @@
+package recommend
+
+import (
+    "testing"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/ratings"
+)
+
+// Verifico que el modelo se reutilice mientras las calificaciones no cambien y
+// se vuelva a entrenar cuando cambian
+func TestCurrentModelFollowsRatings(t *testing.T) {
+    var users []int
+    for _, email := range []string{"modelo1@prueba.com", "modelo2@prueba.com"} {
+        user, err := profiles.AddUser("Usuario Prueba", 30, email, "clave123", "Free", "Adulto", nil)
+        if err != nil {
+            t.Fatal(err)
+        }
+        profiles.MarkEmailVerified(user.ID)
+        users = append(users, user.ID)
+    }
+    ref := categories.ContentRef{Kind: categories.KindAudiovisual, ID: 1}
+    if _, err := ratings.RateContent(ref, users[0], 8); err != nil {
+        t.Fatal(err)
+    }
+
+    first := currentModel()
+    if again := currentModel(); again != first {
+        t.Error("se volvió a entrenar sin cambios en las calificaciones")
+    }
+
+    steps := []struct {
+        name   string
+        change func() error
+    }{
+        {"calificación nueva", func() error {
+            _, err := ratings.RateContent(ref, users[1], 3)
+            return err
+        }},
+        {"calificación cambiada", func() error {
+            _, err := ratings.RateContent(ref, users[1], 6)
+            return err
+        }},
+        {"calificación excluida", func() error { return ratings.Exclude(ref, users[0]) }},
+        {"calificación retirada", func() error { return ratings.RemoveRating(ref, users[1]) }},
+    }
+    previous := first
+    for _, step := range steps {
+        if err := step.change(); err != nil {
+            t.Fatalf("%s: %v", step.name, err)
+        }
+        model := currentModel()
+        if model == previous {
+            t.Errorf("%s: el modelo no se volvió a entrenar", step.name)
+        }
+        if again := currentModel(); again != model {
+            t.Errorf("%s: se volvió a entrenar dos veces", step.name)
+        }
+        previous = model
+    }
+}
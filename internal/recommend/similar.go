@@ -0,0 +1,132 @@
+package recommend
+
+import (
+    "math"
+    "sort"
+    "SDGEStreaming/internal/catalog"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/search"
+)
+
+// Peso de cada atributo en la similitud por metadatos
+const (
+    weightGenre   = 3.0
+    weightCreator = 2.5
+    weightType    = 1.5
+    weightYear    = 1.5
+    weightRating  = 1.0
+    weightKind    = 0.5
+    weightTags    = 1.5
+
+    yearWindow    = 10.0 // años de diferencia a partir de los cuales no hay parecido
+    minSimilarity = 0.25 // por debajo de este puntaje no se considera parecido
+)
+
+// Contenido parecido a otro con su puntaje de 0 a 1
+type Similarity struct {
+    Ref   categories.ContentRef
+    Score float64
+}
+
+// Calculo qué tan parecidos son dos contenidos según sus metadatos
+func metadataSimilarity(a, b catalog.Item) float64 {
+    var score, possible float64
+
+    // El género principal compartido cuenta completo; los demás géneros en
+    // común cuentan en proporción a cuánto se superponen
+    possible += weightGenre
+    if a.Genre == b.Genre {
+        score += weightGenre
+    } else {
+        score += weightGenre * 0.5 * overlap(a.Genres, b.Genres)
+    }
+
+    possible += weightType
+    if a.Type == b.Type {
+        score += weightType
+    }
+
+    possible += weightKind
+    if a.Ref.Kind == b.Ref.Kind {
+        score += weightKind
+    }
+
+    if a.Creator != "" && b.Creator != "" {
+        possible += weightCreator
+        if search.Fold(a.Creator) == search.Fold(b.Creator) {
+            score += weightCreator
+        }
+    }
+
+    // Solo comparo año y rating cuando ambos contenidos los tienen
+    if a.ReleaseYear > 0 && b.ReleaseYear > 0 {
+        possible += weightYear
+        distance := math.Abs(float64(a.ReleaseYear - b.ReleaseYear))
+        score += weightYear * math.Max(0, 1-distance/yearWindow)
+    }
+
+    if len(a.Tags) > 0 && len(b.Tags) > 0 {
+        possible += weightTags
+        score += weightTags * overlap(a.Tags, b.Tags)
+    }
+
+    if a.AverageRating > 0 && b.AverageRating > 0 {
+        possible += weightRating
+        score += weightRating * (1 - math.Abs(a.AverageRating-b.AverageRating)/9)
+    }
+
+    return score / possible
+}
+
+// Calculo la proporción de elementos en común entre dos listas (índice de Jaccard)
+func overlap(a, b []string) float64 {
+    if len(a) == 0 || len(b) == 0 {
+        return 0
+    }
+    set := make(map[string]bool, len(a))
+    for _, x := range a {
+        set[x] = true
+    }
+    common := 0
+    union := len(set)
+    for _, x := range b {
+        if set[x] {
+            common++
+        } else {
+            union++
+        }
+    }
+    return float64(common) / float64(union)
+}
+
+// Busco los n contenidos más parecidos a uno dado; funciona aunque no tenga calificaciones
+func Similar(ref categories.ContentRef, n int, allow func(item catalog.Item) bool) ([]Similarity, error) {
+    source, err := catalog.Get(ref)
+    if err != nil {
+        return nil, err
+    }
+
+    var similar []Similarity
+    for _, item := range catalog.All() {
+        if item.Ref == ref || !item.IsAvailable {
+            continue
+        }
+        if allow != nil && !allow(item) {
+            continue
+        }
+        if score := metadataSimilarity(source, item); score >= minSimilarity {
+            similar = append(similar, Similarity{Ref: item.Ref, Score: score})
+        }
+    }
+
+    sort.Slice(similar, func(i, j int) bool {
+        if similar[i].Score != similar[j].Score {
+            return similar[i].Score > similar[j].Score
+        }
+        return refLess(similar[i].Ref, similar[j].Ref)
+    })
+    if len(similar) > n {
+        similar = similar[:n]
+    }
+    return similar, nil
+}
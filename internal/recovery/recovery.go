@@ -0,0 +1,121 @@
+package recovery
+
+import (
+    "crypto/rand"
+    "crypto/sha256"
+    "fmt"
+    "strings"
+    "time"
+    "SDGEStreaming/internal/auth"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/mailer"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/sessions"
+    "SDGEStreaming/internal/utils"
+)
+
+// Vigencia de un código para restablecer la contraseña
+var TokenTTL = 30 * time.Minute
+
+// Código de restablecimiento emitido; solo guardo su hash
+type token struct {
+    UserID    int
+    ExpiresAt time.Time
+}
+
+// Variables globales para almacenamiento en memoria
+var (
+    tokens = make(map[[32]byte]token) // hash del código -> código vigente
+)
+
+// Mismo error para códigos inexistentes, usados o vencidos
+var errInvalidToken = errors.NewAppError("RESET_001", "Código inválido o vencido", "Solicite uno nuevo")
+
+// Obtengo el hash con el que guardo y busco un código
+func hashToken(t string) [32]byte {
+    return sha256.Sum256([]byte(strings.TrimSpace(t)))
+}
+
+// Descarto los códigos emitidos a un usuario, por ejemplo al eliminar su cuenta
+func DiscardTokens(userID int) {
+    for h, t := range tokens {
+        if t.UserID == userID {
+            delete(tokens, h)
+        }
+    }
+}
+
+// Pido restablecer la contraseña de un email. Para no revelar qué emails
+// están registrados respondo igual exista o no la cuenta, y un fallo al
+// enviar el correo tampoco se informa
+func RequestReset(email string) error {
+    email = strings.TrimSpace(email)
+    if !utils.IsValidEmail(email) {
+        return errors.ErrInvalidEmail
+    }
+    user, err := profiles.FindByEmail(email)
+    if err != nil {
+        return nil
+    }
+
+    body := "Recibimos un pedido para restablecer su contraseña de SDGEStreaming.\nSu código es %s y vence en %.0f minutos; se puede usar una sola vez.\nSi no lo pidió, ignore este mensaje: su contraseña no cambia."
+    issueToken(user.ID, user.Email, body)
+    return nil
+}
+
+// Obligo a un usuario a restablecer su contraseña: no puede iniciar sesión
+// hasta hacerlo, se cierran sus sesiones y le envío un código por email
+func ForceReset(userID int) error {
+    user, err := profiles.FindByID(userID)
+    if err != nil {
+        return err
+    }
+    if err := profiles.RequirePasswordReset(userID); err != nil {
+        return err
+    }
+    sessions.RevokeAll(userID)
+
+    body := "Un administrador pidió que restablezca su contraseña de SDGEStreaming.\nSu código es %s y vence en %.0f minutos; se puede usar una sola vez.\nHasta restablecerla no podrá iniciar sesión."
+    return issueToken(userID, user.Email, body)
+}
+
+// Emito un código nuevo, que reemplaza a los anteriores, y lo envío con el
+// texto dado (con lugar para el código y los minutos de vigencia)
+func issueToken(userID int, email, body string) error {
+    DiscardTokens(userID)
+    code := rand.Text()
+    tokens[hashToken(code)] = token{UserID: userID, ExpiresAt: time.Now().Add(TokenTTL)}
+    return mailer.Send(email, "Restablecer contraseña", fmt.Sprintf(body, code, TokenTTL.Minutes()))
+}
+
+// Restablezco la contraseña con un código vigente. El código se consume y se
+// cierran todas las sesiones abiertas de la cuenta
+func ResetPassword(code, newPassword string) error {
+    h := hashToken(code)
+    t, exists := tokens[h]
+    if !exists {
+        return errInvalidToken
+    }
+    if time.Now().After(t.ExpiresAt) {
+        delete(tokens, h)
+        return errInvalidToken
+    }
+    // Valido antes de consumir el código para poder reintentar con otra contraseña
+    if !utils.IsValidPassword(newPassword) {
+        return errors.ErrInvalidPassword
+    }
+
+    if err := profiles.SetPassword(t.UserID, newPassword); err != nil {
+        return err
+    }
+    DiscardTokens(t.UserID)
+    sessions.RevokeAll(t.UserID)
+
+    if user, err := profiles.FindByID(t.UserID); err == nil {
+        // Quien restablece la contraseña demostró ser el dueño: levanto el bloqueo por intentos
+        auth.ClearFailures(user.Email)
+        mailer.Send(user.Email, "Su contraseña fue cambiada",
+            "La contraseña de su cuenta de SDGEStreaming se restableció y se cerraron todas sus sesiones.\nSi no fue usted, restablézcala de nuevo y revise su email.")
+    }
+    return nil
+}
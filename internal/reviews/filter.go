@@ -0,0 +1,175 @@
+package reviews
+
+import (
+    "regexp"
+    "strings"
+    "unicode"
+    "SDGEStreaming/internal/search"
+)
+
+// Palabras ofensivas en español, ya normalizadas sin tildes. Dejo afuera las
+// que también tienen un sentido inocente, como "zorra" (el animal)
+var profanities = []string{
+    "mierda", "puta", "puto", "pendejo", "pendeja", "cabron", "cabrona", "joder",
+    "gilipollas", "carajo", "verga", "culero", "culera", "imbecil",
+    "idiota", "estupido", "estupida", "malparido", "hijueputa", "marica",
+}
+
+// Palabras ofensivas que solo lo son con su tilde o su eñe; sin ella son otra
+// palabra, como "cono" (de helado), así que las comparo antes de quitar tildes
+var accentedProfanities = []string{"coño"}
+
+// Palabras ofensivas sin letras repetidas seguidas, para compararlas con el texto normalizado
+var (
+    profane         = wordSet(profanities)
+    profaneAccented = wordSet(accentedProfanities)
+)
+
+// Armo el conjunto de palabras sin letras repetidas seguidas
+func wordSet(list []string) map[string]bool {
+    set := make(map[string]bool, len(list))
+    for _, w := range list {
+        set[collapseRepeats(w)] = true
+    }
+    return set
+}
+
+// Sustituciones habituales para esquivar filtros (p3nd3j0, m13rd4)
+var leetspeak = strings.NewReplacer(
+    "0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s",
+)
+
+// Patrones de spam: enlaces, correos y teléfonos
+var (
+    linkPattern  = regexp.MustCompile(`(?i)(https?://|www\.|\b[a-z0-9-]+\.(com|net|org|info|xyz|ly)\b)`)
+    emailPattern = regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`)
+    phonePattern = regexp.MustCompile(`(\d[\s-]?){7,}`)
+)
+
+// Motivos por los que una reseña va a la cola de moderación
+const (
+    FlagProfanity  = "lenguaje ofensivo"
+    FlagLink       = "contiene enlaces"
+    FlagContact    = "contiene datos de contacto"
+    FlagShouting   = "exceso de mayúsculas"
+    FlagRepetition = "texto repetitivo"
+)
+
+// Quito letras repetidas seguidas para detectar "mierdaaaa" o "puuuta"
+func collapseRepeats(text string) string {
+    var b strings.Builder
+    var last rune
+    for _, r := range text {
+        if r != last {
+            b.WriteRune(r)
+        }
+        last = r
+    }
+    return b.String()
+}
+
+// Uno las letras sueltas consecutivas para detectar palabras deletreadas con
+// espacios o puntos, como "m i e r d a". Solo uno letras sueltas, así "una cara
+// joven" no se lee como "carajo"
+func spelledOut(words []string) []string {
+    var joined []string
+    var run strings.Builder
+    letters := 0
+    flush := func() {
+        if letters > 1 {
+            joined = append(joined, collapseRepeats(run.String()))
+        }
+        run.Reset()
+        letters = 0
+    }
+    for _, w := range words {
+        if len([]rune(w)) != 1 {
+            flush()
+            continue
+        }
+        run.WriteString(w)
+        letters++
+    }
+    flush()
+    return joined
+}
+
+// Busco en un texto ya normalizado alguna palabra del conjunto, entera o deletreada
+func containsWord(normalized string, set map[string]bool) bool {
+    words := strings.FieldsFunc(normalized, func(r rune) bool {
+        return !unicode.IsLetter(r)
+    })
+    for _, w := range append(words, spelledOut(words)...) {
+        if set[w] {
+            return true
+        }
+    }
+    return false
+}
+
+// Reviso el texto de una reseña y devuelvo los motivos para moderarla, si hay
+func CheckText(text string) []string {
+    var flags []string
+
+    folded := collapseRepeats(leetspeak.Replace(search.Fold(text)))
+    lowered := collapseRepeats(leetspeak.Replace(strings.ToLower(text)))
+    if containsWord(folded, profane) || containsWord(lowered, profaneAccented) {
+        flags = append(flags, FlagProfanity)
+    }
+
+    if linkPattern.MatchString(text) {
+        flags = append(flags, FlagLink)
+    }
+    if emailPattern.MatchString(text) || phonePattern.MatchString(text) {
+        flags = append(flags, FlagContact)
+    }
+
+    var letters, upper int
+    for _, r := range text {
+        if unicode.IsLetter(r) {
+            letters++
+            if unicode.IsUpper(r) {
+                upper++
+            }
+        }
+    }
+    if letters >= 20 && upper*10 > letters*7 {
+        flags = append(flags, FlagShouting)
+    }
+
+    if isRepetitive(text) {
+        flags = append(flags, FlagRepetition)
+    }
+    return flags
+}
+
+// Detecto caracteres o palabras repetidos en exceso, típicos del spam
+func isRepetitive(text string) bool {
+    var last rune
+    run := 0
+    for _, r := range text {
+        if r == last && !unicode.IsSpace(r) {
+            run++
+            if run >= 5 {
+                return true
+            }
+        } else {
+            run = 0
+        }
+        last = r
+    }
+
+    words := strings.Fields(search.Fold(text))
+    if len(words) < 6 {
+        return false
+    }
+    counts := make(map[string]int)
+    for _, w := range words {
+        counts[w]++
+        // Una misma palabra que ocupa más de la mitad del texto
+        if counts[w]*2 > len(words) {
+            return true
+        }
+    }
+    return false
+}
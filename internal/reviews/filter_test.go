@@ -0,0 +1,69 @@
+package reviews
+
+import (
+    "testing"
+)
+
+// Verifico si una lista de motivos incluye uno dado
+func hasFlag(flags []string, flag string) bool {
+    for _, f := range flags {
+        if f == flag {
+            return true
+        }
+    }
+    return false
+}
+
+// Verifico la detección de lenguaje ofensivo, incluidos los intentos de esquivarla,
+// sin marcar textos inocentes que contienen una palabra ofensiva entre dos palabras
+func TestCheckTextProfanity(t *testing.T) {
+    tests := []struct {
+        text    string
+        profane bool
+    }{
+        {"Qué película tan mala, una mierda", true},
+        {"MIERDAAAA de final", true},
+        {"El protagonista es un p3nd3j0", true},
+        {"No entendí nada, c a r a j o", true},
+        {"Una m.i.e.r.d.a de guion", true},
+        {"Es un i-d-i-o-t-a el villano", true},
+        {"¡Qué estúpido!", true},
+        {"¡Coño, qué final!", true},
+        {"COÑOOO, qué susto", true},
+        {"Un cono de helado en cada escena", false},
+        {"La zorra del bosque engaña al cuervo", false},
+        {"La actriz tiene una cara joven para el papel", false},
+        {"El viejo derrota al dragón en el final", false},
+        {"Un diálogo muy idiomático y una trama idónea", false},
+        {"Una obra maestra, la recomiendo", false},
+        {"a b c d e", false},
+    }
+    for _, tt := range tests {
+        if got := hasFlag(CheckText(tt.text), FlagProfanity); got != tt.profane {
+            t.Errorf("CheckText(%q) ofensivo = %v, quiero %v", tt.text, got, tt.profane)
+        }
+    }
+}
+
+// Verifico los demás motivos de moderación
+func TestCheckTextFlags(t *testing.T) {
+    tests := []struct {
+        text string
+        flag string
+        want bool
+    }{
+        {"Mírenla gratis en www.peliculas.xyz", FlagLink, true},
+        {"Escríbanme a fan@correo.com", FlagContact, true},
+        {"Llamen al 555 123 4567", FlagContact, true},
+        {"ESTA PELÍCULA ES LO MEJOR QUE VI EN AÑOS", FlagShouting, true},
+        {"Esta película es lo mejor que vi en años", FlagShouting, false},
+        {"buena buena buena buena buena película", FlagRepetition, true},
+        {"Excelenteeeeee", FlagRepetition, true},
+        {"Buena fotografía y mejor banda sonora", FlagRepetition, false},
+    }
+    for _, tt := range tests {
+        if got := hasFlag(CheckText(tt.text), tt.flag); got != tt.want {
+            t.Errorf("CheckText(%q) %q = %v, quiero %v", tt.text, tt.flag, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,251 @@
+package reviews
+
+import (
+    "sort"
+    "strings"
+    "time"
+    "unicode/utf8"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/ratings"
+)
+
+// Estados de una reseña
+const (
+    StatusPending  = "pendiente"
+    StatusApproved = "aprobada"
+    StatusHidden   = "oculta"
+)
+
+// Acciones de moderación
+const (
+    ActionApprove = "aprobar"
+    ActionHide    = "ocultar"
+    ActionDelete  = "eliminar"
+)
+
+// Largo permitido para el texto de una reseña
+const (
+    minLength = 10
+    maxLength = 1000
+)
+
+// Reseña escrita por un usuario junto a su calificación
+type Review struct {
+    ID               int
+    Ref              categories.ContentRef
+    UserID           int
+    Rating           float64
+    Text             string
+    Status           string
+    Flags            []string     // motivos del filtro automático
+    Votes            map[int]bool // usuario -> true si le resultó útil
+    CreatedAt        time.Time
+    ModeratedBy      int
+    ModerationReason string
+    ModeratedAt      time.Time
+}
+
+// Resultado de una moderación, para registro
+type ModerationRecord struct {
+    ReviewID    int
+    Action      string
+    Reason      string
+    ModeratorID int
+    At          time.Time
+}
+
+// Variables globales para almacenamiento en memoria
+var (
+    reviews    = make(map[int]*Review)
+    nextID     = 1
+    moderation []ModerationRecord
+)
+
+// Cuento los votos útiles de una reseña
+func (r Review) Helpful() int {
+    count := 0
+    for _, helpful := range r.Votes {
+        if helpful {
+            count++
+        }
+    }
+    return count
+}
+
+// Cuento los votos no útiles de una reseña
+func (r Review) Unhelpful() int {
+    return len(r.Votes) - r.Helpful()
+}
+
+// Copio una reseña para que quien la recibe no modifique el almacenamiento
+func (r *Review) copy() Review {
+    c := *r
+    c.Flags = append([]string(nil), r.Flags...)
+    c.Votes = make(map[int]bool, len(r.Votes))
+    for userID, helpful := range r.Votes {
+        c.Votes[userID] = helpful
+    }
+    return c
+}
+
+// Escribo o reemplazo la reseña de un usuario sobre un contenido que ya calificó
+func AddReview(ref categories.ContentRef, userID int, text string) (Review, error) {
+    own, rated := ratings.GetUserRating(ref, userID)
+    if !rated {
+        return Review{}, errors.NewAppError("REVIEW_001", "Debe calificar el contenido antes de reseñarlo", "")
+    }
+
+    text = strings.TrimSpace(text)
+    length := utf8.RuneCountInString(text)
+    if length < minLength || length > maxLength {
+        return Review{}, errors.NewAppError("REVIEW_002", "Largo de reseña inválido", "Debe tener entre 10 y 1000 caracteres")
+    }
+
+    // El filtro no rechaza: envía a moderación lo sospechoso
+    flags := CheckText(text)
+    status := StatusApproved
+    if len(flags) > 0 {
+        status = StatusPending
+    }
+
+    // Un usuario tiene una sola reseña por contenido: la nueva reemplaza a la anterior
+    for _, r := range reviews {
+        if r.Ref == ref && r.UserID == userID {
+            r.Text = text
+            r.Rating = own.Rating
+            r.Status = status
+            r.Flags = flags
+            r.Votes = make(map[int]bool)
+            r.CreatedAt = time.Now()
+            return r.copy(), nil
+        }
+    }
+
+    review := &Review{
+        ID:        nextID,
+        Ref:       ref,
+        UserID:    userID,
+        Rating:    own.Rating,
+        Text:      text,
+        Status:    status,
+        Flags:     flags,
+        Votes:     make(map[int]bool),
+        CreatedAt: time.Now(),
+    }
+    reviews[nextID] = review
+    nextID++
+    return review.copy(), nil
+}
+
+// Obtengo una reseña por ID
+func GetByID(id int) (Review, error) {
+    r, exists := reviews[id]
+    if !exists {
+        return Review{}, errors.NewAppError("REVIEW_003", "Reseña no encontrada", "")
+    }
+    return r.copy(), nil
+}
+
+// Listo las reseñas aprobadas de un contenido, las más útiles primero
+func ListForContent(ref categories.ContentRef) []Review {
+    var list []Review
+    for _, r := range reviews {
+        if r.Ref == ref && r.Status == StatusApproved {
+            list = append(list, r.copy())
+        }
+    }
+    sort.Slice(list, func(i, j int) bool {
+        si := list[i].Helpful() - list[i].Unhelpful()
+        sj := list[j].Helpful() - list[j].Unhelpful()
+        if si != sj {
+            return si > sj
+        }
+        return list[i].CreatedAt.After(list[j].CreatedAt)
+    })
+    return list
+}
+
+// Registro si una reseña le resultó útil a un usuario; votar de nuevo cambia el voto
+func Vote(reviewID, userID int, helpful bool) error {
+    r, exists := reviews[reviewID]
+    if !exists || r.Status != StatusApproved {
+        return errors.NewAppError("REVIEW_003", "Reseña no encontrada", "")
+    }
+    if r.UserID == userID {
+        return errors.NewAppError("REVIEW_004", "No puede votar su propia reseña", "")
+    }
+    r.Votes[userID] = helpful
+    return nil
+}
+
+// Elimino las reseñas de un usuario y sus votos en reseñas ajenas, por ejemplo
+// al eliminar su cuenta. Devuelvo cuántas reseñas eliminé
+func RemoveAllByUser(userID int) int {
+    removed := 0
+    for id, r := range reviews {
+        if r.UserID == userID {
+            delete(reviews, id)
+            removed++
+            continue
+        }
+        delete(r.Votes, userID)
+    }
+    return removed
+}
+
+// Listo las reseñas pendientes de moderación, las más antiguas primero
+func PendingQueue() []Review {
+    var queue []Review
+    for _, r := range reviews {
+        if r.Status == StatusPending {
+            queue = append(queue, r.copy())
+        }
+    }
+    sort.Slice(queue, func(i, j int) bool {
+        return queue[i].CreatedAt.Before(queue[j].CreatedAt)
+    })
+    return queue
+}
+
+// Apruebo, oculto o elimino una reseña dejando registro del motivo
+func Moderate(reviewID, moderatorID int, action, reason string) error {
+    r, exists := reviews[reviewID]
+    if !exists {
+        return errors.NewAppError("REVIEW_003", "Reseña no encontrada", "")
+    }
+
+    reason = strings.TrimSpace(reason)
+    if reason == "" && action != ActionApprove {
+        return errors.NewAppError("REVIEW_005", "Debe indicar un motivo", action)
+    }
+
+    now := time.Now()
+    switch action {
+    case ActionApprove:
+        r.Status = StatusApproved
+    case ActionHide:
+        r.Status = StatusHidden
+    case ActionDelete:
+        delete(reviews, reviewID)
+    default:
+        return errors.NewAppError("REVIEW_006", "Acción de moderación inválida", action)
+    }
+    r.ModeratedBy = moderatorID
+    r.ModerationReason = reason
+    r.ModeratedAt = now
+
+    moderation = append(moderation, ModerationRecord{
+        ReviewID:    reviewID,
+        Action:      action,
+        Reason:      reason,
+        ModeratorID: moderatorID,
+        At:          now,
+    })
+    return nil
+}
+
+// Obtengo el registro de moderaciones realizadas
+func ModerationLog() []ModerationRecord {
+    return append([]ModerationRecord(nil), moderation...)
+}
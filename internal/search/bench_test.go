@@ -0,0 +1,102 @@
+package search
+
+import (
+    "fmt"
+    "math/rand"
+    "sync"
+    "testing"
+    "SDGEStreaming/internal/audiovisual"
+    "SDGEStreaming/internal/people"
+    "SDGEStreaming/internal/profiles"
+)
+
+// Tamaño del catálogo sintético de los benchmarks
+const benchItems = 100000
+
+// Palabras para generar un catálogo sintético realista
+var (
+    titleWords = []string{
+        "Viaje", "Océano", "Ciudad", "Sombra", "Noche", "Estrella", "Camino", "Secreto",
+        "Fuego", "Río", "Montaña", "Tiempo", "Corazón", "Leyenda", "Guerra", "Sueño",
+        "Silencio", "Invierno", "Verano", "Frontera", "Memoria", "Destino", "Lluvia", "Reino",
+    }
+    firstNames = []string{"Ana", "Luis", "Carmen", "Jorge", "Lucía", "Mateo", "Sofía", "Diego", "Valeria", "Andrés"}
+    lastNames  = []string{"García", "Rodríguez", "Pérez", "Sánchez", "Ramírez", "Torres", "Flores", "Rivera", "Gómez", "Díaz"}
+    types      = []string{"Película", "Serie", "Documental"}
+    genreNames = []string{"Acción", "Comedia", "Drama", "Ciencia Ficción", "Romance", "Terror", "Documental"}
+    ageRatings = []string{"Infantil", "Adolescente", "Adulto"}
+
+    populateOnce sync.Once
+)
+
+// Lleno una sola vez el catálogo con contenido sintético y construyo los índices
+func populate(b *testing.B) {
+    populateOnce.Do(func() {
+        rng := rand.New(rand.NewSource(1))
+        for i := 0; i < benchItems; i++ {
+            title := fmt.Sprintf("%s del %s %d",
+                titleWords[rng.Intn(len(titleWords))], titleWords[rng.Intn(len(titleWords))], i)
+            director := firstNames[rng.Intn(len(firstNames))] + " " + lastNames[rng.Intn(len(lastNames))]
+            audiovisual.AddContent(title, types[rng.Intn(len(types))], genreNames[rng.Intn(len(genreNames))],
+                30+rng.Intn(150), ageRatings[rng.Intn(len(ageRatings))], "Sinopsis generada", 1980+rng.Intn(45), director)
+        }
+        // Los directores se buscan como personas acreditadas
+        people.ImportExisting()
+        Search("viaje", 10)
+        Suggest("viaje", 10)
+    })
+    b.ResetTimer()
+}
+
+func BenchmarkSearch(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        Search("viaje oceano", 10)
+    }
+}
+
+func BenchmarkSuggestPrefix(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        Suggest("mont", 10)
+    }
+}
+
+func BenchmarkSuggestTypo(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        Suggest("montnaa", 10)
+    }
+}
+
+func BenchmarkSuggestMultiword(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        Suggest("sombra del inv", 10)
+    }
+}
+
+func BenchmarkSuggestPerson(b *testing.B) {
+    populate(b)
+    for i := 0; i < b.N; i++ {
+        Suggest("lucia rodrig", 10)
+    }
+}
+
+// Cada vuelta califica un contenido y sugiere enseguida: la calificación no
+// debe obligar a reconstruir el índice
+func BenchmarkSuggestAfterRating(b *testing.B) {
+    populate(b)
+    user, err := profiles.FindByEmail("user@demo.com")
+    if err != nil {
+        b.Fatal(err)
+    }
+    list := audiovisual.ListAll()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        if _, err := audiovisual.RateContent(list[i%len(list)].ID, user.ID, float64(1+i%10)); err != nil {
+            b.Fatal(err)
+        }
+        Suggest("mont", 10)
+    }
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+    "sort"
+    "strings"
+    "SDGEStreaming/internal/people"
+)
+
+// Persona encontrada con la cantidad de contenidos en los que participa
+type PersonResult struct {
+    Person  people.Person
+    Credits int
+}
+
+// Busco personas cuyo nombre contiene todas las palabras de la consulta al
+// comienzo de alguna de sus palabras; si no hay coincidencias exactas uso las
+// sugerencias con errores de tipeo
+func SearchPeople(query string, limit int) []PersonResult {
+    terms := splitWords(query)
+    if len(terms) == 0 {
+        return nil
+    }
+
+    var results []PersonResult
+    for _, p := range people.All() {
+        if matchesAll(splitWords(p.Name), terms) {
+            results = append(results, PersonResult{Person: p, Credits: len(people.CreditsOf(p.ID))})
+        }
+    }
+
+    if len(results) == 0 {
+        for _, s := range Suggest(query, limit) {
+            if s.Kind != SuggestPerson {
+                continue
+            }
+            if p, err := people.GetByID(s.PersonID); err == nil {
+                results = append(results, PersonResult{Person: *p, Credits: len(people.CreditsOf(p.ID))})
+            }
+        }
+    }
+
+    sort.SliceStable(results, func(i, j int) bool {
+        return results[i].Credits > results[j].Credits
+    })
+    if limit > 0 && len(results) > limit {
+        results = results[:limit]
+    }
+    return results
+}
+
+// Verifico que cada término sea el comienzo de alguna palabra del nombre
+func matchesAll(nameWords, terms []string) bool {
+    for _, term := range terms {
+        found := false
+        for _, w := range nameWords {
+            if strings.HasPrefix(w, term) {
+                found = true
+                break
+            }
+        }
+        if !found {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,174 @@
+package search
+
+import (
+    "math"
+    "sort"
+    "SDGEStreaming/internal/audio"
+    "SDGEStreaming/internal/audiovisual"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/people"
+)
+
+// Peso de cada campo en el puntaje de relevancia
+const (
+    weightTitle    = 3.0
+    weightPeople   = 2.0
+    weightAlbum    = 1.5
+    weightSynopsis = 1.0
+)
+
+// Resultado de una búsqueda en el catálogo
+type Result struct {
+    Ref       categories.ContentRef
+    Title     string
+    AgeRating string
+    Score     float64
+}
+
+// Variables globales para el índice invertido
+var (
+    postings           = make(map[string]map[categories.ContentRef]float64) // término -> contenido -> peso
+    documents          = make(map[categories.ContentRef]Result)
+    indexedAudiovisual = -1 // revisión del catálogo audiovisual indexada
+    indexedAudio       = -1 // revisión del catálogo de audio indexada
+    indexedPeople      = -1 // revisión de personas y créditos indexada
+)
+
+// Agrego los términos de un campo al índice con el peso indicado
+func addField(ref categories.ContentRef, text string, weight float64) {
+    for _, term := range Tokenize(text) {
+        if postings[term] == nil {
+            postings[term] = make(map[categories.ContentRef]float64)
+        }
+        postings[term][ref] += weight
+    }
+}
+
+// Quito del índice todos los documentos de un tipo de contenido
+func removeKind(kind string) {
+    for term, docs := range postings {
+        for ref := range docs {
+            if ref.Kind == kind {
+                delete(docs, ref)
+            }
+        }
+        if len(docs) == 0 {
+            delete(postings, term)
+        }
+    }
+    for ref := range documents {
+        if ref.Kind == kind {
+            delete(documents, ref)
+        }
+    }
+}
+
+// Agrego al índice los nombres de las personas acreditadas en un contenido
+func addPeople(ref categories.ContentRef) {
+    for _, name := range people.NamesFor(ref) {
+        addField(ref, name, weightPeople)
+    }
+}
+
+// Reconstruyo el índice de los catálogos que cambiaron desde la última búsqueda
+func refresh() {
+    // Un cambio en los créditos afecta a los documentos de ambos catálogos
+    if rev := people.Revision(); rev != indexedPeople {
+        indexedAudiovisual, indexedAudio = -1, -1
+        indexedPeople = rev
+    }
+
+    if rev := audiovisual.Revision(); rev != indexedAudiovisual {
+        removeKind(categories.KindAudiovisual)
+        for _, c := range audiovisual.ListAll() {
+            ref := categories.ContentRef{Kind: categories.KindAudiovisual, ID: c.ID}
+            documents[ref] = Result{Ref: ref, Title: c.Title, AgeRating: c.AgeRating}
+            addField(ref, c.Title, weightTitle)
+            addPeople(ref)
+            addField(ref, c.Synopsis, weightSynopsis)
+        }
+        indexedAudiovisual = rev
+    }
+
+    if rev := audio.Revision(); rev != indexedAudio {
+        removeKind(categories.KindAudio)
+        for _, c := range audio.ListAll() {
+            ref := categories.ContentRef{Kind: categories.KindAudio, ID: c.ID}
+            documents[ref] = Result{Ref: ref, Title: c.Title, AgeRating: c.AgeRating}
+            addField(ref, c.Title, weightTitle)
+            addPeople(ref)
+            addField(ref, c.Album, weightAlbum)
+        }
+        indexedAudio = rev
+    }
+}
+
+// Busco contenido en ambos catálogos y devuelvo los resultados ordenados por relevancia
+func Search(query string, limit int) []Result {
+    return SearchAllowed(query, limit, nil)
+}
+
+// Busco como Search pero solo entre los resultados que acepta allow (por
+// ejemplo los permitidos para la edad del usuario), antes de cortar en limit
+// para no devolver menos resultados de los que hay
+func SearchAllowed(query string, limit int, allow func(Result) bool) []Result {
+    refresh()
+
+    terms := uniqueTerms(Tokenize(query))
+    if len(terms) == 0 {
+        return nil
+    }
+
+    scores := make(map[categories.ContentRef]float64)
+    matched := make(map[categories.ContentRef]int)
+    total := float64(len(documents))
+    for _, term := range terms {
+        docs := postings[term]
+        if len(docs) == 0 {
+            continue
+        }
+        // Los términos raros pesan más que los comunes
+        idf := math.Log(1 + total/float64(len(docs)))
+        for ref, weight := range docs {
+            scores[ref] += weight * idf
+            matched[ref]++
+        }
+    }
+
+    var results []Result
+    for ref, score := range scores {
+        // Premio los contenidos que coinciden con más términos de la consulta
+        coverage := float64(matched[ref]) / float64(len(terms))
+        result := documents[ref]
+        if allow != nil && !allow(result) {
+            continue
+        }
+        result.Score = score * coverage
+        results = append(results, result)
+    }
+
+    sort.Slice(results, func(i, j int) bool {
+        if results[i].Score != results[j].Score {
+            return results[i].Score > results[j].Score
+        }
+        return results[i].Title < results[j].Title
+    })
+
+    if limit > 0 && len(results) > limit {
+        results = results[:limit]
+    }
+    return results
+}
+
+// Elimino términos repetidos conservando el orden
+func uniqueTerms(terms []string) []string {
+    seen := make(map[string]bool)
+    var unique []string
+    for _, t := range terms {
+        if !seen[t] {
+            seen[t] = true
+            unique = append(unique, t)
+        }
+    }
+    return unique
+}
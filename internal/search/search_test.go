@@ -0,0 +1,110 @@
+package search
+
+import (
+    "fmt"
+    "testing"
+    "SDGEStreaming/internal/audiovisual"
+    "SDGEStreaming/internal/profiles"
+)
+
+// Verifico que la búsqueda ignore tildes y mayúsculas
+func TestSearchFoldsAccents(t *testing.T) {
+    tests := []struct {
+        query string
+        want  string
+    }{
+        {"oceano", "Misterios del Océano"},
+        {"OCÉANO", "Misterios del Océano"},
+        {"misterios del oceano", "Misterios del Océano"},
+        {"viaje", "El Viaje Infinito"},
+    }
+    for _, tt := range tests {
+        results := Search(tt.query, 5)
+        if len(results) == 0 || results[0].Title != tt.want {
+            t.Errorf("Search(%q) = %v, quiero primero %q", tt.query, results, tt.want)
+        }
+    }
+}
+
+// Verifico que el filtro se aplique antes del límite, así los resultados
+// permitidos no quedan afuera por los que se descartan
+func TestSearchAllowedBeforeLimit(t *testing.T) {
+    for i := 0; i < 25; i++ {
+        audiovisual.AddContent(fmt.Sprintf("Limonero Limonero %d", i), "Película", "Drama", 100, "Adulto", "", 2020, "")
+    }
+    for i := 0; i < 3; i++ {
+        audiovisual.AddContent(fmt.Sprintf("Limonero para chicos %d", i), "Serie", "Comedia", 20, "Infantil", "", 2020, "")
+    }
+    infantil := func(r Result) bool { return r.AgeRating == "Infantil" }
+
+    results := SearchAllowed("limonero", 20, infantil)
+    if len(results) != 3 {
+        t.Fatalf("SearchAllowed(limonero, 20, infantil) devolvió %d resultados, quiero 3: %v", len(results), results)
+    }
+    for _, r := range results {
+        if r.AgeRating != "Infantil" {
+            t.Errorf("resultado no permitido: %+v", r)
+        }
+    }
+    if got := Search("limonero", 20); len(got) != 20 {
+        t.Errorf("Search(limonero, 20) devolvió %d resultados, quiero 20", len(got))
+    }
+}
+
+// Verifico que las sugerencias completen prefijos, ignoren tildes y corrijan errores de tipeo
+func TestSuggest(t *testing.T) {
+    tests := []struct {
+        input    string
+        want     string
+        distance int
+    }{
+        {"viaj infinto", "El Viaje Infinito", 1},
+        {"infinto", "El Viaje Infinito", 1},
+        {"oceano", "Misterios del Océano", 0},
+        {"ocea", "Misterios del Océano", 0},
+        {"misterios del oce", "Misterios del Océano", 0},
+        {"ciudd", "Risas en la Ciudad", 1},
+    }
+    for _, tt := range tests {
+        suggestions := Suggest(tt.input, 5)
+        if len(suggestions) == 0 {
+            t.Errorf("Suggest(%q) sin sugerencias, quiero %q", tt.input, tt.want)
+            continue
+        }
+        if got := suggestions[0]; got.Text != tt.want || got.Distance != tt.distance {
+            t.Errorf("Suggest(%q) = %q (distancia %d), quiero %q (distancia %d)", tt.input, got.Text, got.Distance, tt.want, tt.distance)
+        }
+    }
+}
+
+// Verifico que un texto sin relación con el catálogo no devuelva nada
+func TestSuggestNoMatch(t *testing.T) {
+    for _, input := range []string{"", "   ", "xyzzyqwv"} {
+        if got := Suggest(input, 5); len(got) != 0 {
+            t.Errorf("Suggest(%q) = %v, quiero ninguna", input, got)
+        }
+    }
+}
+
+// Verifico que una calificación nueva cambie el orden de las sugerencias
+// aunque el catálogo no haya cambiado
+func TestSuggestFollowsRatings(t *testing.T) {
+    audiovisual.AddContent("Zafiro Alfa", "Película", "Drama", 100, "Adulto", "", 2020, "")
+    audiovisual.AddContent("Zafiro Beta", "Película", "Drama", 100, "Adulto", "", 2020, "")
+    list := audiovisual.ListAll()
+    beta := list[len(list)-1]
+
+    if got := Suggest("zafiro", 2); len(got) != 2 || got[0].Text != "Zafiro Alfa" {
+        t.Fatalf("Suggest antes de calificar = %v, quiero primero Zafiro Alfa", got)
+    }
+    user, err := profiles.FindByEmail("user@demo.com")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := audiovisual.RateContent(beta.ID, user.ID, 9); err != nil {
+        t.Fatal(err)
+    }
+    if got := Suggest("zafiro", 2); len(got) != 2 || got[0].Text != "Zafiro Beta" {
+        t.Errorf("Suggest después de calificar = %v, quiero primero Zafiro Beta", got)
+    }
+}
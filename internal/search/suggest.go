@@ -0,0 +1,426 @@
+package search
+
+import (
+    "sort"
+    "strings"
+    "unicode"
+    "SDGEStreaming/internal/audio"
+    "SDGEStreaming/internal/audiovisual"
+    "SDGEStreaming/internal/categories"
+    "SDGEStreaming/internal/people"
+    "SDGEStreaming/internal/ratings"
+)
+
+// Máximo de sugerencias que guarda cada nodo del árbol de prefijos
+const suggestionPool = 10
+
+// Tipos de sugerencia
+const (
+    SuggestTitle  = "titulo"
+    SuggestPerson = "persona"
+)
+
+// Sugerencia de autocompletado o corrección para lo que escribe el usuario
+type Suggestion struct {
+    Text      string
+    Kind      string                // SuggestTitle o SuggestPerson
+    Ref       categories.ContentRef // solo para títulos
+    PersonID  int                   // solo para personas
+    AgeRating string                // solo para títulos
+    Distance  int                   // errores de tipeo corregidos
+}
+
+// Texto sugerible: un título o el nombre de una persona
+type entry struct {
+    suggestion Suggestion
+    credits    float64 // solo para personas
+    words      []string
+}
+
+// Nodo del árbol de prefijos sobre las palabras del catálogo
+type trieNode struct {
+    children map[rune]*trieNode
+    word     string // palabra completa si el nodo termina una
+    entries  []int // entradas que contienen exactamente esta palabra
+    top      []int // mejores entradas de todo el subárbol
+}
+
+// Variables globales para el índice de sugerencias
+var (
+    entries            []entry
+    root               = &trieNode{children: make(map[rune]*trieNode)}
+    words              = make(map[string]*trieNode)
+    suggestAudiovisual = -1 // revisión del catálogo audiovisual indexada
+    suggestAudio       = -1 // revisión del catálogo de audio indexada
+    suggestPeople      = -1 // revisión de personas y créditos indexada
+)
+
+// Divido un texto normalizado en palabras, sin quitar plurales ni palabras vacías
+func splitWords(text string) []string {
+    return strings.FieldsFunc(Fold(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+}
+
+// Peso de una entrada al momento de ordenar: los títulos según su promedio
+// actual de calificaciones, así una calificación nueva no obliga a reconstruir
+// el índice, y las personas según la cantidad de contenidos en los que participan
+func (e entry) weight() float64 {
+    if e.suggestion.Kind == SuggestPerson {
+        return e.credits
+    }
+    avg, _ := ratings.GetAverage(e.suggestion.Ref)
+    return 1 + avg
+}
+
+// Reconstruyo el índice de sugerencias si alguno de los catálogos cambió
+func refreshSuggestions() {
+    if audiovisual.Revision() == suggestAudiovisual && audio.Revision() == suggestAudio &&
+        people.Revision() == suggestPeople {
+        return
+    }
+
+    entries = nil
+    root = &trieNode{children: make(map[rune]*trieNode)}
+    words = make(map[string]*trieNode)
+
+    for _, c := range audiovisual.ListAll() {
+        ref := categories.ContentRef{Kind: categories.KindAudiovisual, ID: c.ID}
+        entries = append(entries, entry{
+            suggestion: Suggestion{
+                Text:      c.Title,
+                Kind:      SuggestTitle,
+                Ref:       ref,
+                AgeRating: c.AgeRating,
+            },
+            words: splitWords(c.Title),
+        })
+    }
+    for _, c := range audio.ListAll() {
+        ref := categories.ContentRef{Kind: categories.KindAudio, ID: c.ID}
+        entries = append(entries, entry{
+            suggestion: Suggestion{
+                Text:      c.Title,
+                Kind:      SuggestTitle,
+                Ref:       ref,
+                AgeRating: c.AgeRating,
+            },
+            words: splitWords(c.Title),
+        })
+    }
+
+    // Las personas pesan según la cantidad de contenidos en los que participan
+    for _, p := range people.All() {
+        credited := len(people.CreditsOf(p.ID))
+        if credited == 0 {
+            continue
+        }
+        entries = append(entries, entry{
+            suggestion: Suggestion{Text: p.Name, Kind: SuggestPerson, PersonID: p.ID},
+            credits:    float64(credited),
+            words:      splitWords(p.Name),
+        })
+    }
+
+    for i, e := range entries {
+        for _, w := range e.words {
+            node := insertWord(w)
+            if len(node.entries) == 0 || node.entries[len(node.entries)-1] != i {
+                node.entries = append(node.entries, i)
+            }
+        }
+    }
+    computeTop(root)
+
+    suggestAudiovisual = audiovisual.Revision()
+    suggestAudio = audio.Revision()
+    suggestPeople = people.Revision()
+}
+
+// Inserto una palabra en el árbol de prefijos y devuelvo su nodo final
+func insertWord(word string) *trieNode {
+    if node, exists := words[word]; exists {
+        return node
+    }
+    node := root
+    for _, r := range word {
+        child, exists := node.children[r]
+        if !exists {
+            child = &trieNode{children: make(map[rune]*trieNode)}
+            node.children[r] = child
+        }
+        node = child
+    }
+    node.word = word
+    words[word] = node
+    return node
+}
+
+// Calculo las mejores entradas de cada subárbol para responder prefijos sin recorrerlo
+func computeTop(node *trieNode) []int {
+    candidates := append([]int(nil), node.entries...)
+    for _, child := range node.children {
+        candidates = append(candidates, computeTop(child)...)
+    }
+    node.top = bestEntries(candidates, suggestionPool)
+    return node.top
+}
+
+// Ordeno entradas por peso, sin repetir, y me quedo con las primeras n
+func bestEntries(candidates []int, n int) []int {
+    weights := make(map[int]float64, len(candidates))
+    for _, c := range candidates {
+        weights[c] = entries[c].weight()
+    }
+    sort.Slice(candidates, func(i, j int) bool {
+        a, b := candidates[i], candidates[j]
+        if weights[a] != weights[b] {
+            return weights[a] > weights[b]
+        }
+        return len(entries[a].suggestion.Text) < len(entries[b].suggestion.Text)
+    })
+    var best []int
+    seen := make(map[int]bool)
+    for _, c := range candidates {
+        if seen[c] {
+            continue
+        }
+        seen[c] = true
+        best = append(best, c)
+        if len(best) == n {
+            break
+        }
+    }
+    return best
+}
+
+// Errores de tipeo tolerados según el largo de la palabra
+func maxDistance(word string) int {
+    switch n := len([]rune(word)); {
+    case n <= 2:
+        return 0
+    case n <= 5:
+        return 1
+    default:
+        return 2
+    }
+}
+
+// Recorro el árbol buscando prefijos a distancia de edición tolerable de la consulta
+func fuzzyPrefix(query []rune, maxDist int, visit func(node *trieNode, dist int)) {
+    row := make([]int, len(query)+1)
+    for i := range row {
+        row[i] = i
+    }
+    for r, child := range root.children {
+        fuzzyWalk(child, r, 0, query, nil, row, maxDist, visit)
+    }
+}
+
+// Avanzo un nivel del árbol actualizando la matriz de distancia (con transposiciones)
+func fuzzyWalk(node *trieNode, r, prevRune rune, query []rune, prevPrev, prev []int, maxDist int, visit func(node *trieNode, dist int)) {
+    row := make([]int, len(prev))
+    row[0] = prev[0] + 1
+    best := row[0]
+    for i := 1; i < len(row); i++ {
+        cost := 1
+        if query[i-1] == r {
+            cost = 0
+        }
+        row[i] = min(row[i-1]+1, prev[i]+1, prev[i-1]+cost)
+        // Dos letras invertidas cuentan como un solo error
+        if prevPrev != nil && i > 1 && query[i-1] == prevRune && query[i-2] == r {
+            row[i] = min(row[i], prevPrev[i-2]+1)
+        }
+        best = min(best, row[i])
+    }
+
+    // La consulta completa ya coincide: todo el subárbol son posibles completados,
+    // y solo sigo bajando si más adelante la distancia aún puede mejorar
+    last := row[len(row)-1]
+    if last <= maxDist {
+        visit(node, last)
+        if best >= last {
+            return
+        }
+    }
+    if best > maxDist {
+        return
+    }
+    for next, child := range node.children {
+        fuzzyWalk(child, next, r, query, prev, row, maxDist, visit)
+    }
+}
+
+// Reúno las palabras del vocabulario que completan un prefijo, con su distancia
+func completions(prefix string) map[string]int {
+    found := make(map[string]int)
+    var collect func(node *trieNode, dist int)
+    collect = func(node *trieNode, dist int) {
+        if node.word != "" {
+            if d, seen := found[node.word]; !seen || dist < d {
+                found[node.word] = dist
+            }
+        }
+        for _, child := range node.children {
+            collect(child, dist)
+        }
+    }
+    fuzzyPrefix([]rune(prefix), maxDistance(prefix), collect)
+    return found
+}
+
+// Reúno las palabras del vocabulario parecidas a una palabra completa
+func similarWords(word string) map[string]int {
+    found := make(map[string]int)
+    limit := maxDistance(word)
+    for w, dist := range completions(word) {
+        if w == word {
+            found[w] = 0
+        } else if d := editDistance(w, word); d <= limit {
+            found[w] = max(d, dist)
+        }
+    }
+    return found
+}
+
+// Verifico si alguna palabra de la entrada pertenece al conjunto indicado
+func hasAnyWord(e entry, set map[string]int) bool {
+    for _, w := range e.words {
+        if _, ok := set[w]; ok {
+            return true
+        }
+    }
+    return false
+}
+
+// Calculo la distancia de edición entre dos palabras, contando transposiciones
+func editDistance(a, b string) int {
+    ra, rb := []rune(a), []rune(b)
+    d := make([][]int, len(ra)+1)
+    for i := range d {
+        d[i] = make([]int, len(rb)+1)
+        d[i][0] = i
+    }
+    for j := range d[0] {
+        d[0][j] = j
+    }
+    for i := 1; i <= len(ra); i++ {
+        for j := 1; j <= len(rb); j++ {
+            cost := 1
+            if ra[i-1] == rb[j-1] {
+                cost = 0
+            }
+            d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+            if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+                d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+            }
+        }
+    }
+    return d[len(ra)][len(rb)]
+}
+
+// Sugiero títulos y nombres para un texto parcial, tolerando errores de tipeo
+func Suggest(input string, n int) []Suggestion {
+    refreshSuggestions()
+
+    typed := splitWords(input)
+    if len(typed) == 0 || n <= 0 {
+        return nil
+    }
+    if n > suggestionPool {
+        n = suggestionPool
+    }
+
+    // La última palabra se completa; las anteriores deben aparecer en la entrada
+    prefix := typed[len(typed)-1]
+    var context []string
+    for _, w := range typed[:len(typed)-1] {
+        if !stopwords[w] {
+            context = append(context, w)
+        }
+    }
+
+    distances := make(map[int]int) // entrada -> menor distancia encontrada
+    consider := func(candidates []int, dist int) {
+        for _, c := range candidates {
+            if d, seen := distances[c]; !seen || dist < d {
+                distances[c] = dist
+            }
+        }
+    }
+
+    if len(context) > 0 {
+        // Parto de las entradas que contienen alguna palabra parecida a la primera
+        // palabra de contexto y filtro por las demás y por el prefijo
+        pool := make(map[int]int)
+        for w, dist := range similarWords(context[0]) {
+            for _, c := range words[w].entries {
+                if d, seen := pool[c]; !seen || dist < d {
+                    pool[c] = dist
+                }
+            }
+        }
+        required := []map[string]int{completions(prefix)}
+        for _, w := range context[1:] {
+            required = append(required, similarWords(w))
+        }
+        for c, dist := range pool {
+            matches := true
+            for _, set := range required {
+                if !hasAnyWord(entries[c], set) {
+                    matches = false
+                    break
+                }
+            }
+            if matches {
+                consider([]int{c}, dist)
+            }
+        }
+    } else {
+        node := root
+        for _, r := range prefix {
+            if node = node.children[r]; node == nil {
+                break
+            }
+        }
+        if node != nil {
+            consider(node.top, 0)
+        }
+        if len(distances) < n {
+            fuzzyPrefix([]rune(prefix), maxDistance(prefix), func(node *trieNode, dist int) {
+                consider(node.top, dist)
+            })
+        }
+    }
+
+    // Los pesos se leen ahora: las calificaciones pueden haber cambiado desde
+    // que se armó el índice
+    ranked := make([]int, 0, len(distances))
+    weights := make(map[int]float64, len(distances))
+    for c := range distances {
+        ranked = append(ranked, c)
+        weights[c] = entries[c].weight()
+    }
+    sort.Slice(ranked, func(i, j int) bool {
+        a, b := ranked[i], ranked[j]
+        if distances[a] != distances[b] {
+            return distances[a] < distances[b]
+        }
+        if weights[a] != weights[b] {
+            return weights[a] > weights[b]
+        }
+        return entries[a].suggestion.Text < entries[b].suggestion.Text
+    })
+    if len(ranked) > n {
+        ranked = ranked[:n]
+    }
+
+    suggestions := make([]Suggestion, 0, len(ranked))
+    for _, c := range ranked {
+        s := entries[c].suggestion
+        s.Distance = distances[c]
+        suggestions = append(suggestions, s)
+    }
+    return suggestions
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+    "strings"
+    "unicode"
+    "SDGEStreaming/internal/textnorm"
+)
+
+// Equivalencias para quitar tildes y diéresis al normalizar
+var accentFolds = map[rune]rune{
+    'á': 'a', 'à': 'a', 'ä': 'a', 'â': 'a',
+    'é': 'e', 'è': 'e', 'ë': 'e', 'ê': 'e',
+    'í': 'i', 'ì': 'i', 'ï': 'i', 'î': 'i',
+    'ó': 'o', 'ò': 'o', 'ö': 'o', 'ô': 'o',
+    'ú': 'u', 'ù': 'u', 'ü': 'u', 'û': 'u',
+    'ñ': 'n', 'ç': 'c',
+}
+
+// Palabras vacías en español que no aportan a la búsqueda
+var stopwords = map[string]bool{
+    "a": true, "al": true, "con": true, "de": true, "del": true, "el": true,
+    "en": true, "es": true, "la": true, "las": true, "lo": true, "los": true,
+    "o": true, "para": true, "por": true, "que": true, "se": true, "sobre": true,
+    "su": true, "un": true, "una": true, "y": true,
+}
+
+// Normalizo un texto: minúsculas y sin tildes
+func Fold(text string) string {
+    var b strings.Builder
+    for _, r := range textnorm.Fold(text) {
+        if folded, ok := accentFolds[r]; ok {
+            r = folded
+        }
+        b.WriteRune(r)
+    }
+    return b.String()
+}
+
+// Reduzco el plural en español a su forma singular aproximada
+func Stem(word string) string {
+    n := len(word)
+    switch {
+    case n > 4 && strings.HasSuffix(word, "ces"):
+        // luces -> luz, voces -> voz
+        return word[:n-3] + "z"
+    case n > 4 && strings.HasSuffix(word, "es") && strings.ContainsRune("dlnrjy", rune(word[n-3])):
+        // ciudades -> ciudad, canciones -> cancion
+        return word[:n-2]
+    case n > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+        // misterios -> misterio
+        return word[:n-1]
+    }
+    return word
+}
+
+// Divido un texto en términos normalizados listos para indexar o buscar
+func Tokenize(text string) []string {
+    words := strings.FieldsFunc(Fold(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+
+    var terms []string
+    for _, w := range words {
+        if stopwords[w] {
+            continue
+        }
+        terms = append(terms, Stem(w))
+    }
+    return terms
+}
@@ -0,0 +1,114 @@
+package sessions
+
+import (
+    "crypto/rand"
+    "sort"
+    "time"
+    "SDGEStreaming/internal/errors"
+)
+
+// Sesión iniciada por un usuario
+type Session struct {
+    ID        string
+    UserID    int
+    Source    string // desde dónde se inició, por ejemplo "consola local"
+    CreatedAt time.Time
+    LastSeen  time.Time
+}
+
+// Tiempo sin actividad tras el cual una sesión vence
+var IdleTimeout = 5 * time.Minute
+
+// Variables globales para almacenamiento en memoria
+var (
+    sessions = make(map[string]*Session)
+)
+
+// Inicio una sesión nueva para un usuario con un identificador aleatorio
+func Create(userID int, source string) Session {
+    now := time.Now()
+    s := &Session{
+        ID:        rand.Text(),
+        UserID:    userID,
+        Source:    source,
+        CreatedAt: now,
+        LastSeen:  now,
+    }
+    sessions[s.ID] = s
+    return *s
+}
+
+// Verifico que una sesión siga vigente y registro la actividad
+func Validate(id string) (Session, error) {
+    s, exists := sessions[id]
+    if !exists {
+        return Session{}, errors.NewAppError("SESSION_002", "Sesión cerrada", "Inicie sesión nuevamente")
+    }
+    now := time.Now()
+    if now.Sub(s.LastSeen) > IdleTimeout {
+        delete(sessions, id)
+        return Session{}, errors.ErrSessionExpired
+    }
+    s.LastSeen = now
+    return *s, nil
+}
+
+// Cierro una sesión
+func Revoke(id string) {
+    delete(sessions, id)
+}
+
+// Cierro todas las sesiones de un usuario y devuelvo cuántas había
+func RevokeAll(userID int) int {
+    count := 0
+    for id, s := range sessions {
+        if s.UserID == userID {
+            delete(sessions, id)
+            count++
+        }
+    }
+    return count
+}
+
+// Cierro una sesión de un usuario; no puede cerrar sesiones de otros
+func RevokeOwn(userID int, id string) error {
+    s, exists := sessions[id]
+    if !exists || s.UserID != userID {
+        return errors.NewAppError("SESSION_003", "Sesión no encontrada", "")
+    }
+    delete(sessions, id)
+    return nil
+}
+
+// Cierro todas las sesiones de un usuario menos una y devuelvo cuántas cerré
+func RevokeOthers(userID int, keepID string) int {
+    count := 0
+    for id, s := range sessions {
+        if s.UserID == userID && id != keepID {
+            delete(sessions, id)
+            count++
+        }
+    }
+    return count
+}
+
+// Obtengo las sesiones vigentes de un usuario, la de actividad más reciente primero.
+// Aprovecho para descartar las vencidas
+func ForUser(userID int) []Session {
+    now := time.Now()
+    var list []Session
+    for id, s := range sessions {
+        if s.UserID != userID {
+            continue
+        }
+        if now.Sub(s.LastSeen) > IdleTimeout {
+            delete(sessions, id)
+            continue
+        }
+        list = append(list, *s)
+    }
+    sort.Slice(list, func(i, j int) bool {
+        return list[i].LastSeen.After(list[j].LastSeen)
+    })
+    return list
+}
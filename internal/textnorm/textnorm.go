@@ -0,0 +1,122 @@
+package textnorm
+
+import (
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// Marcas combinantes que pueden venir separadas de su letra base (forma NFD)
+const (
+    combiningGrave      = '\u0300'
+    combiningAcute      = '\u0301'
+    combiningCircumflex = '\u0302'
+    combiningTilde      = '\u0303'
+    combiningDiaeresis  = '\u0308'
+    combiningCedilla    = '\u0327'
+)
+
+// Composición de letra base + marca en el carácter precompuesto (forma NFC)
+// para las letras acentuadas del español y las más comunes en nombres propios
+var compositions = map[rune]map[rune]rune{
+    combiningAcute: {
+        'a': 'á', 'e': 'é', 'i': 'í', 'o': 'ó', 'u': 'ú', 'y': 'ý',
+        'A': 'Á', 'E': 'É', 'I': 'Í', 'O': 'Ó', 'U': 'Ú', 'Y': 'Ý',
+    },
+    combiningGrave: {
+        'a': 'à', 'e': 'è', 'i': 'ì', 'o': 'ò', 'u': 'ù',
+        'A': 'À', 'E': 'È', 'I': 'Ì', 'O': 'Ò', 'U': 'Ù',
+    },
+    combiningCircumflex: {
+        'a': 'â', 'e': 'ê', 'i': 'î', 'o': 'ô', 'u': 'û',
+        'A': 'Â', 'E': 'Ê', 'I': 'Î', 'O': 'Ô', 'U': 'Û',
+    },
+    combiningTilde: {
+        'n': 'ñ', 'a': 'ã', 'o': 'õ',
+        'N': 'Ñ', 'A': 'Ã', 'O': 'Õ',
+    },
+    combiningDiaeresis: {
+        'a': 'ä', 'e': 'ë', 'i': 'ï', 'o': 'ö', 'u': 'ü',
+        'A': 'Ä', 'E': 'Ë', 'I': 'Ï', 'O': 'Ö', 'U': 'Ü',
+    },
+    combiningCedilla: {
+        'c': 'ç', 'C': 'Ç',
+    },
+}
+
+// Compongo las letras escritas como base + marca combinante en su forma
+// precompuesta, para que "José" tipeado de las dos formas sea el mismo texto
+func Compose(s string) string {
+    if !hasCombining(s) {
+        return s
+    }
+    out := make([]rune, 0, len(s))
+    for _, r := range s {
+        if n := len(out); n > 0 {
+            if composed, ok := compositions[r][out[n-1]]; ok {
+                out[n-1] = composed
+                continue
+            }
+        }
+        out = append(out, r)
+    }
+    return string(out)
+}
+
+// Verifico si un texto tiene alguna marca combinante que sepa componer
+func hasCombining(s string) bool {
+    for _, r := range s {
+        if _, ok := compositions[r]; ok {
+            return true
+        }
+    }
+    return false
+}
+
+// Quito los espacios de los extremos y reduzco los internos, de cualquier tipo, a uno solo
+func CollapseSpaces(s string) string {
+    return strings.Join(strings.Fields(s), " ")
+}
+
+// Dejo un texto listo para guardar: compuesto y sin espacios de más
+func Clean(s string) string {
+    return CollapseSpaces(Compose(s))
+}
+
+// Pliego mayúsculas y minúsculas de forma Unicode: "Ñandú", "ÑANDÚ" y "ñandú"
+// quedan iguales, igual que variantes como la sigma final o la s larga
+func Fold(s string) string {
+    return strings.Map(func(r rune) rune {
+        return unicode.ToLower(unicode.ToUpper(r))
+    }, Compose(s))
+}
+
+// Obtengo la clave de identidad de un texto para compararlo o indexarlo:
+// compuesto, sin espacios de más y sin distinguir mayúsculas
+func Key(s string) string {
+    return Fold(CollapseSpaces(s))
+}
+
+// Verifico si dos textos son el mismo según su clave
+func Equal(a, b string) bool {
+    return Key(a) == Key(b)
+}
+
+// Escribo cada palabra con mayúscula inicial y el resto en minúsculas; las
+// palabras se separan por espacios o guiones ("stand-up" -> "Stand-Up")
+func Title(s string) string {
+    runes := []rune(Fold(Clean(s)))
+    start := true
+    for i, r := range runes {
+        if start && unicode.IsLetter(r) {
+            runes[i] = unicode.ToTitle(r)
+        }
+        start = r == ' ' || r == '-'
+    }
+    return string(runes)
+}
+
+// Cuento los caracteres que ve el usuario, no los bytes
+func RuneCount(s string) int {
+    return utf8.RuneCountInString(Compose(s))
+}
@@ -0,0 +1,102 @@
+package totp
+
+import (
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/sha1"
+    "crypto/subtle"
+    "encoding/base32"
+    "encoding/binary"
+    "fmt"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// Parámetros de los códigos, los que usan por defecto las apps autenticadoras
+const (
+    Period = 30 // segundos que dura cada código
+    Digits = 6
+)
+
+// Codificación base32 sin relleno de los secretos
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Genero un secreto aleatorio de 160 bits codificado en base32
+func GenerateSecret() string {
+    key := make([]byte, 20)
+    rand.Read(key)
+    return encoding.EncodeToString(key)
+}
+
+// Decodifico un secreto tolerando espacios y minúsculas
+func decodeSecret(secret string) ([]byte, error) {
+    secret = strings.ToUpper(strings.Join(strings.Fields(secret), ""))
+    return encoding.DecodeString(strings.TrimRight(secret, "="))
+}
+
+// Calculo un código HOTP (RFC 4226) para un contador
+func hotp(key []byte, counter uint64) string {
+    var msg [8]byte
+    binary.BigEndian.PutUint64(msg[:], counter)
+    mac := hmac.New(sha1.New, key)
+    mac.Write(msg[:])
+    sum := mac.Sum(nil)
+
+    // Truncamiento dinámico: 31 bits desde la posición que indica el último nibble
+    offset := sum[len(sum)-1] & 0x0f
+    value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+    mod := uint32(1)
+    for i := 0; i < Digits; i++ {
+        mod *= 10
+    }
+    return fmt.Sprintf("%0*d", Digits, value%mod)
+}
+
+// Obtengo el número de intervalo de un momento
+func Step(t time.Time) int64 {
+    return t.Unix() / Period
+}
+
+// Calculo el código TOTP (RFC 6238) de un secreto en un momento dado
+func Code(secret string, t time.Time) (string, error) {
+    key, err := decodeSecret(secret)
+    if err != nil {
+        return "", err
+    }
+    return hotp(key, uint64(Step(t))), nil
+}
+
+// Verifico un código aceptando skew intervalos antes y después para tolerar
+// relojes desfasados. Devuelvo el intervalo que coincidió para evitar que el
+// mismo código se use dos veces
+func Validate(secret, code string, t time.Time, skew int) (int64, bool) {
+    key, err := decodeSecret(secret)
+    code = strings.Join(strings.Fields(code), "")
+    if err != nil || len(code) != Digits {
+        return 0, false
+    }
+    now := Step(t)
+    for d := -int64(skew); d <= int64(skew); d++ {
+        step := now + d
+        if step < 0 {
+            continue
+        }
+        if subtle.ConstantTimeCompare([]byte(hotp(key, uint64(step))), []byte(code)) == 1 {
+            return step, true
+        }
+    }
+    return 0, false
+}
+
+// Armo el URI otpauth:// que las apps autenticadoras leen como QR o a mano
+func URI(secret, account, issuer string) string {
+    label := url.PathEscape(issuer + ":" + account)
+    params := url.Values{}
+    params.Set("secret", secret)
+    params.Set("issuer", issuer)
+    params.Set("algorithm", "SHA1")
+    params.Set("digits", fmt.Sprint(Digits))
+    params.Set("period", fmt.Sprint(Period))
+    return "otpauth://totp/" + label + "?" + params.Encode()
+}
@@ -0,0 +1,79 @@
+package totp
+
+import (
+    "strings"
+    "testing"
+    "time"
+)
+
+// Secreto de los vectores de prueba del RFC 6238 ("12345678901234567890" en base32)
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Verifico los vectores SHA1 del apéndice B del RFC 6238. El RFC usa 8 dígitos;
+// con 6 el código son sus últimos 6 dígitos
+func TestCodeRFC6238(t *testing.T) {
+    tests := []struct {
+        unix int64
+        rfc  string
+    }{
+        {59, "94287082"},
+        {1111111109, "07081804"},
+        {1111111111, "14050471"},
+        {1234567890, "89005924"},
+        {2000000000, "69279037"},
+        {20000000000, "65353130"},
+    }
+    for _, tt := range tests {
+        want := tt.rfc[len(tt.rfc)-Digits:]
+        got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got != want {
+            t.Errorf("Code(T=%d) = %s, quiero %s", tt.unix, got, want)
+        }
+    }
+}
+
+// Verifico la tolerancia de desfase y los códigos mal formados
+func TestValidate(t *testing.T) {
+    now := time.Unix(1111111111, 0)
+    current, _ := Code(rfcSecret, now)
+    previous, _ := Code(rfcSecret, now.Add(-Period*time.Second))
+    old, _ := Code(rfcSecret, now.Add(-2*Period*time.Second))
+
+    tests := []struct {
+        name   string
+        secret string
+        code   string
+        skew   int
+        step   int64
+        ok     bool
+    }{
+        {"código actual", rfcSecret, current, 0, Step(now), true},
+        {"código con espacios", rfcSecret, current[:3] + " " + current[3:], 0, Step(now), true},
+        {"secreto en minúsculas y con espacios", strings.ToLower(rfcSecret[:8]) + " " + rfcSecret[8:], current, 0, Step(now), true},
+        {"intervalo anterior sin tolerancia", rfcSecret, previous, 0, 0, false},
+        {"intervalo anterior con tolerancia", rfcSecret, previous, 1, Step(now) - 1, true},
+        {"dos intervalos atrás", rfcSecret, old, 1, 0, false},
+        {"largo incorrecto", rfcSecret, current[:5], 1, 0, false},
+        {"secreto inválido", "no-es-base32!", current, 1, 0, false},
+    }
+    for _, tt := range tests {
+        step, ok := Validate(tt.secret, tt.code, now, tt.skew)
+        if ok != tt.ok || step != tt.step {
+            t.Errorf("%s: Validate = (%d, %v), quiero (%d, %v)", tt.name, step, ok, tt.step, tt.ok)
+        }
+    }
+}
+
+// Verifico que los secretos generados sean de 160 bits y distintos
+func TestGenerateSecret(t *testing.T) {
+    a, b := GenerateSecret(), GenerateSecret()
+    if key, err := decodeSecret(a); err != nil || len(key) != 20 {
+        t.Errorf("GenerateSecret() = %q: %d bytes, %v", a, len(key), err)
+    }
+    if a == b {
+        t.Error("GenerateSecret() repitió el secreto")
+    }
+}
@@ -0,0 +1,146 @@
+package twofactor
+
+import (
+    "crypto/rand"
+    "crypto/sha256"
+    "strings"
+    "time"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/rbac"
+    "SDGEStreaming/internal/totp"
+)
+
+// Nombre con el que la cuenta aparece en la app autenticadora
+const Issuer = "SDGEStreaming"
+
+// Intervalos de desfase aceptados a cada lado del actual
+var Skew = 1
+
+// Cantidad de códigos de recuperación que se entregan
+var RecoveryCodeCount = 10
+
+// Verificación en dos pasos de un usuario
+type enrollment struct {
+    secret    string
+    confirmed bool
+    lastStep  int64             // último intervalo usado, para no aceptar el mismo código dos veces
+    recovery  map[[32]byte]bool // hash de los códigos de recuperación sin usar
+}
+
+// Variables globales para almacenamiento en memoria
+var (
+    enrollments = make(map[int]*enrollment)
+)
+
+// Normalizo un código de recuperación: sin espacios ni guiones, en minúsculas
+func normalizeRecovery(code string) string {
+    return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+// Genero códigos de recuperación nuevos y guardo solo sus hashes
+func newRecoveryCodes(e *enrollment) []string {
+    e.recovery = make(map[[32]byte]bool)
+    codes := make([]string, RecoveryCodeCount)
+    for i := range codes {
+        raw := strings.ToLower(rand.Text()[:10])
+        codes[i] = raw[:5] + "-" + raw[5:]
+        e.recovery[sha256.Sum256([]byte(raw))] = true
+    }
+    return codes
+}
+
+// Verifico si un usuario tiene la verificación en dos pasos activada
+func IsEnabled(userID int) bool {
+    e, exists := enrollments[userID]
+    return exists && e.confirmed
+}
+
+// Verifico si un usuario está obligado a usar verificación en dos pasos:
+// toda cuenta con un rol administrativo
+func IsRequired(userID int) bool {
+    return rbac.IsStaff(userID)
+}
+
+// Empiezo la activación: genero un secreto y el URI para cargarlo en la app.
+// Queda pendiente hasta confirmar un primer código
+func BeginEnrollment(userID int) (string, string, error) {
+    user, err := profiles.FindByID(userID)
+    if err != nil {
+        return "", "", err
+    }
+    if IsEnabled(userID) {
+        return "", "", errors.NewAppError("TWOFA_001", "La verificación en dos pasos ya está activada", "")
+    }
+    secret := totp.GenerateSecret()
+    enrollments[userID] = &enrollment{secret: secret}
+    return secret, totp.URI(secret, user.Email, Issuer), nil
+}
+
+// Confirmo la activación con un código de la app y entrego los códigos de recuperación
+func ConfirmEnrollment(userID int, code string) ([]string, error) {
+    e, exists := enrollments[userID]
+    if !exists || e.confirmed {
+        return nil, errors.NewAppError("TWOFA_004", "No hay una activación pendiente", "")
+    }
+    step, ok := totp.Validate(e.secret, code, time.Now(), Skew)
+    if !ok {
+        return nil, errors.NewAppError("TWOFA_002", "Código de verificación inválido", "")
+    }
+    e.confirmed = true
+    e.lastStep = step
+    return newRecoveryCodes(e), nil
+}
+
+// Verifico el segundo paso de un inicio de sesión: un código de la app o un
+// código de recuperación, que se consume
+func Verify(userID int, code string) error {
+    e, exists := enrollments[userID]
+    if !exists || !e.confirmed {
+        return errors.NewAppError("TWOFA_004", "La verificación en dos pasos no está activada", "")
+    }
+    if step, ok := totp.Validate(e.secret, code, time.Now(), Skew); ok && step > e.lastStep {
+        e.lastStep = step
+        return nil
+    }
+    h := sha256.Sum256([]byte(normalizeRecovery(code)))
+    if e.recovery[h] {
+        delete(e.recovery, h)
+        return nil
+    }
+    return errors.NewAppError("TWOFA_002", "Código de verificación inválido", "")
+}
+
+// Obtengo cuántos códigos de recuperación le quedan a un usuario
+func RecoveryCodesLeft(userID int) int {
+    if e, exists := enrollments[userID]; exists {
+        return len(e.recovery)
+    }
+    return 0
+}
+
+// Reemplazo los códigos de recuperación después de verificar un código
+func RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+    if err := Verify(userID, code); err != nil {
+        return nil, err
+    }
+    return newRecoveryCodes(enrollments[userID]), nil
+}
+
+// Olvido la verificación en dos pasos de una cuenta eliminada, con su secreto y
+// sus códigos de recuperación
+func Remove(userID int) {
+    delete(enrollments, userID)
+}
+
+// Desactivo la verificación en dos pasos; las cuentas con roles administrativos no pueden
+func Disable(userID int, code string) error {
+    if IsRequired(userID) {
+        return errors.NewAppError("TWOFA_003", "La verificación en dos pasos es obligatoria para cuentas con roles administrativos", "")
+    }
+    if err := Verify(userID, code); err != nil {
+        return err
+    }
+    delete(enrollments, userID)
+    return nil
+}
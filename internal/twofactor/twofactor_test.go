@@ -0,0 +1,94 @@
+package twofactor
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+    "time"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/totp"
+)
+
+// Obtengo el código de un error de la aplicación, o vacío si no es uno
+func errorCode(err error) string {
+    if appErr, ok := err.(*errors.AppError); ok {
+        return appErr.Code
+    }
+    return ""
+}
+
+// Cuentas registradas por las pruebas, para no repetir emails
+var testUsers = 0
+
+// Registro una cuenta con la verificación en dos pasos activada y devuelvo su
+// ID, su secreto y sus códigos de recuperación
+func enrolled(t *testing.T) (int, string, []string) {
+    t.Helper()
+    testUsers++
+    user, err := profiles.AddUser("Usuario Prueba", 30, fmt.Sprintf("dospasos%d@prueba.com", testUsers), "clave123", "Free", "Adulto", nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    secret, _, err := BeginEnrollment(user.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    code, _ := totp.Code(secret, time.Now())
+    recovery, err := ConfirmEnrollment(user.ID, code)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return user.ID, secret, recovery
+}
+
+// Verifico que un código ya usado, o uno de un intervalo anterior, no se acepte de nuevo
+func TestVerifyRejectsReplay(t *testing.T) {
+    userID, secret, _ := enrolled(t)
+    now := time.Now()
+    current, _ := totp.Code(secret, now)
+    previous, _ := totp.Code(secret, now.Add(-totp.Period*time.Second))
+    next, _ := totp.Code(secret, now.Add(totp.Period*time.Second))
+
+    steps := []struct {
+        name string
+        code string
+        ok   bool
+    }{
+        {"código usado al activar", current, false},
+        {"intervalo anterior al usado", previous, false},
+        {"intervalo siguiente", next, true},
+        {"intervalo siguiente otra vez", next, false},
+        {"código actual, anterior al último usado", current, false},
+    }
+    for _, step := range steps {
+        err := Verify(userID, step.code)
+        if (err == nil) != step.ok {
+            t.Errorf("%s: Verify = %v, quiero aceptado = %v", step.name, err, step.ok)
+        }
+        if err != nil && errorCode(err) != "TWOFA_002" {
+            t.Errorf("%s: error %v, quiero TWOFA_002", step.name, err)
+        }
+    }
+}
+
+// Verifico que cada código de recuperación sirva una sola vez
+func TestRecoveryCodesAreSingleUse(t *testing.T) {
+    userID, _, recovery := enrolled(t)
+    if len(recovery) != RecoveryCodeCount {
+        t.Fatalf("%d códigos de recuperación, quiero %d", len(recovery), RecoveryCodeCount)
+    }
+    if err := Verify(userID, recovery[0]); err != nil {
+        t.Fatalf("primer uso: %v", err)
+    }
+    if err := Verify(userID, recovery[0]); errorCode(err) != "TWOFA_002" {
+        t.Errorf("segundo uso: error %v, quiero TWOFA_002", err)
+    }
+    // Sin guion y en mayúsculas también se acepta
+    if err := Verify(userID, strings.ToUpper(recovery[1][:5]+recovery[1][6:])); err != nil {
+        t.Errorf("código sin guion y en mayúsculas: %v", err)
+    }
+    if left := RecoveryCodesLeft(userID); left != RecoveryCodeCount-2 {
+        t.Errorf("RecoveryCodesLeft = %d, quiero %d", left, RecoveryCodeCount-2)
+    }
+}
@@ -3,14 +3,58 @@ package utils
 import (
     "bufio"
     "fmt"
+    "math"
+    "net/mail"
     "os"
     "strconv"
     "strings"
+    "SDGEStreaming/internal/textnorm"
 )
 
-// Valido que un email tenga formato correcto mínimo
+// Valido que un email sea una dirección RFC 5322 simple, sin nombre ni
+// corchetes, y que su dominio tenga una sintaxis válida
 func IsValidEmail(email string) bool {
-    return strings.Contains(email, "@") && strings.Contains(email, ".") && len(email) > 5
+    addr, err := mail.ParseAddress(email)
+    if err != nil || addr.Name != "" || addr.Address != email || len(email) > 254 {
+        return false
+    }
+    at := strings.LastIndex(email, "@")
+    if at > 64 {
+        return false
+    }
+    return isValidDomain(email[at+1:])
+}
+
+// Valido la sintaxis de un dominio: al menos dos etiquetas de letras, dígitos
+// y guiones, sin guiones en los extremos, y un dominio de primer nivel alfabético
+func isValidDomain(domain string) bool {
+    if len(domain) > 253 {
+        return false
+    }
+    labels := strings.Split(domain, ".")
+    if len(labels) < 2 {
+        return false
+    }
+    for _, label := range labels {
+        if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+            return false
+        }
+        for _, r := range label {
+            if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+                return false
+            }
+        }
+    }
+    tld := labels[len(labels)-1]
+    if len(tld) < 2 {
+        return false
+    }
+    for _, r := range tld {
+        if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+            return false
+        }
+    }
+    return true
 }
 
 // Valido que una contraseña cumpla con requisitos mínimos
@@ -73,9 +117,10 @@ func FormatDuration(minutes int) string {
     return fmt.Sprintf("%d h %d min", hours, remaining)
 }
 
-// Valido que un nombre sea válido (mínimo 2 caracteres, sin números)
+// Valido que un nombre sea válido (mínimo 2 caracteres, sin números). Cuento
+// caracteres y no bytes, para que "Ñu" o "Íñigo" se midan como se ven
 func IsValidName(name string) bool {
-    if len(name) < 2 {
+    if textnorm.RuneCount(textnorm.Clean(name)) < 2 {
         return false
     }
     for _, r := range name {
@@ -86,10 +131,12 @@ func IsValidName(name string) bool {
     return true
 }
 
-// Formateo un rating para mostrar con un decimal, excepto 10 que muestro como entero
+// Formateo un rating para mostrar con un decimal, excepto 10 que muestro como entero.
+// Los promedios se guardan sin redondear: el redondeo ocurre solo aquí
 func FormatRating(rating float64) string {
-    if rating == 10.0 {
+    rounded := math.Round(rating*10) / 10
+    if rounded == 10.0 {
         return "10"
     }
-    return fmt.Sprintf("%.1f", rating)
+    return fmt.Sprintf("%.1f", rounded)
 }
\ No newline at end of file
@@ -0,0 +1,185 @@
+package verification
+
+import (
+    "crypto/rand"
+    "crypto/sha256"
+    "crypto/subtle"
+    "fmt"
+    "math"
+    "math/big"
+    "strings"
+    "time"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/mailer"
+    "SDGEStreaming/internal/profiles"
+    "SDGEStreaming/internal/utils"
+)
+
+// Motivos por los que se pide un código
+const (
+    PurposeRegistration = "registro"
+    PurposeEmailChange  = "cambio de email"
+)
+
+// Vigencia de un código e intentos permitidos antes de invalidarlo
+var (
+    CodeTTL     = 15 * time.Minute
+    MaxAttempts = 5
+)
+
+// Espera entre dos códigos para el mismo usuario y máximo de códigos por hora,
+// para que pedir códigos nuevos no sirva para llenar una casilla ajena
+var (
+    ResendCooldown  = time.Minute
+    MaxCodesPerHour = 5
+)
+
+// Código pendiente de un usuario; solo guardo su hash
+type challenge struct {
+    Email     string
+    Purpose   string
+    CodeHash  [32]byte
+    ExpiresAt time.Time
+    Attempts  int
+}
+
+// Variables globales para almacenamiento en memoria
+var (
+    pending = make(map[int]*challenge)  // usuario -> código vigente; uno nuevo reemplaza al anterior
+    issued  = make(map[int][]time.Time) // usuario -> envíos de la última hora
+)
+
+// Genero un código de 6 dígitos con una fuente criptográfica
+func newCode() (string, error) {
+    n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+    if err != nil {
+        return "", err
+    }
+    return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// Verifico que el usuario pueda recibir otro código y descarto los envíos de
+// hace más de una hora
+func checkRate(userID int, now time.Time) error {
+    var recent []time.Time
+    for _, at := range issued[userID] {
+        if now.Sub(at) < time.Hour {
+            recent = append(recent, at)
+        }
+    }
+    issued[userID] = recent
+    if len(recent) == 0 {
+        delete(issued, userID)
+        return nil
+    }
+    if wait := recent[len(recent)-1].Add(ResendCooldown).Sub(now); wait > 0 {
+        return errors.NewAppError("VERIFY_007", "Ya se envió un código hace poco", fmt.Sprintf("Espere %d segundos", int(math.Ceil(wait.Seconds()))))
+    }
+    if len(recent) >= MaxCodesPerHour {
+        return errors.NewAppError("VERIFY_008", "Demasiados códigos pedidos", fmt.Sprintf("Puede pedir otro a las %s", recent[0].Add(time.Hour).Format("15:04")))
+    }
+    return nil
+}
+
+// Genero un código, lo envío al email indicado y lo dejo pendiente
+func issue(userID int, email, purpose string) error {
+    now := time.Now()
+    if err := checkRate(userID, now); err != nil {
+        return err
+    }
+    code, err := newCode()
+    if err != nil {
+        return err
+    }
+    body := fmt.Sprintf("Su código de verificación de SDGEStreaming es %s.\nVence en %.0f minutos. Si no lo pidió, ignore este mensaje.", code, CodeTTL.Minutes())
+    if err := mailer.Send(email, "Código de verificación", body); err != nil {
+        return errors.NewAppError("VERIFY_006", "No se pudo enviar el correo", err.Error())
+    }
+    issued[userID] = append(issued[userID], now)
+    pending[userID] = &challenge{
+        Email:     email,
+        Purpose:   purpose,
+        CodeHash:  sha256.Sum256([]byte(code)),
+        ExpiresAt: now.Add(CodeTTL),
+    }
+    return nil
+}
+
+// Envío el código para verificar el email con el que se registró un usuario
+func SendRegistrationCode(userID int) error {
+    user, err := profiles.FindByID(userID)
+    if err != nil {
+        return err
+    }
+    if user.EmailVerified {
+        return errors.NewAppError("VERIFY_005", "El email ya está verificado", user.Email)
+    }
+    return issue(userID, user.Email, PurposeRegistration)
+}
+
+// Pido cambiar el email de un usuario; el cambio se aplica al confirmar el
+// código enviado a la dirección nueva
+func RequestEmailChange(userID int, newEmail string) error {
+    if _, err := profiles.FindByID(userID); err != nil {
+        return err
+    }
+    newEmail = strings.TrimSpace(newEmail)
+    if !utils.IsValidEmail(newEmail) {
+        return errors.ErrInvalidEmail
+    }
+    if _, err := profiles.FindByEmail(newEmail); err == nil {
+        return errors.ErrEmailExists
+    }
+    return issue(userID, newEmail, PurposeEmailChange)
+}
+
+// Obtengo el email y el motivo del código pendiente de un usuario
+func Pending(userID int) (string, string, bool) {
+    c, exists := pending[userID]
+    if !exists || time.Now().After(c.ExpiresAt) {
+        return "", "", false
+    }
+    return c.Email, c.Purpose, true
+}
+
+// Confirmo el código de un usuario y aplico lo que se pidió: verificar el
+// email de registro o cambiar al email nuevo. Devuelvo el motivo confirmado
+func Confirm(userID int, code string) (string, error) {
+    c, exists := pending[userID]
+    if !exists {
+        return "", errors.NewAppError("VERIFY_001", "No hay un código pendiente", "Solicite uno nuevo")
+    }
+    if time.Now().After(c.ExpiresAt) {
+        delete(pending, userID)
+        return "", errors.NewAppError("VERIFY_002", "El código venció", "Solicite uno nuevo")
+    }
+
+    hash := sha256.Sum256([]byte(strings.TrimSpace(code)))
+    if subtle.ConstantTimeCompare(hash[:], c.CodeHash[:]) != 1 {
+        c.Attempts++
+        if c.Attempts >= MaxAttempts {
+            delete(pending, userID)
+            return "", errors.NewAppError("VERIFY_004", "Demasiados intentos", "Solicite un código nuevo")
+        }
+        return "", errors.NewAppError("VERIFY_003", "Código incorrecto", fmt.Sprintf("Quedan %d intentos", MaxAttempts-c.Attempts))
+    }
+
+    var err error
+    switch c.Purpose {
+    case PurposeEmailChange:
+        err = profiles.ChangeEmail(userID, c.Email)
+    default:
+        err = profiles.MarkEmailVerified(userID)
+    }
+    if err != nil {
+        return "", err
+    }
+    delete(pending, userID)
+    return c.Purpose, nil
+}
+
+// Descarto el código pendiente y los envíos de un usuario, por ejemplo al eliminar su cuenta
+func Discard(userID int) {
+    delete(pending, userID)
+    delete(issued, userID)
+}
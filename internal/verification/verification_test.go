@@ -0,0 +1,93 @@
+package verification
+
+import (
+    "fmt"
+    "testing"
+    "time"
+    "SDGEStreaming/internal/errors"
+    "SDGEStreaming/internal/mailer"
+    "SDGEStreaming/internal/profiles"
+)
+
+// Obtengo el código de un error de la aplicación, o vacío si no es uno
+func errorCode(err error) string {
+    if appErr, ok := err.(*errors.AppError); ok {
+        return appErr.Code
+    }
+    return ""
+}
+
+// Cuentas registradas por las pruebas, para no repetir emails
+var testUsers = 0
+
+// Registro una cuenta sin verificar y devuelvo su ID
+func newUser(t *testing.T) int {
+    t.Helper()
+    testUsers++
+    user, err := profiles.AddUser("Usuario Prueba", 30, fmt.Sprintf("verificar%d@prueba.com", testUsers), "clave123", "Free", "Adulto", nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return user.ID
+}
+
+// Verifico la espera entre códigos, el máximo por hora y que los envíos viejos
+// dejen de contar
+func TestIssueRateLimit(t *testing.T) {
+    mailer.SetMailer(mailer.FileOutbox{Dir: t.TempDir()})
+    cooldown, perHour := ResendCooldown, MaxCodesPerHour
+    t.Cleanup(func() { ResendCooldown, MaxCodesPerHour = cooldown, perHour })
+    ResendCooldown, MaxCodesPerHour = time.Hour, 3
+    userID := newUser(t)
+
+    if err := SendRegistrationCode(userID); err != nil {
+        t.Fatal(err)
+    }
+    if err := SendRegistrationCode(userID); errorCode(err) != "VERIFY_007" {
+        t.Errorf("reenvío inmediato: error %v, quiero VERIFY_007", err)
+    }
+    if err := RequestEmailChange(userID, "otro-verificar@prueba.com"); errorCode(err) != "VERIFY_007" {
+        t.Errorf("cambio de email inmediato: error %v, quiero VERIFY_007", err)
+    }
+
+    ResendCooldown = 0
+    steps := []string{"", "", "VERIFY_008"}
+    for i, code := range steps {
+        if err := SendRegistrationCode(userID); errorCode(err) != code {
+            t.Errorf("envío %d: error %v, quiero %q", i+2, err, code)
+        }
+    }
+
+    // Pasada la hora los envíos anteriores ya no cuentan
+    for i := range issued[userID] {
+        issued[userID][i] = issued[userID][i].Add(-time.Hour)
+    }
+    if err := SendRegistrationCode(userID); err != nil {
+        t.Errorf("después de una hora: %v", err)
+    }
+    if len(issued[userID]) != 1 {
+        t.Errorf("quedaron %d envíos registrados, quiero 1", len(issued[userID]))
+    }
+
+    // Otro usuario no hereda la espera
+    if err := SendRegistrationCode(newUser(t)); err != nil {
+        t.Errorf("otro usuario: %v", err)
+    }
+}
+
+// Verifico que descartar un usuario borre su código pendiente y sus envíos
+func TestDiscard(t *testing.T) {
+    mailer.SetMailer(mailer.FileOutbox{Dir: t.TempDir()})
+    userID := newUser(t)
+    if err := SendRegistrationCode(userID); err != nil {
+        t.Fatal(err)
+    }
+
+    Discard(userID)
+    if _, _, ok := Pending(userID); ok {
+        t.Error("quedó el código pendiente")
+    }
+    if _, exists := issued[userID]; exists {
+        t.Errorf("quedaron los envíos: %v", issued[userID])
+    }
+}